@@ -0,0 +1,393 @@
+package azpolicy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+//go:embed aliases.json
+var aliasesJSON []byte
+
+// policyLink is the link of a policy definition in the Azure portal, followed by the escaped definition ID.
+const policyLink = "https://portal.azure.com/#view/Microsoft_Azure_Policy/PolicyDetailBlade/definitionId/"
+
+// aliases returns the body path of each policy alias by lower case alias.
+var aliases = sync.OnceValue(func() map[string]string {
+	var m map[string]string
+	if err := json.Unmarshal(aliasesJSON, &m); err != nil {
+		panic(fmt.Sprintf("could not decode policy aliases: %s", err))
+	}
+	res := make(map[string]string, len(m))
+	for alias, p := range m {
+		res[strings.ToLower(alias)] = p
+	}
+	return res
+})
+
+// Definition is an Azure Policy definition, as exported from Azure or from the azure-policy repository.
+type Definition struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		DisplayName string               `json:"displayName"`
+		Parameters  map[string]Parameter `json:"parameters"`
+		PolicyRule  struct {
+			If   any `json:"if"`
+			Then struct {
+				Effect string `json:"effect"`
+			} `json:"then"`
+		} `json:"policyRule"`
+	} `json:"properties"`
+}
+
+// Parameter is a parameter of a policy definition. Only its default value is used.
+type Parameter struct {
+	DefaultValue any `json:"defaultValue"`
+}
+
+// RuleDefinition is a rule translated from a policy definition.
+type RuleDefinition struct {
+	Name         string          // The name of the rule, `azpolicy_` followed by the file name in snake case, e.g. `azpolicy_storage_min_tls`.
+	DisplayName  string          // The display name of the policy.
+	Link         string          // The policy in the Azure portal, if the definition has an ID.
+	ResourceType string          // The resource type that the policy applies to, e.g. `Microsoft.Storage/storageAccounts`.
+	Expression   string          // The CEL expression that compliant resources satisfy, the negation of the `if` condition.
+	Severity     tflint.Severity // ERROR for the `Deny` effect and WARNING for `Audit`.
+}
+
+// Rule returns the rule of the definition.
+func (d *RuleDefinition) Rule() (*rules.CelRule, error) {
+	r, err := rules.NewCelRule(d.Name, d.Link, d.ResourceType, "", "", d.Expression)
+	if err != nil {
+		return nil, err
+	}
+	return r.WithSeverity(d.Severity).WithMessage(fmt.Sprintf("Resource does not comply with the policy `%s`", d.DisplayName)), nil
+}
+
+// Skipped is a policy definition that could not be translated.
+type Skipped struct {
+	File   string // The file of the definition.
+	Policy string // The display name of the policy, or its file name if it has none.
+	Reason string
+}
+
+// Result is the result of the import of a directory of policy definitions.
+type Result struct {
+	Rules          []*RuleDefinition // Sorted by name.
+	Skipped        []Skipped
+	UnknownAliases []string // The aliases used by the policies that are not in the alias map, sorted.
+}
+
+// Import reads the policy definitions in the JSON files of the directory from modulecontent.AppFs, and translates them to rules.
+// Definitions that cannot be parsed or translated are skipped and reported in the result, a file that cannot be read is an error.
+func Import(dir string) (*Result, error) {
+	infos, err := modulecontent.AppFs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read policy definition directory: %s", err)
+	}
+	res := &Result{}
+	names := map[string]bool{}
+	unknown := map[string]bool{}
+	for _, info := range infos {
+		if info.IsDir() || path.Ext(info.Name()) != ".json" {
+			continue
+		}
+		filename := path.Join(dir, info.Name())
+		skip := func(policy, reason string) {
+			res.Skipped = append(res.Skipped, Skipped{File: filename, Policy: policy, Reason: reason})
+		}
+		src, err := modulecontent.AppFs.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not read policy definition: %s", err)
+		}
+		def := &Definition{}
+		if err := json.Unmarshal(src, def); err != nil {
+			skip(info.Name(), fmt.Sprintf("could not parse policy definition: %s", err))
+			continue
+		}
+		policy := def.Properties.DisplayName
+		if policy == "" {
+			policy = info.Name()
+		}
+		t := &translator{params: def.Properties.Parameters, unknownAliases: unknown}
+		rd, err := t.translate(ruleName(info.Name()), def)
+		if err != nil {
+			skip(policy, err.Error())
+			continue
+		}
+		if names[rd.Name] {
+			skip(policy, fmt.Sprintf("another policy has the rule name `%s`", rd.Name))
+			continue
+		}
+		names[rd.Name] = true
+		res.Rules = append(res.Rules, rd)
+	}
+	sort.Slice(res.Rules, func(i, j int) bool {
+		return res.Rules[i].Name < res.Rules[j].Name
+	})
+	for alias := range unknown {
+		res.UnknownAliases = append(res.UnknownAliases, alias)
+	}
+	sort.Strings(res.UnknownAliases)
+	return res, nil
+}
+
+var ruleNameReplacer = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ruleName returns the name of the rule of a definition file, e.g. `azpolicy_storage_min_tls` for `storage-min-tls.json`.
+func ruleName(filename string) string {
+	base := strings.TrimSuffix(filename, path.Ext(filename))
+	return "azpolicy_" + strings.Trim(ruleNameReplacer.ReplaceAllString(strings.ToLower(base), "_"), "_")
+}
+
+// translator translates the `if` condition of a policy definition to a CEL expression.
+type translator struct {
+	params         map[string]Parameter
+	unknownAliases map[string]bool // The aliases that are not in the alias map, shared by the definitions of an import.
+}
+
+func (t *translator) translate(name string, def *Definition) (*RuleDefinition, error) {
+	rd := &RuleDefinition{Name: name, DisplayName: def.Properties.DisplayName}
+	if rd.DisplayName == "" {
+		rd.DisplayName = def.Name
+	}
+	if def.ID != "" {
+		rd.Link = policyLink + url.PathEscape(def.ID)
+	}
+
+	effect, err := t.resolve(def.Properties.PolicyRule.Then.Effect)
+	if err != nil {
+		return nil, err
+	}
+	switch e, _ := effect.(string); strings.ToLower(e) {
+	case "deny":
+		rd.Severity = tflint.ERROR
+	case "audit":
+		rd.Severity = tflint.WARNING
+	case "disabled":
+		return nil, fmt.Errorf("the effect is `Disabled`")
+	default:
+		return nil, fmt.Errorf("unsupported effect `%v`", effect)
+	}
+
+	// The resource type is selected by a `type` condition of the `if` condition, or of its `allOf` conditions.
+	cond, ok := def.Properties.PolicyRule.If.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("the policy rule has no `if` condition")
+	}
+	conds := []any{cond}
+	if allOf, ok := cond["allOf"].([]any); ok && len(cond) == 1 {
+		conds = allOf
+	}
+	var rest []string
+	for _, c := range conds {
+		if rd.ResourceType == "" {
+			if rt, ok := t.resourceType(c); ok {
+				rd.ResourceType = rt
+				continue
+			}
+		}
+		expr, err := t.condition(c)
+		if err != nil {
+			return nil, err
+		}
+		rest = append(rest, expr)
+	}
+	if rd.ResourceType == "" {
+		return nil, fmt.Errorf("the policy does not select a resource type with a `type` condition")
+	}
+	rd.Expression = "false"
+	if len(rest) > 0 {
+		rd.Expression = "!(" + strings.Join(rest, " && ") + ")"
+	}
+	if _, err := rd.Rule(); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// resourceType returns the resource type of a condition that the type equals it.
+func (t *translator) resourceType(c any) (string, bool) {
+	cond, ok := c.(map[string]any)
+	if !ok || len(cond) != 2 || !strings.EqualFold(fmt.Sprint(cond["field"]), "type") {
+		return "", false
+	}
+	val, err := t.resolve(cond["equals"])
+	s, ok := val.(string)
+	return s, err == nil && ok
+}
+
+// condition returns the CEL expression of a condition.
+func (t *translator) condition(c any) (string, error) {
+	cond, ok := c.(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("a condition is not an object")
+	}
+	if conds, ok := cond["allOf"]; ok {
+		return t.logical(conds, " && ")
+	}
+	if conds, ok := cond["anyOf"]; ok {
+		return t.logical(conds, " || ")
+	}
+	if not, ok := cond["not"]; ok {
+		expr, err := t.condition(not)
+		if err != nil {
+			return "", err
+		}
+		return "!(" + expr + ")", nil
+	}
+	field, ok := cond["field"].(string)
+	if !ok {
+		for key := range cond {
+			if key != "field" {
+				return "", fmt.Errorf("unsupported condition `%s`", key)
+			}
+		}
+		return "", fmt.Errorf("a condition has no `field`")
+	}
+	val, err := t.field(field)
+	if err != nil {
+		return "", err
+	}
+	if len(cond) != 2 {
+		return "", fmt.Errorf("the condition of field `%s` must have one operator", field)
+	}
+	for op, v := range cond {
+		if op == "field" {
+			continue
+		}
+		v, err := t.resolve(v)
+		if err != nil {
+			return "", err
+		}
+		switch op {
+		case "equals", "notEquals":
+			lit, err := literal(v)
+			if err != nil {
+				return "", err
+			}
+			expr := fmt.Sprintf("cel.bind(v, %s, v != null && string(v).lowerAscii() == %s)", val, lit)
+			if op == "notEquals" {
+				expr = "!" + expr
+			}
+			return expr, nil
+		case "in", "notIn":
+			values, ok := v.([]any)
+			if !ok {
+				return "", fmt.Errorf("the value of `%s` is not an array", op)
+			}
+			lits := make([]string, len(values))
+			for i, v := range values {
+				if lits[i], err = literal(v); err != nil {
+					return "", err
+				}
+			}
+			expr := fmt.Sprintf("cel.bind(v, %s, v != null && string(v).lowerAscii() in [%s])", val, strings.Join(lits, ", "))
+			if op == "notIn" {
+				expr = "!" + expr
+			}
+			return expr, nil
+		case "exists":
+			exists, err := strconv.ParseBool(strings.ToLower(fmt.Sprint(v)))
+			if err != nil {
+				return "", fmt.Errorf("the value of `exists` is not a boolean")
+			}
+			if exists {
+				return val + " != null", nil
+			}
+			return val + " == null", nil
+		default:
+			return "", fmt.Errorf("unsupported condition `%s`", op)
+		}
+	}
+	return "", nil
+}
+
+// logical returns the CEL expressions of the conditions joined by the operator.
+func (t *translator) logical(c any, op string) (string, error) {
+	conds, ok := c.([]any)
+	if !ok || len(conds) == 0 {
+		return "", fmt.Errorf("`allOf` and `anyOf` must be arrays of conditions")
+	}
+	exprs := make([]string, len(conds))
+	for i, cond := range conds {
+		var err error
+		if exprs[i], err = t.condition(cond); err != nil {
+			return "", err
+		}
+	}
+	return "(" + strings.Join(exprs, op) + ")", nil
+}
+
+var tagFieldPattern = regexp.MustCompile(`^tags(?:\['([^']+)'\]|\[([^\]']+)\]|\.(.+))$`)
+
+// field returns the CEL expression of the value of a field, which is null if it does not exist.
+func (t *translator) field(field string) (string, error) {
+	switch strings.ToLower(field) {
+	case "type":
+		return "resource.type.split('@')[0]", nil
+	case "name", "location":
+		return "resource." + strings.ToLower(field), nil
+	case "kind":
+		return "body.?kind.orValue(null)", nil
+	case "tags":
+		return "resource.tags", nil
+	}
+	if m := tagFieldPattern.FindStringSubmatch(field); m != nil {
+		return fmt.Sprintf("resource.tags[?%s].orValue(null)", strconv.Quote(m[1]+m[2]+m[3])), nil
+	}
+	p, ok := aliases()[strings.ToLower(field)]
+	if !ok {
+		t.unknownAliases[field] = true
+		return "", fmt.Errorf("unknown alias `%s`", field)
+	}
+	return "body.?" + strings.ReplaceAll(p, ".", ".?") + ".orValue(null)", nil
+}
+
+var parametersPattern = regexp.MustCompile(`^\[parameters\('([^']+)'\)\]$`)
+
+// resolve returns the value of a `[parameters('name')]` expression, which is the default value of the parameter, or the value itself.
+// A string that starts with `[[` is a literal that starts with `[`.
+func (t *translator) resolve(v any) (any, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "[") {
+		return v, nil
+	}
+	if strings.HasPrefix(s, "[[") {
+		return s[1:], nil
+	}
+	m := parametersPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported expression `%s`", s)
+	}
+	param, ok := t.params[m[1]]
+	if !ok || param.DefaultValue == nil {
+		return nil, fmt.Errorf("parameter `%s` has no default value", m[1])
+	}
+	return param.DefaultValue, nil
+}
+
+// literal returns the CEL string literal of a value in lower case, to compare it with the string of a field value.
+func literal(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(strings.ToLower(v)), nil
+	case bool:
+		return strconv.Quote(strconv.FormatBool(v)), nil
+	case float64:
+		return strconv.Quote(strconv.FormatFloat(v, 'f', -1, 64)), nil
+	}
+	return "", fmt.Errorf("unsupported value `%v`", v)
+}
@@ -0,0 +1,160 @@
+package azpolicy
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+func TestImport(t *testing.T) {
+	res, err := Import("testdata")
+	require.NoError(t, err)
+
+	type rule struct {
+		name         string
+		resourceType string
+		severity     tflint.Severity
+	}
+	var got []rule
+	for _, rd := range res.Rules {
+		got = append(got, rule{rd.Name, rd.ResourceType, rd.Severity})
+	}
+	assert.Equal(t, []rule{
+		{"azpolicy_key_vault_purge_protection", "Microsoft.KeyVault/vaults", tflint.WARNING},
+		{"azpolicy_storage_https", "Microsoft.Storage/storageAccounts", tflint.ERROR},
+		{"azpolicy_storage_min_tls", "Microsoft.Storage/storageAccounts", tflint.WARNING},
+	}, got)
+	assert.Equal(t,
+		`!((!cel.bind(v, body.?properties.?minimumTlsVersion.orValue(null), v != null && string(v).lowerAscii() == "tls1_2") || body.?properties.?minimumTlsVersion.orValue(null) == null))`,
+		res.Rules[2].Expression,
+	)
+	assert.Equal(t,
+		"https://portal.azure.com/#view/Microsoft_Azure_Policy/PolicyDetailBlade/definitionId/%2Fproviders%2FMicrosoft.Authorization%2FpolicyDefinitions%2Ffe83a0eb-a853-422d-aac2-1bffd182c5d0",
+		res.Rules[2].Link,
+	)
+
+	assert.Equal(t, []Skipped{
+		{File: "testdata/invalid.json", Policy: "invalid.json", Reason: "could not parse policy definition: unexpected end of JSON input"},
+		{File: "testdata/storage_diagnostics.json", Policy: "Diagnostic logs in storage accounts should be enabled", Reason: "unsupported effect `AuditIfNotExists`"},
+		{File: "testdata/storage_key_expiration.json", Policy: "Storage accounts should have shared access signature (SAS) policies configured", Reason: "unknown alias `Microsoft.Storage/storageAccounts/keyPolicy.keyExpirationPeriodInDays`"},
+		{File: "testdata/storage_name.json", Policy: "Storage account names should start with st", Reason: "unsupported condition `notLike`"},
+	}, res.Skipped)
+	assert.Equal(t, []string{"Microsoft.Storage/storageAccounts/keyPolicy.keyExpirationPeriodInDays"}, res.UnknownAliases)
+}
+
+func TestImportedRules(t *testing.T) {
+	res, err := Import("testdata")
+	require.NoError(t, err)
+
+	content := `
+resource "azapi_resource" "compliant" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  body = {
+    properties = {
+      minimumTlsVersion        = "tls1_2"
+      supportsHttpsTrafficOnly = true
+    }
+  }
+}
+
+resource "azapi_resource" "old_tls" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  body = {
+    properties = {
+      minimumTlsVersion        = "TLS1_0"
+      supportsHttpsTrafficOnly = false
+    }
+  }
+}
+
+resource "azapi_resource" "defaults" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  body = {}
+}
+
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-07-01"
+  body = {
+    properties = {
+      enablePurgeProtection = false
+    }
+  }
+}
+
+resource "azapi_resource" "kv_dev" {
+  type = "Microsoft.KeyVault/vaults@2023-07-01"
+  tags = {
+    environment = "Dev"
+  }
+  body = {
+    properties = {}
+  }
+}
+`
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{"main.tf": content}))
+	defer stub.Reset()
+
+	var got []string
+	for _, rd := range res.Rules {
+		rule, err := rd.Rule()
+		require.NoError(t, err)
+		runner := helper.TestRunner(t, map[string]string{"main.tf": content})
+		require.NoError(t, rule.Check(runner))
+		for _, issue := range runner.Issues {
+			got = append(got, rule.Name()+": "+issue.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		"azpolicy_key_vault_purge_protection: Resource does not comply with the policy `Key vaults should have purge protection enabled`",
+		"azpolicy_storage_https: Resource does not comply with the policy `Secure transfer to storage accounts should be enabled`",
+		"azpolicy_storage_https: Resource does not comply with the policy `Secure transfer to storage accounts should be enabled`",
+		"azpolicy_storage_min_tls: Resource does not comply with the policy `Storage accounts should have the specified minimum TLS version`",
+		"azpolicy_storage_min_tls: Resource does not comply with the policy `Storage accounts should have the specified minimum TLS version`",
+	}, got)
+}
+
+func TestField(t *testing.T) {
+	tr := &translator{unknownAliases: map[string]bool{}}
+	for field, want := range map[string]string{
+		"type":                "resource.type.split('@')[0]",
+		"Location":            "resource.location",
+		"kind":                "body.?kind.orValue(null)",
+		"tags['cost-center']": `resource.tags[?"cost-center"].orValue(null)`,
+		"tags[env]":           `resource.tags[?"env"].orValue(null)`,
+		"tags.env":            `resource.tags[?"env"].orValue(null)`,
+		"Microsoft.Storage/storageAccounts/networkAcls.defaultAction": "body.?properties.?networkAcls.?defaultAction.orValue(null)",
+		"microsoft.storage/storageaccounts/minimumtlsversion":         "body.?properties.?minimumTlsVersion.orValue(null)",
+	} {
+		got, err := tr.field(field)
+		require.NoError(t, err, field)
+		assert.Equal(t, want, got, field)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tr := &translator{params: map[string]Parameter{
+		"effect":   {DefaultValue: "Deny"},
+		"versions": {DefaultValue: []any{"TLS1_2", "TLS1_3"}},
+		"required": {},
+	}}
+	for v, want := range map[string]any{
+		"[parameters('effect')]":   "Deny",
+		"[parameters('versions')]": []any{"TLS1_2", "TLS1_3"},
+		"[[literal]":               "[literal]",
+		"Audit":                    "Audit",
+	} {
+		got, err := tr.resolve(v)
+		require.NoError(t, err, v)
+		assert.Equal(t, want, got, v)
+	}
+	_, err := tr.resolve("[parameters('required')]")
+	assert.EqualError(t, err, "parameter `required` has no default value")
+	_, err = tr.resolve("[concat('a', 'b')]")
+	assert.EqualError(t, err, "unsupported expression `[concat('a', 'b')]`")
+}
@@ -0,0 +1,5 @@
+// Package azpolicy imports Azure Policy definitions as rules that evaluate a CEL expression against `azapi_resource` resources.
+// The `if` condition of a policy is translated to an expression over the body, with an embedded map of policy aliases to body paths,
+// e.g. `Microsoft.Storage/storageAccounts/minimumTlsVersion` to `properties.minimumTlsVersion`.
+// Policies with conditions, aliases or effects that cannot be translated are reported instead.
+package azpolicy
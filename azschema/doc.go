@@ -0,0 +1,4 @@
+// Package azschema provides the Azure resource type schemas used by the azapi provider,
+// so that the body of an `azapi_resource` can be validated without access to Azure.
+// The schemas are a compressed bundle built from the bicep-types-az type definitions by `cmd/azschemagen`.
+package azschema
@@ -0,0 +1,163 @@
+package azschema
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// bicepIndex is the `index.json` file in the `generated` directory of bicep-types-az.
+type bicepIndex struct {
+	Resources map[string]TypeRef `json:"resources"` // The resource types by `type@apiVersion`, referencing a `types.json` file.
+}
+
+// typeKey identifies a type in a `types.json` file.
+type typeKey struct {
+	file  string
+	index int
+}
+
+// bundleBuilder copies the types reachable from the included resource types into a single type list.
+type bundleBuilder struct {
+	dir     string
+	files   map[string][]any // The decoded `types.json` files by path.
+	indexes map[typeKey]int  // The index in types of each copied type.
+	types   []any
+}
+
+// WriteBundle writes a gzip compressed Bundle of the resource types in the bicep-types-az `generated` directory.
+// If include is not empty only the resource types in it are written, the types are case insensitive and have no API version.
+func WriteBundle(w io.Writer, dir string, include []string) error {
+	b, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		return fmt.Errorf("could not read index: %s", err)
+	}
+	var idx bicepIndex
+	if err := json.Unmarshal(b, &idx); err != nil {
+		return fmt.Errorf("could not decode index: %s", err)
+	}
+	included := make(map[string]bool, len(include))
+	for _, t := range include {
+		included[strings.ToLower(t)] = true
+	}
+
+	builder := &bundleBuilder{
+		dir:     dir,
+		files:   make(map[string][]any),
+		indexes: make(map[typeKey]int),
+	}
+	resources := make(map[string]TypeRef)
+	keys := sortedKeys(idx.Resources)
+	for _, key := range keys {
+		resourceType, _, _ := strings.Cut(key, "@")
+		if len(included) > 0 && !included[strings.ToLower(resourceType)] {
+			continue
+		}
+		ref := idx.Resources[key]
+		file, _, _ := strings.Cut(ref.Ref, "#")
+		i, err := ref.Index()
+		if err != nil {
+			return err
+		}
+		n, err := builder.copy(typeKey{file: file, index: i})
+		if err != nil {
+			return fmt.Errorf("could not copy `%s`: %s", key, err)
+		}
+		resources[key] = TypeRef{Ref: fmt.Sprintf("#/%d", n)}
+	}
+
+	zw := gzip.NewWriter(w)
+	enc := json.NewEncoder(zw)
+	if err := enc.Encode(struct {
+		Resources map[string]TypeRef `json:"resources"`
+		Types     []any              `json:"types"`
+	}{resources, builder.types}); err != nil {
+		return fmt.Errorf("could not encode schemas: %s", err)
+	}
+	return zw.Close()
+}
+
+// copy copies the type and the types it references, and returns its index in the bundle.
+func (b *bundleBuilder) copy(key typeKey) (int, error) {
+	if n, ok := b.indexes[key]; ok {
+		return n, nil
+	}
+	types, err := b.file(key.file)
+	if err != nil {
+		return 0, err
+	}
+	if key.index < 0 || key.index >= len(types) {
+		return 0, fmt.Errorf("type reference `%s#/%d` is out of range", key.file, key.index)
+	}
+	n := len(b.types)
+	b.indexes[key] = n
+	b.types = append(b.types, nil)
+	t, err := b.rewriteRefs(key.file, types[key.index])
+	if err != nil {
+		return 0, err
+	}
+	b.types[n] = t
+	return n, nil
+}
+
+// rewriteRefs returns a copy of the decoded JSON value with the local type references rewritten to bundle indexes.
+func (b *bundleBuilder) rewriteRefs(file string, v any) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(v))
+		for _, k := range sortedKeys(v) {
+			item := v[k]
+			if ref, ok := item.(string); ok && k == "$ref" {
+				i, err := TypeRef{Ref: ref}.Index()
+				if err != nil {
+					return nil, err
+				}
+				n, err := b.copy(typeKey{file: file, index: i})
+				if err != nil {
+					return nil, err
+				}
+				res[k] = fmt.Sprintf("#/%d", n)
+				continue
+			}
+			rewritten, err := b.rewriteRefs(file, item)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = rewritten
+		}
+		return res, nil
+	case []any:
+		res := make([]any, len(v))
+		for i, item := range v {
+			rewritten, err := b.rewriteRefs(file, item)
+			if err != nil {
+				return nil, err
+			}
+			res[i] = rewritten
+		}
+		return res, nil
+	default:
+		return v, nil
+	}
+}
+
+// file returns the decoded `types.json` file, relative to the generated directory.
+func (b *bundleBuilder) file(path string) ([]any, error) {
+	if types, ok := b.files[path]; ok {
+		return types, nil
+	}
+	data, err := os.ReadFile(filepath.Join(b.dir, filepath.FromSlash(path)))
+	if err != nil {
+		return nil, fmt.Errorf("could not read types: %s", err)
+	}
+	var types []any
+	if err := json.Unmarshal(data, &types); err != nil {
+		return nil, fmt.Errorf("could not decode types in `%s`: %s", path, err)
+	}
+	b.files[path] = types
+	return types, nil
+}
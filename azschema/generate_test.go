@@ -0,0 +1,28 @@
+package azschema
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBundle(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteBundle(&buf, "bicep-types/generated", []string{"microsoft.keyvault/vaults"}))
+	s, err := Load(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Microsoft.KeyVault/vaults"}, s.ResourceTypes())
+	rt, ok := s.Resource("Microsoft.KeyVault/vaults", "2023-07-01")
+	require.True(t, ok)
+	body, err := s.Type(*rt.Body)
+	require.NoError(t, err)
+	assert.Contains(t, body.Properties, "properties")
+}
+
+func TestDefaultIsUpToDate(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteBundle(&buf, "bicep-types/generated", nil))
+	assert.True(t, bytes.Equal(buf.Bytes(), bundle), "run `go generate ./azschema` to update the bundled schemas")
+}
@@ -0,0 +1,117 @@
+package azschema
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:generate go run ../cmd/azschemagen -in bicep-types/generated -out schemas.json.gz
+
+//go:embed schemas.json.gz
+var bundle []byte
+
+// Bundle is the serialized form of a Schema: the resource types and the types they reference.
+type Bundle struct {
+	Resources map[string]TypeRef `json:"resources"` // The resource types by `type@apiVersion`.
+	Types     []Type             `json:"types"`     // The types, referenced by their index.
+}
+
+// Schema is a set of Azure resource type schemas.
+type Schema struct {
+	types     []Type
+	resources map[string]int // The index of the resource type by lower case `type@apiVersion`.
+}
+
+var defaultSchema = sync.OnceValues(func() (*Schema, error) {
+	return Load(bytes.NewReader(bundle))
+})
+
+// Default returns the schemas bundled with the plugin.
+func Default() (*Schema, error) {
+	return defaultSchema()
+}
+
+// Load reads a gzip compressed Bundle.
+func Load(r io.Reader) (*Schema, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not decompress schemas: %s", err)
+	}
+	defer zr.Close()
+	var b Bundle
+	if err := json.NewDecoder(zr).Decode(&b); err != nil {
+		return nil, fmt.Errorf("could not decode schemas: %s", err)
+	}
+	return New(b)
+}
+
+// New returns the Schema of the bundle.
+func New(b Bundle) (*Schema, error) {
+	s := &Schema{
+		types:     b.Types,
+		resources: make(map[string]int, len(b.Resources)),
+	}
+	for key, ref := range b.Resources {
+		i, err := s.index(ref)
+		if err != nil {
+			return nil, err
+		}
+		if s.types[i].Kind != KindResource {
+			return nil, fmt.Errorf("`%s` does not reference a resource type", key)
+		}
+		s.resources[strings.ToLower(key)] = i
+	}
+	return s, nil
+}
+
+// Resource returns the resource type with the given type and API version.
+// Resource types are case insensitive.
+func (s *Schema) Resource(resourceType, apiVersion string) (*Type, bool) {
+	i, ok := s.resources[strings.ToLower(resourceType+"@"+apiVersion)]
+	if !ok {
+		return nil, false
+	}
+	return &s.types[i], true
+}
+
+// Type returns the referenced type.
+func (s *Schema) Type(ref TypeRef) (*Type, error) {
+	i, err := s.index(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &s.types[i], nil
+}
+
+// ResourceTypes returns the sorted resource types in the bundle, without API versions.
+func (s *Schema) ResourceTypes() []string {
+	seen := make(map[string]bool)
+	res := make([]string, 0)
+	for _, i := range s.resources {
+		t, _, _ := strings.Cut(s.types[i].Name, "@")
+		if !seen[strings.ToLower(t)] {
+			seen[strings.ToLower(t)] = true
+			res = append(res, t)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (s *Schema) index(ref TypeRef) (int, error) {
+	i, err := ref.Index()
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 || i >= len(s.types) {
+		return 0, fmt.Errorf("type reference `%s` is out of range", ref.Ref)
+	}
+	return i, nil
+}
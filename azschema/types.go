@@ -0,0 +1,101 @@
+package azschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kinds of type in the bicep-types-az type definitions.
+const (
+	KindAny                 = "AnyType"
+	KindNull                = "NullType"
+	KindBoolean             = "BooleanType"
+	KindInteger             = "IntegerType"
+	KindString              = "StringType"
+	KindStringLiteral       = "StringLiteralType"
+	KindArray               = "ArrayType"
+	KindObject              = "ObjectType"
+	KindDiscriminatedObject = "DiscriminatedObjectType"
+	KindUnion               = "UnionType"
+	KindResource            = "ResourceType"
+)
+
+// PropertyFlags are the flags of an object property.
+type PropertyFlags int
+
+// Property flags in the bicep-types-az type definitions.
+const (
+	FlagRequired           PropertyFlags = 1 << iota // The property must be set.
+	FlagReadOnly                                     // The property is returned by the API but cannot be set.
+	FlagWriteOnly                                    // The property can be set but is not returned by the API.
+	FlagDeployTimeConstant                           // The property must be known at deployment time.
+	FlagIdentifier                                   // The property identifies the resource, e.g. `name`.
+)
+
+// Has reports whether all the given flags are set.
+func (f PropertyFlags) Has(flag PropertyFlags) bool {
+	return f&flag == flag
+}
+
+// TypeRef is a reference to a type by its index in the type list, e.g. `#/12`.
+type TypeRef struct {
+	Ref string `json:"$ref"`
+}
+
+// Index returns the index of the referenced type.
+func (r TypeRef) Index() (int, error) {
+	i := strings.LastIndex(r.Ref, "#/")
+	if i < 0 {
+		return 0, fmt.Errorf("invalid type reference `%s`", r.Ref)
+	}
+	n, err := strconv.Atoi(r.Ref[i+2:])
+	if err != nil {
+		return 0, fmt.Errorf("invalid type reference `%s`: %s", r.Ref, err)
+	}
+	return n, nil
+}
+
+// Property is a property of an object type.
+type Property struct {
+	Type        TypeRef       `json:"type"`
+	Flags       PropertyFlags `json:"flags"`
+	Description string        `json:"description,omitempty"`
+}
+
+// Type is a type in the bicep-types-az type definitions.
+// Only the fields relevant to the Kind are set.
+type Type struct {
+	Kind                 string              `json:"$type"`
+	Name                 string              `json:"name,omitempty"`                 // The name of an object or resource type.
+	Value                string              `json:"value,omitempty"`                // The value of a string literal.
+	Properties           map[string]Property `json:"properties,omitempty"`           // The properties of an object.
+	AdditionalProperties *TypeRef            `json:"additionalProperties,omitempty"` // The type of properties not in Properties, nil if not allowed.
+	ItemType             *TypeRef            `json:"itemType,omitempty"`             // The type of the items of an array.
+	Discriminator        string              `json:"discriminator,omitempty"`        // The discriminator property of a discriminated object.
+	BaseProperties       map[string]Property `json:"baseProperties,omitempty"`       // The properties common to all variants of a discriminated object.
+	Body                 *TypeRef            `json:"body,omitempty"`                 // The body of a resource.
+	Elements             []TypeRef           `json:"-"`                              // The elements of a union.
+	Variants             map[string]TypeRef  `json:"-"`                              // The variants of a discriminated object, by discriminator value.
+}
+
+// UnmarshalJSON decodes a type, the `elements` field is a list for unions and a map for discriminated objects.
+func (t *Type) UnmarshalJSON(b []byte) error {
+	type plain Type
+	var raw struct {
+		plain
+		Elements json.RawMessage `json:"elements,omitempty"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*t = Type(raw.plain)
+	if len(raw.Elements) == 0 {
+		return nil
+	}
+	if t.Kind == KindDiscriminatedObject {
+		return json.Unmarshal(raw.Elements, &t.Variants)
+	}
+	return json.Unmarshal(raw.Elements, &t.Elements)
+}
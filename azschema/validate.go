@@ -0,0 +1,300 @@
+package azschema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Violation is a value in a resource body that does not match the schema.
+type Violation struct {
+	Path    string // The path of the value in the body, e.g. `properties.minimumTlsVersion`.
+	Message string // The reason the value is invalid.
+}
+
+// String returns the path and message of the violation.
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return fmt.Sprintf("`%s` %s", v.Path, v.Message)
+}
+
+// attributeProperties are the body properties that `azapi_resource` sets from its own arguments,
+// so they are not required in the body.
+var attributeProperties = map[string]bool{
+	"name":     true,
+	"location": true,
+	"tags":     true,
+	"identity": true,
+}
+
+// ValidateResource validates the body of an `azapi_resource` against the resource type.
+// Unknown values are not validated, null values are treated as absent.
+func (s *Schema) ValidateResource(resource *Type, body cty.Value) ([]Violation, error) {
+	if resource.Kind != KindResource || resource.Body == nil {
+		return nil, fmt.Errorf("`%s` is not a resource type", resource.Name)
+	}
+	v := &validator{schema: s}
+	body, _ = body.UnmarkDeep()
+	if err := v.value("", *resource.Body, body, true); err != nil {
+		return nil, err
+	}
+	return v.violations, nil
+}
+
+type validator struct {
+	schema     *Schema
+	violations []Violation
+}
+
+func (v *validator) report(path, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// value validates the value against the referenced type, top is set for the resource body.
+func (v *validator) value(path string, ref TypeRef, val cty.Value, top bool) error {
+	if !val.IsKnown() || val.IsNull() {
+		return nil
+	}
+	t, err := v.schema.Type(ref)
+	if err != nil {
+		return err
+	}
+	ty := val.Type()
+	switch t.Kind {
+	case KindAny, KindNull:
+	case KindBoolean:
+		if ty != cty.Bool {
+			v.report(path, "must be a bool, got %s", ty.FriendlyName())
+		}
+	case KindInteger:
+		if ty != cty.Number {
+			v.report(path, "must be an integer, got %s", ty.FriendlyName())
+		} else if !val.AsBigFloat().IsInt() {
+			v.report(path, "must be an integer, got `%s`", val.AsBigFloat().String())
+		}
+	case KindString:
+		if ty != cty.String {
+			v.report(path, "must be a string, got %s", ty.FriendlyName())
+		}
+	case KindStringLiteral:
+		if ty != cty.String {
+			v.report(path, "must be a string, got %s", ty.FriendlyName())
+		} else if !strings.EqualFold(val.AsString(), t.Value) {
+			v.report(path, "value `%s` must be `%s`", val.AsString(), t.Value)
+		}
+	case KindArray:
+		if !ty.IsListType() && !ty.IsTupleType() && !ty.IsSetType() {
+			v.report(path, "must be a list, got %s", ty.FriendlyName())
+			return nil
+		}
+		if t.ItemType == nil {
+			return nil
+		}
+		i := 0
+		for it := val.ElementIterator(); it.Next(); i++ {
+			_, item := it.Element()
+			if err := v.value(joinPath(path, strconv.Itoa(i)), *t.ItemType, item, false); err != nil {
+				return err
+			}
+		}
+	case KindObject:
+		return v.object(path, t.Properties, t.AdditionalProperties, val, top, false)
+	case KindDiscriminatedObject:
+		return v.discriminatedObject(path, t, val, top)
+	case KindUnion:
+		return v.union(path, t, val)
+	case KindResource:
+		if t.Body != nil {
+			return v.value(path, *t.Body, val, top)
+		}
+	default:
+		return fmt.Errorf("unsupported type `%s`", t.Kind)
+	}
+	return nil
+}
+
+// object validates the properties of an object value.
+// If open is set properties that are not in the schema are not reported, e.g. when the variant of a discriminated object is unknown.
+func (v *validator) object(path string, props map[string]Property, additional *TypeRef, val cty.Value, top, open bool) error {
+	ty := val.Type()
+	if !ty.IsObjectType() && !ty.IsMapType() {
+		v.report(path, "must be an object, got %s", ty.FriendlyName())
+		return nil
+	}
+	for it := val.ElementIterator(); it.Next(); {
+		k, item := it.Element()
+		key := k.AsString()
+		prop, ok := props[key]
+		switch {
+		case ok && prop.Flags.Has(FlagReadOnly):
+			v.report(joinPath(path, key), "is read-only")
+		case ok:
+			if err := v.value(joinPath(path, key), prop.Type, item, false); err != nil {
+				return err
+			}
+		case additional != nil:
+			if err := v.value(joinPath(path, key), *additional, item, false); err != nil {
+				return err
+			}
+		case open:
+		default:
+			if suggestion := suggestProperty(key, props); suggestion != "" {
+				v.report(joinPath(path, key), "is not a valid property, did you mean `%s`?", suggestion)
+			} else {
+				v.report(joinPath(path, key), "is not a valid property")
+			}
+		}
+	}
+	for _, key := range sortedKeys(props) {
+		prop := props[key]
+		if !prop.Flags.Has(FlagRequired) || prop.Flags.Has(FlagReadOnly) || (top && attributeProperties[key]) {
+			continue
+		}
+		if item, ok := property(val, key); !ok || item.IsNull() {
+			v.report(joinPath(path, key), "is required")
+		}
+	}
+	return nil
+}
+
+// discriminatedObject validates an object whose properties depend on the value of its discriminator property.
+func (v *validator) discriminatedObject(path string, t *Type, val cty.Value, top bool) error {
+	ty := val.Type()
+	if !ty.IsObjectType() && !ty.IsMapType() {
+		v.report(path, "must be an object, got %s", ty.FriendlyName())
+		return nil
+	}
+	props := make(map[string]Property, len(t.BaseProperties)+1)
+	for k, p := range t.BaseProperties {
+		props[k] = p
+	}
+	disc, ok := property(val, t.Discriminator)
+	if !ok {
+		v.report(joinPath(path, t.Discriminator), "is required")
+		return v.object(path, props, nil, val, top, true)
+	}
+	if !disc.IsKnown() || disc.IsNull() || disc.Type() != cty.String {
+		return v.object(path, props, nil, val, top, true)
+	}
+	ref, ok := variant(t.Variants, disc.AsString())
+	if !ok {
+		v.report(joinPath(path, t.Discriminator), "value `%s` is not one of %s", disc.AsString(), quoteList(sortedKeys(t.Variants)))
+		return v.object(path, props, nil, val, top, true)
+	}
+	vt, err := v.schema.Type(ref)
+	if err != nil {
+		return err
+	}
+	for k, p := range vt.Properties {
+		props[k] = p
+	}
+	// The discriminator has been validated by the variant lookup, so it is only validated against the schema if declared.
+	if _, ok := props[t.Discriminator]; !ok {
+		val = withoutKey(val, t.Discriminator)
+	}
+	return v.object(path, props, vt.AdditionalProperties, val, top, false)
+}
+
+// union validates that the value matches at least one element of the union.
+func (v *validator) union(path string, t *Type, val cty.Value) error {
+	literals := make([]string, 0, len(t.Elements))
+	for _, ref := range t.Elements {
+		sub := &validator{schema: v.schema}
+		if err := sub.value(path, ref, val, false); err != nil {
+			return err
+		}
+		if len(sub.violations) == 0 {
+			return nil
+		}
+		if et, err := v.schema.Type(ref); err == nil && et.Kind == KindStringLiteral {
+			literals = append(literals, et.Value)
+		} else {
+			literals = nil
+		}
+	}
+	if len(literals) == len(t.Elements) && val.Type() == cty.String {
+		v.report(path, "value `%s` is not one of %s", val.AsString(), quoteList(literals))
+		return nil
+	}
+	v.report(path, "does not match any of the allowed types")
+	return nil
+}
+
+// variant returns the variant for the discriminator value, compared case insensitively if there is no exact match.
+func variant(variants map[string]TypeRef, value string) (TypeRef, bool) {
+	if ref, ok := variants[value]; ok {
+		return ref, true
+	}
+	for k, ref := range variants {
+		if strings.EqualFold(k, value) {
+			return ref, true
+		}
+	}
+	return TypeRef{}, false
+}
+
+// suggestProperty returns the property that differs from the key only by case, if any.
+func suggestProperty(key string, props map[string]Property) string {
+	for name := range props {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+	}
+	return ""
+}
+
+// property returns the value of the key in an object or map value.
+func property(val cty.Value, key string) (cty.Value, bool) {
+	if val.Type().IsObjectType() {
+		if !val.Type().HasAttribute(key) {
+			return cty.NilVal, false
+		}
+		return val.GetAttr(key), true
+	}
+	if !val.IsKnown() || !val.HasIndex(cty.StringVal(key)).True() {
+		return cty.NilVal, false
+	}
+	return val.Index(cty.StringVal(key)), true
+}
+
+// withoutKey returns the object or map value without the given key.
+func withoutKey(val cty.Value, key string) cty.Value {
+	m := val.AsValueMap()
+	delete(m, key)
+	if val.Type().IsMapType() {
+		if len(m) == 0 {
+			return cty.MapValEmpty(val.Type().ElementType())
+		}
+		return cty.MapVal(m)
+	}
+	return cty.ObjectVal(m)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "`" + v + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
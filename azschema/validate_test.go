@@ -0,0 +1,201 @@
+package azschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestValidateResource(t *testing.T) {
+	testCases := []struct {
+		name       string
+		type_      string
+		apiVersion string
+		body       cty.Value
+		want       []string
+	}{
+		{
+			name:       "valid",
+			type_:      "Microsoft.Storage/storageAccounts",
+			apiVersion: "2023-01-01",
+			body: cty.ObjectVal(map[string]cty.Value{
+				"kind": cty.StringVal("StorageV2"),
+				"sku":  cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("Standard_ZRS")}),
+				"properties": cty.ObjectVal(map[string]cty.Value{
+					"minimumTlsVersion":        cty.StringVal("TLS1_2"),
+					"supportsHttpsTrafficOnly": cty.True,
+				}),
+			}),
+			want: []string{},
+		},
+		{
+			name:       "unknown property with different case",
+			type_:      "Microsoft.Storage/storageAccounts",
+			apiVersion: "2023-01-01",
+			body: cty.ObjectVal(map[string]cty.Value{
+				"kind": cty.StringVal("StorageV2"),
+				"sku":  cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("Standard_ZRS")}),
+				"properties": cty.ObjectVal(map[string]cty.Value{
+					"minimumTLSVersion": cty.StringVal("TLS1_2"),
+					"foo":               cty.StringVal("bar"),
+				}),
+			}),
+			want: []string{
+				"`properties.foo` is not a valid property",
+				"`properties.minimumTLSVersion` is not a valid property, did you mean `minimumTlsVersion`?",
+			},
+		},
+		{
+			name:       "wrong type and missing required",
+			type_:      "Microsoft.Storage/storageAccounts",
+			apiVersion: "2023-01-01",
+			body: cty.ObjectVal(map[string]cty.Value{
+				"properties": cty.ObjectVal(map[string]cty.Value{
+					"supportsHttpsTrafficOnly": cty.StringVal("true"),
+				}),
+			}),
+			want: []string{
+				"`properties.supportsHttpsTrafficOnly` must be a bool, got string",
+				"`kind` is required",
+				"`sku` is required",
+			},
+		},
+		{
+			name:       "invalid closed enum",
+			type_:      "Microsoft.KeyVault/vaults",
+			apiVersion: "2023-07-01",
+			body: cty.ObjectVal(map[string]cty.Value{
+				"properties": cty.ObjectVal(map[string]cty.Value{
+					"tenantId": cty.StringVal("00000000-0000-0000-0000-000000000000"),
+					"sku": cty.ObjectVal(map[string]cty.Value{
+						"family": cty.StringVal("A"),
+						"name":   cty.StringVal("basic"),
+					}),
+				}),
+			}),
+			want: []string{
+				"`properties.sku.name` value `basic` is not one of `standard`, `premium`",
+			},
+		},
+		{
+			name:       "open enum accepts any string",
+			type_:      "Microsoft.Storage/storageAccounts",
+			apiVersion: "2023-01-01",
+			body: cty.ObjectVal(map[string]cty.Value{
+				"kind": cty.StringVal("StorageV3"),
+				"sku":  cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("Standard_LRS")}),
+			}),
+			want: []string{},
+		},
+		{
+			name:       "read-only property and array items",
+			type_:      "Microsoft.Network/virtualNetworks",
+			apiVersion: "2023-11-01",
+			body: cty.ObjectVal(map[string]cty.Value{
+				"properties": cty.ObjectVal(map[string]cty.Value{
+					"provisioningState": cty.StringVal("Succeeded"),
+					"addressSpace": cty.ObjectVal(map[string]cty.Value{
+						"addressPrefixes": cty.TupleVal([]cty.Value{cty.StringVal("10.0.0.0/16"), cty.NumberIntVal(1)}),
+					}),
+				}),
+			}),
+			want: []string{
+				"`properties.addressSpace.addressPrefixes.1` must be a string, got number",
+				"`properties.provisioningState` is read-only",
+			},
+		},
+		{
+			name:       "unknown values are not validated",
+			type_:      "Microsoft.Storage/storageAccounts",
+			apiVersion: "2023-01-01",
+			body: cty.ObjectVal(map[string]cty.Value{
+				"kind":       cty.UnknownVal(cty.String),
+				"sku":        cty.DynamicVal,
+				"properties": cty.UnknownVal(cty.DynamicPseudoType),
+			}),
+			want: []string{},
+		},
+	}
+	s, err := Default()
+	require.NoError(t, err)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rt, ok := s.Resource(tc.type_, tc.apiVersion)
+			require.True(t, ok)
+			violations, err := s.ValidateResource(rt, tc.body)
+			require.NoError(t, err)
+			got := make([]string, len(violations))
+			for i, v := range violations {
+				got[i] = v.String()
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestUnknownProperties(t *testing.T) {
+	b := Bundle{
+		Resources: map[string]TypeRef{"Test/things@2024-01-01": {Ref: "#/0"}},
+		Types: []Type{
+			{Kind: KindResource, Name: "Test/things@2024-01-01", Body: &TypeRef{Ref: "#/1"}},
+			{Kind: KindObject, Properties: map[string]Property{"size": {Type: TypeRef{Ref: "#/2"}}}},
+			{Kind: KindInteger},
+		},
+	}
+	body := cty.ObjectVal(map[string]cty.Value{
+		"Size":  cty.NumberIntVal(1),
+		"other": cty.StringVal("test"),
+	})
+	s, err := New(b)
+	require.NoError(t, err)
+	rt, ok := s.Resource("Test/things", "2024-01-01")
+	require.True(t, ok)
+	violations, err := s.ValidateResource(rt, body)
+	require.NoError(t, err)
+	assert.Equal(t, []Violation{
+		{Path: "Size", Message: "is not a valid property, did you mean `size`?"},
+		{Path: "other", Message: "is not a valid property"},
+	}, violations)
+}
+
+func TestDiscriminatedObject(t *testing.T) {
+	s, err := New(Bundle{
+		Resources: map[string]TypeRef{"Test/things@2024-01-01": {Ref: "#/0"}},
+		Types: []Type{
+			{Kind: KindResource, Name: "Test/things@2024-01-01", Body: &TypeRef{Ref: "#/1"}},
+			{Kind: KindObject, Properties: map[string]Property{"properties": {Type: TypeRef{Ref: "#/2"}}}},
+			{
+				Kind:           KindDiscriminatedObject,
+				Discriminator:  "kind",
+				BaseProperties: map[string]Property{"description": {Type: TypeRef{Ref: "#/3"}}},
+				Variants:       map[string]TypeRef{"A": {Ref: "#/4"}},
+			},
+			{Kind: KindString},
+			{Kind: KindObject, Properties: map[string]Property{"size": {Type: TypeRef{Ref: "#/5"}, Flags: FlagRequired}}},
+			{Kind: KindInteger},
+		},
+	})
+	require.NoError(t, err)
+	rt, ok := s.Resource("test/things", "2024-01-01")
+	require.True(t, ok)
+
+	violations, err := s.ValidateResource(rt, cty.ObjectVal(map[string]cty.Value{
+		"properties": cty.ObjectVal(map[string]cty.Value{
+			"kind":        cty.StringVal("A"),
+			"description": cty.StringVal("test"),
+		}),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []Violation{{Path: "properties.size", Message: "is required"}}, violations)
+
+	violations, err = s.ValidateResource(rt, cty.ObjectVal(map[string]cty.Value{
+		"properties": cty.ObjectVal(map[string]cty.Value{
+			"kind":  cty.StringVal("B"),
+			"other": cty.StringVal("test"),
+		}),
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []Violation{{Path: "properties.kind", Message: "value `B` is not one of `A`"}}, violations)
+}
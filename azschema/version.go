@@ -0,0 +1,77 @@
+package azschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// apiVersionLayout is the layout of the date at the start of an API version.
+const apiVersionLayout = "2006-01-02"
+
+// IsPreview reports whether the API version is a preview version, e.g. `2021-04-01-preview`.
+func IsPreview(apiVersion string) bool {
+	return len(apiVersion) > len(apiVersionLayout)
+}
+
+// VersionDate returns the date of the API version.
+func VersionDate(apiVersion string) (time.Time, error) {
+	if len(apiVersion) < len(apiVersionLayout) {
+		return time.Time{}, fmt.Errorf("invalid API version `%s`", apiVersion)
+	}
+	t, err := time.Parse(apiVersionLayout, apiVersion[:len(apiVersionLayout)])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid API version `%s`: %s", apiVersion, err)
+	}
+	return t, nil
+}
+
+// CompareVersions compares API versions by date, a preview version is older than the stable version of the same date.
+func CompareVersions(a, b string) int {
+	if c := strings.Compare(datePart(a), datePart(b)); c != 0 {
+		return c
+	}
+	switch {
+	case IsPreview(a) && !IsPreview(b):
+		return -1
+	case !IsPreview(a) && IsPreview(b):
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// LatestStable returns the newest stable version in the list, or an empty string if there is none.
+func LatestStable(versions []string) string {
+	latest := ""
+	for _, v := range versions {
+		if !IsPreview(v) && (latest == "" || CompareVersions(v, latest) > 0) {
+			latest = v
+		}
+	}
+	return latest
+}
+
+// APIVersions returns the API versions of the resource type in the bundle, oldest first.
+// Resource types are case insensitive.
+func (s *Schema) APIVersions(resourceType string) []string {
+	prefix := strings.ToLower(resourceType) + "@"
+	res := make([]string, 0)
+	for key, i := range s.resources {
+		if strings.HasPrefix(key, prefix) {
+			_, v, _ := strings.Cut(s.types[i].Name, "@")
+			res = append(res, v)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return CompareVersions(res[i], res[j]) < 0
+	})
+	return res
+}
+
+func datePart(apiVersion string) string {
+	if len(apiVersion) < len(apiVersionLayout) {
+		return apiVersion
+	}
+	return apiVersion[:len(apiVersionLayout)]
+}
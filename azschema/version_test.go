@@ -0,0 +1,35 @@
+package azschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIVersions(t *testing.T) {
+	s, err := Default()
+	require.NoError(t, err)
+	versions := s.APIVersions("microsoft.keyvault/vaults")
+	assert.Equal(t, []string{"2021-04-01-preview", "2022-07-01", "2023-07-01"}, versions)
+	assert.Equal(t, "2023-07-01", LatestStable(versions))
+	assert.Empty(t, s.APIVersions("Microsoft.Unknown/things"))
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want int
+	}{
+		{a: "2023-01-01", b: "2023-05-01", want: -1},
+		{a: "2023-01-01-preview", b: "2023-01-01", want: -1},
+		{a: "2023-01-01", b: "2022-12-01-preview", want: 1},
+		{a: "2023-01-01", b: "2023-01-01", want: 0},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, CompareVersions(tc.a, tc.b), "%s %s", tc.a, tc.b)
+	}
+	assert.True(t, IsPreview("2021-04-01-preview"))
+	assert.False(t, IsPreview("2021-04-01"))
+	assert.Equal(t, "", LatestStable([]string{"2021-04-01-preview"}))
+}
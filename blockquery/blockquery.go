@@ -25,3 +25,11 @@ func NewBlockQuery(
 		CompareFunc:     cmpFn,
 	}
 }
+
+// QueryPath returns the path of the queried value in the block, e.g. `body.properties.minimumTlsVersion`.
+func (bq BlockQuery) QueryPath() string {
+	if bq.Query == "" {
+		return bq.QueryAttribute
+	}
+	return bq.QueryAttribute + "." + bq.Query
+}
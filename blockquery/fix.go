@@ -0,0 +1,120 @@
+package blockquery
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// FixObjectValue uses the fixer to set the value at the query path in an object expression, e.g. the `body` attribute.
+// The value is replaced if it is a literal, or inserted if it does not exist, creating any missing parent objects.
+// Only simple queries of dot separated keys are supported.
+// If the expression or the value at the path is not written as a literal in the file, tflint.ErrFixNotSupported is returned.
+func FixObjectValue(f tflint.Fixer, expr hcl.Expression, query string, value cty.Value) error {
+	keys, ok := simpleQueryKeys(query)
+	if !ok {
+		return tflint.ErrFixNotSupported
+	}
+	obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return tflint.ErrFixNotSupported
+	}
+	for i, key := range keys {
+		item := objectItem(obj, key)
+		if item == nil {
+			return insertObjectItem(f, obj, keys[i:], f.ValueText(value))
+		}
+		if i == len(keys)-1 {
+			if _, diags := item.ValueExpr.Value(nil); diags.HasErrors() {
+				return tflint.ErrFixNotSupported
+			}
+			return f.ReplaceText(item.ValueExpr.Range(), f.ValueText(value))
+		}
+		if obj, ok = item.ValueExpr.(*hclsyntax.ObjectConsExpr); !ok {
+			return tflint.ErrFixNotSupported
+		}
+	}
+	return tflint.ErrFixNotSupported
+}
+
+// simpleQueryKeys splits a gjson query of dot separated keys, returning false if the query uses any other syntax.
+func simpleQueryKeys(query string) ([]string, bool) {
+	if query == "" || strings.ContainsAny(query, `#*?|@\!{}[]`) {
+		return nil, false
+	}
+	keys := strings.Split(query, ".")
+	for _, k := range keys {
+		if k == "" {
+			return nil, false
+		}
+	}
+	return keys, true
+}
+
+// objectItem returns the item of the object expression with the given key, or nil if there is none.
+func objectItem(obj *hclsyntax.ObjectConsExpr, key string) *hclsyntax.ObjectConsItem {
+	for i := range obj.Items {
+		k, diags := obj.Items[i].KeyExpr.Value(nil)
+		if diags.HasErrors() || !k.IsKnown() || k.IsNull() || k.Type() != cty.String {
+			continue
+		}
+		if k.AsString() == key {
+			return &obj.Items[i]
+		}
+	}
+	return nil
+}
+
+// insertObjectItem inserts the value at the path of keys after the last item of the object expression.
+// The changes are formatted by tflint, so the inserted text is not indented.
+func insertObjectItem(f tflint.Fixer, obj *hclsyntax.ObjectConsExpr, keys []string, valueText string) error {
+	text := valueText
+	for i := len(keys) - 1; i >= 0; i-- {
+		if i == len(keys)-1 {
+			text = fmt.Sprintf("%s = %s", objectKeyText(keys[i]), text)
+			continue
+		}
+		text = fmt.Sprintf("%s = {\n%s\n}", objectKeyText(keys[i]), text)
+	}
+	if len(obj.Items) == 0 {
+		return f.InsertTextAfter(obj.OpenRange, "\n"+text+"\n")
+	}
+	return f.InsertTextAfter(obj.Items[len(obj.Items)-1].ValueExpr.Range(), "\n"+text)
+}
+
+// objectKeyText returns the key as an identifier if it is valid, otherwise as a quoted string.
+func objectKeyText(key string) string {
+	if hclsyntax.ValidIdentifier(key) {
+		return key
+	}
+	return fmt.Sprintf("%q", key)
+}
+
+// ObjectValueExpr returns the expression at the query path in an object expression, e.g. the `body` attribute.
+// A `jsonencode` call is looked through. If an expression along the path is not an object literal, e.g. a variable, it is returned with the keys of the path below it.
+// False is returned if the query is not a simple query of dot separated keys, or a key does not exist in an object literal.
+func ObjectValueExpr(expr hcl.Expression, query string) (hcl.Expression, []string, bool) {
+	keys, ok := simpleQueryKeys(query)
+	if !ok {
+		return nil, nil, false
+	}
+	for i, key := range keys {
+		if call, ok := expr.(*hclsyntax.FunctionCallExpr); ok && call.Name == "jsonencode" && len(call.Args) == 1 {
+			expr = call.Args[0]
+		}
+		obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			return expr, keys[i:], true
+		}
+		item := objectItem(obj, key)
+		if item == nil {
+			return nil, nil, false
+		}
+		expr = item.ValueExpr
+	}
+	return expr, nil, true
+}
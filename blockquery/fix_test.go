@@ -0,0 +1,62 @@
+package blockquery
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimpleQueryKeys(t *testing.T) {
+	testCases := []struct {
+		query string
+		want  []string
+		ok    bool
+	}{
+		{query: "properties.minimumTlsVersion", want: []string{"properties", "minimumTlsVersion"}, ok: true},
+		{query: "sku", want: []string{"sku"}, ok: true},
+		{query: "properties.subnets.#.name"},
+		{query: "properties.*"},
+		{query: "properties..name"},
+		{query: ""},
+	}
+	for _, tc := range testCases {
+		got, ok := simpleQueryKeys(tc.query)
+		assert.Equal(t, tc.ok, ok, tc.query)
+		assert.Equal(t, tc.want, got, tc.query)
+	}
+}
+
+func TestObjectValueExpr(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     string
+		query    string
+		wantExpr string
+		wantKeys []string
+		ok       bool
+	}{
+		{name: "literal", expr: `{ properties = { minimumTlsVersion = "TLS1_2" } }`, query: "properties.minimumTlsVersion", wantExpr: `"TLS1_2"`, ok: true},
+		{name: "variable", expr: `{ properties = { minimumTlsVersion = var.tls } }`, query: "properties.minimumTlsVersion", wantExpr: "var.tls", ok: true},
+		{name: "variable object", expr: `{ properties = var.properties }`, query: "properties.minimumTlsVersion", wantExpr: "var.properties", wantKeys: []string{"minimumTlsVersion"}, ok: true},
+		{name: "jsonencode", expr: `jsonencode({ "properties" = { minimumTlsVersion = var.tls } })`, query: "properties.minimumTlsVersion", wantExpr: "var.tls", ok: true},
+		{name: "missing", expr: `{ properties = {} }`, query: "properties.minimumTlsVersion"},
+		{name: "not a simple query", expr: `{ properties = {} }`, query: "properties.*"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			src := []byte(tc.expr)
+			expr, diags := hclsyntax.ParseExpression(src, "main.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors(), diags.Error())
+			got, keys, ok := ObjectValueExpr(expr, tc.query)
+			assert.Equal(t, tc.ok, ok)
+			if !tc.ok {
+				return
+			}
+			assert.Equal(t, tc.wantExpr, string(got.Range().SliceBytes(src)))
+			assert.Equal(t, tc.wantKeys, keys)
+		})
+	}
+}
@@ -8,10 +8,39 @@ import (
 	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
+// Query marshals the value to JSON and runs the gjson query against it.
 func Query(val cty.Value, ty cty.Type, query string) (gjson.Result, error) {
-	jsonbytes, err := ctyjson.Marshal(val, ty)
+	doc, err := Marshal(val, ty)
 	if err != nil {
-		return gjson.Result{}, fmt.Errorf("could not marshal cty value: %s", err)
+		return gjson.Result{}, err
 	}
-	return gjson.GetBytes(jsonbytes, "value."+query), nil
+	return QueryDocument(doc, query), nil
+}
+
+// Marshal marshals the value to the JSON document that QueryDocument runs queries against.
+// Use it with QueryDocument to run several queries against a value without marshalling it each time.
+func Marshal(val cty.Value, ty cty.Type) ([]byte, error) {
+	doc, err := ctyjson.Marshal(val, ty)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal cty value: %s", err)
+	}
+	return doc, nil
+}
+
+// QueryDocument runs the gjson query against a document returned by Marshal.
+func QueryDocument(doc []byte, query string) gjson.Result {
+	return gjson.GetBytes(doc, "value."+query)
+}
+
+// UnknownAsNull replaces unknown values with nulls of the same type and removes any marks.
+// This allows values that contain references to other resources to be marshalled to JSON.
+func UnknownAsNull(val cty.Value) cty.Value {
+	val, _ = val.UnmarkDeep()
+	res, _ := cty.Transform(val, func(_ cty.Path, v cty.Value) (cty.Value, error) {
+		if !v.IsKnown() {
+			return cty.NullVal(v.Type()), nil
+		}
+		return v, nil
+	})
+	return res
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -16,3 +17,30 @@ func TestQuery(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "value", result.String())
 }
+
+func TestQueryUnknownAsNull(t *testing.T) {
+	ctyVal := cty.ObjectVal(map[string]cty.Value{
+		"known":   cty.StringVal("value"),
+		"unknown": cty.UnknownVal(cty.String),
+	})
+	result, err := Query(UnknownAsNull(ctyVal), cty.DynamicPseudoType, "known")
+	require.NoError(t, err)
+	require.Equal(t, "value", result.String())
+	result, err = Query(UnknownAsNull(ctyVal), cty.DynamicPseudoType, "unknown")
+	require.NoError(t, err)
+	require.Equal(t, gjson.Null, result.Type)
+}
+
+func TestQueryDocument(t *testing.T) {
+	doc, err := Marshal(cty.ObjectVal(map[string]cty.Value{
+		"properties": cty.ObjectVal(map[string]cty.Value{
+			"minimumTlsVersion": cty.StringVal("TLS1_2"),
+		}),
+	}), cty.DynamicPseudoType)
+	require.NoError(t, err)
+	require.Equal(t, "TLS1_2", QueryDocument(doc, "properties.minimumTlsVersion").String())
+	require.False(t, QueryDocument(doc, "properties.publicNetworkAccess").Exists())
+
+	_, err = Marshal(cty.UnknownVal(cty.String), cty.DynamicPseudoType)
+	require.ErrorContains(t, err, "could not marshal cty value")
+}
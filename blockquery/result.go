@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
 func NewResult(ty gjson.Type, val any) gjson.Result {
@@ -72,3 +74,22 @@ func NewComplexResults(vals ...any) []gjson.Result {
 	}
 	return results
 }
+
+// NewResultsFromCty creates results from the elements of a cty list, set or tuple value,
+// e.g. the value of an `expected = ["a", "b"]` attribute in a configuration file.
+// A value that is not a collection creates a single result.
+func NewResultsFromCty(val cty.Value) ([]gjson.Result, error) {
+	if !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("value is not known")
+	}
+	val, _ = val.UnmarkDeep()
+	jsonbytes, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal cty value: %s", err)
+	}
+	res := gjson.ParseBytes(jsonbytes)
+	if !res.IsArray() {
+		return []gjson.Result{res}, nil
+	}
+	return res.Array(), nil
+}
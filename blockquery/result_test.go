@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestNewStringResult(t *testing.T) {
@@ -70,3 +71,22 @@ func TestNewJsonResultArray(t *testing.T) {
 	got := gjson.GetBytes(source, "key")
 	assert.Equal(t, results[0].Value(), got.Value())
 }
+
+func TestNewResultsFromCty(t *testing.T) {
+	results, err := NewResultsFromCty(cty.TupleVal([]cty.Value{
+		cty.StringVal("a"),
+		cty.NumberIntVal(1),
+		cty.True,
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, NewStringResults("a")[0].Value(), results[0].Value())
+	assert.Equal(t, NewNumberResults(1)[0].Value(), results[1].Value())
+	assert.Equal(t, NewTrueResult().Value(), results[2].Value())
+
+	results, err = NewResultsFromCty(cty.StringVal("a"))
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	_, err = NewResultsFromCty(cty.UnknownVal(cty.String))
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package check
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruleset"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+)
+
+// Result is the result of running the ruleset.
+type Result struct {
+	Issues []Issue       // The issues emitted by the rules.
+	Checks []score.Check // The checks made by the rules, for scoring.
+}
+
+// Run applies the config to the ruleset and runs each enabled rule against the configuration in the working directory.
+// The configuration is read from modulecontent.AppFs relative to the current directory, as in the plugin.
+func Run(rs *ruleset.RuleSet, config *Config, wd string) (*Result, error) {
+	if err := rs.ApplyGlobalConfig(config.Global); err != nil {
+		return nil, fmt.Errorf("could not apply config: %s", err)
+	}
+	content := &hclext.BodyContent{}
+	if config.Plugin != nil {
+		var diags hcl.Diagnostics
+		content, diags = hclext.PartialContent(config.Plugin, rs.ConfigSchema())
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+	if err := rs.ApplyConfig(content); err != nil {
+		return nil, fmt.Errorf("could not apply plugin config: %s", err)
+	}
+	runner := NewRunner(wd, config, rs.Variables(config.Variables))
+	runner.runConfig = rs.RunConfig()
+	for _, rule := range rs.EnabledRules {
+		if err := rule.Check(runner); err != nil {
+			return nil, fmt.Errorf("failed to check %s rule: %s", rule.Name(), err)
+		}
+	}
+	return &Result{Issues: runner.Issues, Checks: runner.Checks}, nil
+}
@@ -0,0 +1,386 @@
+package check
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruleset"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+const testConfig = `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name = "sa"
+  body = {
+    properties = {
+      minimumTlsVersion = "TLS1_0"
+    }
+  }
+}
+`
+
+func testRules() []tflint.Rule {
+	return []tflint.Rule{
+		rules.NewAzApiRule("tls", "https://example.com", "Microsoft.Storage/storageAccounts", "", "", "properties.minimumTlsVersion", blockquery.IsOneOf, blockquery.NewStringResults("TLS1_2")...).
+			WithMetadata(waf.Metadata{Pillar: waf.Security, RecommendationIDs: []string{"SE:07"}}),
+		rules.NewAzApiRule("public_network_access", "https://example.com", "Microsoft.Storage/storageAccounts", "", "", "properties.publicNetworkAccess", blockquery.IsOneOfAndMustExist, blockquery.NewStringResults("Disabled")...),
+	}
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name: "no config",
+			want: []string{"tls", "public_network_access"},
+		},
+		{
+			name: "rule disabled",
+			config: `
+rule "public_network_access" {
+  enabled = false
+}`,
+			want: []string{"tls"},
+		},
+		{
+			name: "rule config",
+			config: `
+rule "tls" {
+  enabled  = true
+  expected = ["TLS1_0", "TLS1_2"]
+}`,
+			want: []string{"public_network_access"},
+		},
+		{
+			name: "disabled by default and plugin config",
+			config: `
+config {
+  disabled_by_default = true
+}
+
+plugin "azure-wellarchitected" {
+  enabled = true
+
+  pillar "security" {
+    enabled = true
+  }
+}`,
+			want: []string{"tls"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"main.tf": testConfig}
+			if tc.config != "" {
+				files[DefaultConfigFile] = tc.config
+			}
+			stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(files))
+			defer stub.Reset()
+			wd, _ := os.Getwd()
+
+			config, err := LoadConfig(DefaultConfigFile)
+			require.NoError(t, err)
+			res, err := Run(ruleset.New(PluginName, "0.0.0", testRules()), config, wd)
+			require.NoError(t, err)
+			got := make([]string, len(res.Issues))
+			for i, issue := range res.Issues {
+				got[i] = issue.Rule.Name()
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRunVariables(t *testing.T) {
+	const main = `
+variable "tls" {
+  type    = string
+  default = "TLS1_2"
+}
+
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name = "sa"
+  body = {
+    properties = {
+      minimumTlsVersion   = var.tls
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+`
+	testCases := []struct {
+		name   string
+		config string
+		want   int
+	}{
+		{
+			name: "default",
+			want: 0,
+		},
+		{
+			name:   "config block varfile",
+			config: `config { varfile = ["dev.tfvars"] }`,
+			want:   1,
+		},
+		{
+			name: "plugin varfile takes precedence",
+			config: `
+config {
+  varfile = ["dev.tfvars"]
+}
+
+plugin "azure-wellarchitected" {
+  enabled = true
+  varfile = ["prod.tfvars"]
+}`,
+			want: 0,
+		},
+		{
+			name:   "config block variables take precedence",
+			config: `config { variables = ["tls=TLS1_0"] }`,
+			want:   1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{
+				"main.tf":     main,
+				"dev.tfvars":  `tls = "TLS1_0"`,
+				"prod.tfvars": `tls = "TLS1_2"`,
+			}
+			if tc.config != "" {
+				files[DefaultConfigFile] = tc.config
+			}
+			stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(files))
+			defer stub.Reset()
+			wd, _ := os.Getwd()
+			config, err := LoadConfig(DefaultConfigFile)
+			require.NoError(t, err)
+			res, err := Run(ruleset.New(PluginName, "0.0.0", testRules()[:1]), config, wd)
+			require.NoError(t, err)
+			assert.Len(t, res.Issues, tc.want)
+		})
+	}
+}
+
+func TestRunScenarios(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		"main.tf": `
+variable "tls" {
+  type = string
+}
+
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name = "sa"
+  body = {
+    properties = {
+      minimumTlsVersion   = var.tls
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+`,
+		"dev.tfvars":  `tls = "TLS1_0"`,
+		"prod.tfvars": `tls = "TLS1_2"`,
+		DefaultConfigFile: `
+plugin "azure-wellarchitected" {
+  enabled = true
+
+  scenario "dev" {
+    varfile = ["dev.tfvars"]
+  }
+
+  scenario "prod" {
+    varfile = ["prod.tfvars"]
+  }
+}`,
+	}))
+	defer stub.Reset()
+	wd, _ := os.Getwd()
+	config, err := LoadConfig(DefaultConfigFile)
+	require.NoError(t, err)
+	res, err := Run(ruleset.New(PluginName, "0.0.0", testRules()), config, wd)
+	require.NoError(t, err)
+	require.Len(t, res.Issues, 1)
+	assert.Equal(t, "tls", res.Issues[0].Rule.Name())
+	assert.True(t, strings.HasPrefix(res.Issues[0].Message, "[scenario: dev] "), res.Issues[0].Message)
+	require.Len(t, res.Checks, 2, "a check is recorded once for all scenarios")
+	assert.Equal(t, "tls", res.Checks[0].Rule.Name())
+	assert.False(t, res.Checks[0].Passed, "a check fails if it fails in any scenario")
+	assert.Equal(t, "public_network_access", res.Checks[1].Rule.Name())
+	assert.True(t, res.Checks[1].Passed)
+}
+
+func TestRunChecks(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{"main.tf": testConfig}))
+	defer stub.Reset()
+	wd, _ := os.Getwd()
+	config, err := LoadConfig(DefaultConfigFile)
+	require.NoError(t, err)
+	res, err := Run(ruleset.New(PluginName, "0.0.0", testRules()), config, wd)
+	require.NoError(t, err)
+	require.Len(t, res.Checks, 2)
+	assert.Equal(t, "azapi_resource.sa", res.Checks[0].Address)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts", res.Checks[0].ResourceType)
+	assert.False(t, res.Checks[0].Passed)
+}
+
+func TestLoadConfigNotFound(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{}))
+	defer stub.Reset()
+	_, err := LoadConfig(DefaultConfigFile)
+	assert.NoError(t, err)
+	_, err = LoadConfig("other.hcl")
+	assert.Error(t, err)
+}
+
+func TestWriteText(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{"main.tf": testConfig}))
+	defer stub.Reset()
+	wd, _ := os.Getwd()
+	config, err := LoadConfig(DefaultConfigFile)
+	require.NoError(t, err)
+	res, err := Run(ruleset.New(PluginName, "0.0.0", testRules()), config, wd)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, res.Issues))
+	assert.Equal(t, "Security (1)\n"+
+		"  main.tf:5,3-9,4: Error: returned value `TLS1_0` not in expected values `[TLS1_2]` [Security SE:07] (tls)\n"+
+		"Other (1)\n"+
+		"  main.tf:5,3-9,4: Error: returned value does not exist but expected (public_network_access)\n"+
+		"2 issue(s) found\n", buf.String())
+}
+
+func TestAtLeast(t *testing.T) {
+	rs := rules.NewApiVersionRule("warning", "https://example.com")
+	issues := []Issue{{Rule: testRules()[0]}, {Rule: rs}}
+	assert.Len(t, AtLeast(issues, tflint.ERROR), 1)
+	assert.Len(t, AtLeast(issues, tflint.WARNING), 2)
+	notice := Issue{Rule: &noticeRule{name: ruleset.TagExemptionRuleName}}
+	assert.Len(t, AtLeast(append(issues, notice), tflint.NOTICE), 3)
+	assert.Len(t, Failures(append(issues, notice), tflint.NOTICE), 2)
+	sev, err := ParseSeverity("warning")
+	require.NoError(t, err)
+	assert.Equal(t, tflint.WARNING, sev)
+	_, err = ParseSeverity("fatal")
+	assert.Error(t, err)
+}
+
+func TestRunProfileExpected(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		"main.tf": `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name = "sa"
+  body = {
+    sku = {
+      name = "Standard_ZRS"
+    }
+  }
+}
+`,
+		"profile.hcl": `
+config {
+  disabled_by_default = true
+}
+
+plugin "azure-wellarchitected" {
+  enabled = true
+  profile = "mission-critical"
+}`,
+		"default.hcl": `
+rule "azapi_storage_account_zone_redundancy" {
+  enabled = true
+}`,
+	}))
+	defer stub.Reset()
+	wd, _ := os.Getwd()
+
+	// The rulesets share the rule, the expected values of the profile only apply to the ruleset with the profile.
+	var zone []tflint.Rule
+	for _, rule := range rules.Rules {
+		if rule.Name() == "azapi_storage_account_zone_redundancy" {
+			zone = append(zone, rule)
+		}
+	}
+	require.Len(t, zone, 1)
+	for _, tc := range []struct {
+		config string
+		want   int
+	}{
+		{config: "profile.hcl", want: 1},
+		{config: "default.hcl", want: 0},
+		{config: "profile.hcl", want: 1},
+	} {
+		config, err := LoadConfig(tc.config)
+		require.NoError(t, err)
+		res, err := Run(ruleset.New(PluginName, "0.0.0", zone), config, wd)
+		require.NoError(t, err)
+		assert.Len(t, res.Issues, tc.want, tc.config)
+	}
+}
+
+func TestRunStartsFresh(t *testing.T) {
+	tagged := func(exempt string) map[string]string {
+		return map[string]string{"main.tf": `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name = "sa"
+  tags = {
+    "waf-exempt" = "` + exempt + `"
+  }
+  body = {
+    properties = {
+      minimumTlsVersion = "TLS1_0"
+    }
+  }
+}
+`}
+	}
+	rs := ruleset.New(PluginName, "0.0.0", testRules()[:1])
+	wd, _ := os.Getwd()
+	for _, tc := range []struct {
+		exempt string
+		want   string
+	}{
+		{exempt: "tls", want: ruleset.TagExemptionRuleName},
+		{exempt: "none", want: "tls"},
+	} {
+		stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(tagged(tc.exempt)))
+		config, err := LoadConfig(DefaultConfigFile)
+		require.NoError(t, err)
+		res, err := Run(rs, config, wd)
+		stub.Reset()
+		require.NoError(t, err)
+		require.Len(t, res.Issues, 1)
+		assert.Equal(t, tc.want, res.Issues[0].Rule.Name(), "the tags of the last run must not be used")
+	}
+}
+
+// noticeRule is a rule of notices with the given name.
+type noticeRule struct {
+	tflint.DefaultRule
+	name string
+}
+
+func (r *noticeRule) Name() string                { return r.name }
+func (r *noticeRule) Enabled() bool               { return true }
+func (r *noticeRule) Severity() tflint.Severity   { return tflint.NOTICE }
+func (r *noticeRule) Check(_ tflint.Runner) error { return nil }
@@ -0,0 +1,117 @@
+package check
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruleset"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// PluginName is the name of the plugin block in `.tflint.hcl` that configures the ruleset.
+const PluginName = "azure-wellarchitected"
+
+// DefaultConfigFile is the config file that is loaded if it exists and no other file is given.
+const DefaultConfigFile = ".tflint.hcl"
+
+// Config is the subset of a tflint config file that applies to the ruleset.
+type Config struct {
+	Global    *tflint.Config          // The `config` block and the enabled state of each `rule` block.
+	Variables modulecontent.Variables // The `varfile` and `variables` attributes of the `config` block.
+	Rules     map[string]hcl.Body     // The body of each `rule` block, decoded by DecodeRuleConfig.
+	Plugin    hcl.Body                // The body of the plugin block, nil if there is none.
+}
+
+var configSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "config"},
+		{Type: "rule", LabelNames: []string{"name"}},
+		{Type: "plugin", LabelNames: []string{"name"}},
+	},
+}
+
+// LoadConfig reads the tflint config file from modulecontent.AppFs.
+// If the file is DefaultConfigFile and it does not exist, an empty config is returned.
+func LoadConfig(filename string) (*Config, error) {
+	config := &Config{
+		Global: &tflint.Config{Rules: map[string]*tflint.RuleConfig{}},
+		Rules:  map[string]hcl.Body{},
+	}
+	src, err := modulecontent.AppFs.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) && filename == DefaultConfigFile {
+			return config, nil
+		}
+		return nil, fmt.Errorf("could not read config: %s", err)
+	}
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	content, _, diags := file.Body.PartialContent(configSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "config":
+			if err := decodeGlobalConfig(block.Body, config.Global); err != nil {
+				return nil, err
+			}
+			vars, err := ruleset.ConfigVariables(block.Body)
+			if err != nil {
+				return nil, err
+			}
+			config.Variables = vars
+		case "rule":
+			enabled, err := boolAttribute(block.Body, "enabled", true)
+			if err != nil {
+				return nil, err
+			}
+			name := block.Labels[0]
+			config.Global.Rules[name] = &tflint.RuleConfig{Name: name, Enabled: enabled}
+			config.Rules[name] = block.Body
+		case "plugin":
+			if block.Labels[0] == PluginName {
+				config.Plugin = block.Body
+			}
+		}
+	}
+	return config, nil
+}
+
+// decodeGlobalConfig decodes the attributes of the `config` block that apply to the ruleset.
+func decodeGlobalConfig(body hcl.Body, global *tflint.Config) error {
+	disabledByDefault, err := boolAttribute(body, "disabled_by_default", false)
+	if err != nil {
+		return err
+	}
+	global.DisabledByDefault = disabledByDefault
+	return nil
+}
+
+// boolAttribute returns the value of the bool attribute in the body, or the default if it is not set.
+func boolAttribute(body hcl.Body, name string, def bool) (bool, error) {
+	content, _, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: name}},
+	})
+	if diags.HasErrors() {
+		return false, diags
+	}
+	attr, ok := content.Attributes[name]
+	if !ok {
+		return def, nil
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return false, diags
+	}
+	if val.IsNull() || !val.IsKnown() || val.Type() != cty.Bool {
+		return false, fmt.Errorf("%s: `%s` must be a bool", attr.Range, name)
+	}
+	return val.True(), nil
+}
@@ -0,0 +1,3 @@
+// Package check runs the ruleset against a Terraform configuration without tflint,
+// using the same evaluator as the plugin and the rule configuration in `.tflint.hcl`.
+package check
@@ -0,0 +1,87 @@
+package check
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruleset"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// otherPillar is the heading of issues from rules without Well-Architected metadata.
+const otherPillar = "Other"
+
+// ParseSeverity parses a severity name, e.g. `warning`, case insensitively.
+func ParseSeverity(s string) (tflint.Severity, error) {
+	for _, sev := range []tflint.Severity{tflint.ERROR, tflint.WARNING, tflint.NOTICE} {
+		if strings.EqualFold(s, sev.String()) {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid severity `%s`, valid severities are: error, warning, notice", s)
+}
+
+// AtLeast returns the issues with a severity of at least min.
+// Severities are ordered ERROR, WARNING, NOTICE, from the most to the least severe.
+func AtLeast(issues []Issue, min tflint.Severity) []Issue {
+	res := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Rule.Severity() <= min {
+			res = append(res, issue)
+		}
+	}
+	return res
+}
+
+// Failures returns the issues that fail the check, those with a severity of at least min,
+// except the notices of issues exempted by a tag, which only keep the exemptions visible.
+func Failures(issues []Issue, min tflint.Severity) []Issue {
+	res := make([]Issue, 0, len(issues))
+	for _, issue := range AtLeast(issues, min) {
+		if issue.Rule.Name() != ruleset.TagExemptionRuleName {
+			res = append(res, issue)
+		}
+	}
+	return res
+}
+
+// GroupByPillar returns the issues by the pillar of their rule, rules without metadata are grouped as `Other`.
+func GroupByPillar(issues []Issue) map[string][]Issue {
+	res := make(map[string][]Issue)
+	for _, issue := range issues {
+		pillar := otherPillar
+		if md := waf.FromRule(issue.Rule); md != nil && md.Pillar != "" {
+			pillar = string(md.Pillar)
+		}
+		res[pillar] = append(res[pillar], issue)
+	}
+	return res
+}
+
+// WriteText writes the issues grouped by pillar, in the order of waf.Pillars.
+func WriteText(w io.Writer, issues []Issue) error {
+	groups := GroupByPillar(issues)
+	headings := make([]string, 0, len(waf.Pillars)+1)
+	for _, p := range waf.Pillars {
+		headings = append(headings, string(p))
+	}
+	headings = append(headings, otherPillar)
+	for _, heading := range headings {
+		group := groups[heading]
+		if len(group) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s (%d)\n", heading, len(group)); err != nil {
+			return err
+		}
+		for _, issue := range group {
+			if _, err := fmt.Fprintf(w, "  %s: %s: %s (%s)\n", issue.Range, issue.Rule.Severity(), issue.Message, issue.Rule.Name()); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d issue(s) found\n", len(issues))
+	return err
+}
@@ -0,0 +1,134 @@
+package check
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/terraform/addrs"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// errNotSupported is returned by the runner methods that the rules in this ruleset do not use.
+var errNotSupported = errors.New("not supported by the standalone runner, the rule must use modulecontent")
+
+// Issue is an issue emitted by a rule.
+type Issue struct {
+	Rule    tflint.Rule
+	Message string
+	Range   hcl.Range
+}
+
+// Runner is a tflint.Runner for the rules in this ruleset, which read the configuration with modulecontent.
+// It provides the working directory, rule config and the RunConfig of the ruleset, and collects the emitted issues and the checks for scoring.
+// Fixes are not applied.
+type Runner struct {
+	wd        string
+	config    *Config
+	variables modulecontent.Variables
+	runConfig *rules.RunConfig
+	Issues    []Issue
+	Checks    []score.Check
+}
+
+var _ tflint.Runner = &Runner{}
+var _ score.Recorder = &Runner{}
+var _ modulecontent.VariablesRunner = &Runner{}
+var _ rules.RunConfigRunner = &Runner{}
+
+// NewRunner returns a runner for the configuration in the working directory, evaluated with the input values.
+func NewRunner(wd string, config *Config, vars modulecontent.Variables) *Runner {
+	return &Runner{
+		wd:        wd,
+		config:    config,
+		variables: vars,
+	}
+}
+
+func (r *Runner) GetOriginalwd() (string, error) {
+	return r.wd, nil
+}
+
+func (r *Runner) GetModulePath() (addrs.Module, error) {
+	return addrs.Module{}, nil
+}
+
+// DecodeRuleConfig decodes the `rule` block of the rule into ret, the `enabled` attribute is ignored.
+// If there is no `rule` block ret is left unchanged.
+func (r *Runner) DecodeRuleConfig(ruleName string, ret interface{}) error {
+	body, ok := r.config.Rules[ruleName]
+	if !ok {
+		return nil
+	}
+	content, diags := hclext.PartialContent(body, hclext.ImpliedBodySchema(ret))
+	if diags.HasErrors() {
+		return diags
+	}
+	if diags := hclext.DecodeBody(content, nil, ret); diags.HasErrors() {
+		return diags
+	}
+	return nil
+}
+
+func (r *Runner) EmitIssue(rule tflint.Rule, message string, issueRange hcl.Range) error {
+	r.Issues = append(r.Issues, Issue{Rule: rule, Message: message, Range: issueRange})
+	return nil
+}
+
+func (r *Runner) Variables() modulecontent.Variables {
+	return r.variables
+}
+
+func (r *Runner) RunConfig() *rules.RunConfig {
+	return r.runConfig
+}
+
+func (r *Runner) RecordCheck(c score.Check) {
+	r.Checks = append(r.Checks, c)
+}
+
+func (r *Runner) EmitIssueWithFix(rule tflint.Rule, message string, issueRange hcl.Range, _ func(f tflint.Fixer) error) error {
+	return r.EmitIssue(rule, message, issueRange)
+}
+
+func (r *Runner) EnsureNoError(err error, proc func() error) error {
+	if err != nil {
+		return err
+	}
+	return proc()
+}
+
+func (r *Runner) GetResourceContent(string, *hclext.BodySchema, *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return nil, fmt.Errorf("GetResourceContent is %w", errNotSupported)
+}
+
+func (r *Runner) GetProviderContent(string, *hclext.BodySchema, *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return nil, fmt.Errorf("GetProviderContent is %w", errNotSupported)
+}
+
+func (r *Runner) GetModuleContent(*hclext.BodySchema, *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return nil, fmt.Errorf("GetModuleContent is %w", errNotSupported)
+}
+
+func (r *Runner) GetFile(string) (*hcl.File, error) {
+	return nil, fmt.Errorf("GetFile is %w", errNotSupported)
+}
+
+func (r *Runner) GetFiles() (map[string]*hcl.File, error) {
+	return nil, fmt.Errorf("GetFiles is %w", errNotSupported)
+}
+
+func (r *Runner) WalkExpressions(tflint.ExprWalker) hcl.Diagnostics {
+	return hcl.Diagnostics{{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf("WalkExpressions is %s", errNotSupported),
+	}}
+}
+
+func (r *Runner) EvaluateExpr(hcl.Expression, interface{}, *tflint.EvaluateExprOption) error {
+	return fmt.Errorf("EvaluateExpr is %w", errNotSupported)
+}
@@ -0,0 +1,49 @@
+// Command azpolicyimport reports how the Azure Policy definitions of a directory translate to rules,
+// as configured with `azure_policies` in the plugin block, and which policies and aliases could not be translated.
+//
+//	go run ./cmd/azpolicyimport -in policies
+//
+// The exit status is 1 if the directory could not be read, and 2 if a policy was skipped.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/azpolicy"
+)
+
+func main() {
+	in := flag.String("in", "", "the directory of policy definition JSON files")
+	flag.Parse()
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	res, err := azpolicy.Import(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, rd := range res.Rules {
+		fmt.Printf("%s (%s, %s): %s\n", rd.Name, rd.ResourceType, rd.Severity, rd.DisplayName)
+		fmt.Printf("  %s\n", rd.Expression)
+	}
+	if len(res.Skipped) > 0 {
+		fmt.Println("\nSkipped:")
+		for _, s := range res.Skipped {
+			fmt.Printf("  %s (%s): %s\n", s.Policy, s.File, s.Reason)
+		}
+	}
+	if len(res.UnknownAliases) > 0 {
+		fmt.Println("\nUnknown aliases:")
+		for _, alias := range res.UnknownAliases {
+			fmt.Printf("  %s\n", alias)
+		}
+	}
+	if len(res.Skipped) > 0 {
+		os.Exit(2)
+	}
+}
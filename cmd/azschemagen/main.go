@@ -0,0 +1,41 @@
+// Command azschemagen builds the schema bundle embedded in the azschema package
+// from the `generated` directory of a bicep-types-az checkout.
+//
+//	go run ./cmd/azschemagen -in ../bicep-types-az/generated -out azschema/schemas.json.gz
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/azschema"
+)
+
+func main() {
+	in := flag.String("in", "", "the bicep-types-az `generated` directory")
+	out := flag.String("out", "schemas.json.gz", "the bundle to write")
+	include := flag.String("include", "", "comma separated resource types to include, all resource types if empty")
+	flag.Parse()
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var types []string
+	if *include != "" {
+		types = strings.Split(*include, ",")
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("could not create bundle: %s", err)
+	}
+	if err := azschema.WriteBundle(f, *in, types); err != nil {
+		_ = f.Close()
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("could not write bundle: %s", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Command wafcheck runs the Azure Well-Architected ruleset against a Terraform configuration without tflint,
+// e.g. in a pre-commit hook. It reads the rule and plugin configuration from `.tflint.hcl`.
+//
+//	wafcheck -chdir=infra -minimum-failure-severity=warning
+//	wafcheck -format=sarif > wafcheck.sarif
+//	wafcheck -score -format=json
+//	terraform show -json tfplan > tfplan.json && wafcheck -plan=tfplan.json
+//	wafcheck -update-baseline
+//
+// The exit status is 0 if there are no issues at or above the minimum failure severity,
+// 2 if there are, and 1 if the configuration could not be checked. The notices of tag exemptions do not fail the check.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/check"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/plan"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/report"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruleset"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+)
+
+// version is the version of the ruleset, set at build time.
+var version = "0.1.0"
+
+// projectURI is the home page of the ruleset, reported in SARIF output.
+const projectURI = "https://github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitected"
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	chdir := flag.String("chdir", "", "the directory of the Terraform configuration, defaults to the current directory")
+	configFile := flag.String("config", check.DefaultConfigFile, "the tflint config file, relative to the configuration directory")
+	minSeverity := flag.String("minimum-failure-severity", "notice", "the minimum severity of issues that fail the check: error, warning or notice")
+	format := flag.String("format", string(report.Text), "the output format: text, json, sarif or junit")
+	planFile := flag.String("plan", "", "check the azapi_resource instances in a `terraform show -json` plan file instead of the configuration, relative to the configuration directory")
+	scoreReport := flag.Bool("score", false, "print the Well-Architected score per pillar instead of the issues, as a text table or json")
+	updateBaseline := flag.Bool("update-baseline", false, "write the current issues to the baseline file of the plugin configuration, instead of printing them")
+	pruneBaseline := flag.Bool("prune-baseline", false, "remove the entries that no longer match an issue from the baseline file, instead of printing the issues")
+	verbose := flag.Bool("verbose", false, "log the progress of loading the configuration")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	min, err := check.ParseSeverity(*minSeverity)
+	if err != nil {
+		return fail(err)
+	}
+	f, err := report.ParseFormat(*format)
+	if err != nil {
+		return fail(err)
+	}
+	if *scoreReport && f != report.Text && f != report.JSON {
+		return fail(fmt.Errorf("the score can only be written as text or json"))
+	}
+	if (*updateBaseline || *pruneBaseline) && (*planFile != "" || *scoreReport || (*updateBaseline && *pruneBaseline)) {
+		return fail(fmt.Errorf("-update-baseline and -prune-baseline cannot be combined with each other, -plan or -score"))
+	}
+	if *chdir != "" {
+		if err := os.Chdir(*chdir); err != nil {
+			return fail(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return fail(err)
+	}
+	config, err := check.LoadConfig(*configFile)
+	if err != nil {
+		return fail(err)
+	}
+	rs := ruleset.New(check.PluginName, version, rules.Rules)
+	var res *check.Result
+	if *planFile != "" {
+		res, err = plan.Run(rs, config, wd, *planFile)
+	} else {
+		res, err = check.Run(rs, config, wd)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if *updateBaseline || *pruneBaseline {
+		if err := writeBaseline(rs, *pruneBaseline); err != nil {
+			return fail(err)
+		}
+		return 0
+	}
+	if *scoreReport {
+		err = writeScore(f, score.Compute(res.Checks, score.DefaultWeights))
+	} else {
+		tool := report.Tool{Name: "wafcheck", Version: version, URI: projectURI}
+		err = report.Write(os.Stdout, f, tool, rs.EnabledRules, res)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if len(check.Failures(res.Issues, min)) > 0 {
+		return 2
+	}
+	return 0
+}
+
+func writeScore(f report.Format, r score.Report) error {
+	if f == report.JSON {
+		return score.WriteJSON(os.Stdout, r)
+	}
+	return score.WriteTable(os.Stdout, r)
+}
+
+// writeBaseline replaces the baseline file with the issues of the run, or, if prune is set, with the entries that matched an issue.
+func writeBaseline(rs *ruleset.RuleSet, prune bool) error {
+	baseline := rs.UpdatedBaseline()
+	if prune {
+		baseline = rs.PrunedBaseline()
+	}
+	if baseline == nil {
+		return fmt.Errorf("no baseline file is set in the plugin block")
+	}
+	var buf bytes.Buffer
+	if err := baseline.Write(&buf); err != nil {
+		return err
+	}
+	if err := os.WriteFile(rs.BaselineFile(), buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("could not write baseline file: %s", err)
+	}
+	fmt.Fprintf(os.Stderr, "wafcheck: wrote %d entries to %s\n", len(baseline.Entries), rs.BaselineFile())
+	return nil
+}
+
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "wafcheck: %s\n", err)
+	return 1
+}
@@ -0,0 +1,3 @@
+// Package graph provides an index of the resources, data sources and module calls in a Terraform configuration,
+// and the relationships between them.
+package graph
@@ -0,0 +1,367 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/terraform-linters/tflint/terraform/addrs"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// resourceAttributes are the attributes fetched from `resource` and `data` blocks.
+// Only references in these attributes are recorded.
+var resourceAttributes = []string{
+	"type",
+	"name",
+	"parent_id",
+	"resource_id",
+	"location",
+	"body",
+	"tags",
+	"identity",
+	"schema_validation_enabled",
+	"count",
+	"for_each",
+	"depends_on",
+}
+
+// moduleAttributes are the meta-arguments fetched from `module` blocks, in addition to the child module's variables.
+var moduleAttributes = []string{
+	"source",
+	"version",
+	"count",
+	"for_each",
+	"depends_on",
+}
+
+// Graph is an index of the resources, data sources and module calls in every module instance of a configuration.
+type Graph struct {
+	nodes     []*Node
+	byAddress map[string][]*Node
+}
+
+// FromRunner loads the configuration using the runner's working directory and builds the graph.
+func FromRunner(runner tflint.Runner) (*Graph, hcl.Diagnostics) {
+	instances, diags := modulecontent.ModuleInstances(runner)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return Build(instances)
+}
+
+// Build builds the graph from the given module instances, see modulecontent.ModuleInstances.
+// Blocks expanded by `count` or `for_each` are added as one node per instance.
+func Build(instances []modulecontent.ModuleInstance) (*Graph, hcl.Diagnostics) {
+	g := &Graph{
+		byAddress: make(map[string][]*Node),
+	}
+	for _, mi := range instances {
+		for _, f := range moduleFetchers(mi.Config) {
+			blocks, blockInstances, diags := mi.FetchBlocks(f)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			for _, block := range blocks {
+				node, diags := newNode(mi, block, blockInstances[block])
+				if diags.HasErrors() {
+					return nil, diags
+				}
+				g.add(node)
+			}
+		}
+	}
+	return g, nil
+}
+
+// Nodes returns all nodes in the graph.
+func (g *Graph) Nodes() []*Node {
+	return g.nodes
+}
+
+// Get returns the nodes with the given address, there is more than one if the block has been expanded.
+// An address with an instance key, e.g. `azapi_resource.vnet["hub"]`, returns only that instance.
+func (g *Graph) Get(address string) []*Node {
+	return g.byAddress[address]
+}
+
+// OfType returns the nodes with the given type, e.g. `Microsoft.Storage/storageAccounts` or `azurerm_resource_group`.
+// The comparison is case insensitive.
+func (g *Graph) OfType(resourceType string) []*Node {
+	res := make([]*Node, 0)
+	for _, n := range g.nodes {
+		if strings.EqualFold(n.Type, resourceType) {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// ByResourceID returns the nodes that the given Azure resource ID refers to.
+// Nodes are matched by type and name as the resource group and subscription are not usually known.
+func (g *Graph) ByResourceID(id string) []*Node {
+	res := make([]*Node, 0)
+	for _, n := range g.nodes {
+		if matchesResourceID(n, id) {
+			res = append(res, n)
+		}
+	}
+	return res
+}
+
+// Children returns the nodes whose `parent_id` refers to the node with the given address,
+// either by a reference or by a resource ID that matches the node's type and name.
+func (g *Graph) Children(address string) []*Node {
+	parents := g.byAddress[address]
+	res := make([]*Node, 0)
+	for _, n := range g.nodes {
+		for _, p := range parents {
+			if isParent(p, n) {
+				res = append(res, n)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// Parents returns the nodes that the `parent_id` of the node refers to, as in Children.
+func (g *Graph) Parents(n *Node) []*Node {
+	res := make([]*Node, 0)
+	for _, p := range g.nodes {
+		if isParent(p, n) {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// ReferencedBy returns the nodes that reference the node with the given address, explicitly or implicitly.
+func (g *Graph) ReferencedBy(address string) []*Node {
+	targets := g.byAddress[address]
+	res := make([]*Node, 0)
+	for _, n := range g.nodes {
+		for _, t := range targets {
+			if n.ReferencesNode(t, "") {
+				res = append(res, n)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// OfTypeUnder returns the nodes of the given type that are descendants of the node with the given scope address,
+// following the `parent_id` relationships, e.g. all storage accounts under a resource group.
+func (g *Graph) OfTypeUnder(resourceType, scope string) []*Node {
+	res := make([]*Node, 0)
+	visited := map[*Node]bool{}
+	queue := []string{scope}
+	for len(queue) > 0 {
+		addr := queue[0]
+		queue = queue[1:]
+		for _, child := range g.Children(addr) {
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			if strings.EqualFold(child.Type, resourceType) {
+				res = append(res, child)
+			}
+			queue = append(queue, child.String())
+		}
+	}
+	return res
+}
+
+func (g *Graph) add(n *Node) {
+	g.nodes = append(g.nodes, n)
+	g.byAddress[n.Address] = append(g.byAddress[n.Address], n)
+	if addr := n.String(); addr != n.Address {
+		g.byAddress[addr] = append(g.byAddress[addr], n)
+	}
+}
+
+// fetcher fetches all blocks of a type from a module instance.
+type fetcher struct {
+	blockType  string
+	labelNames []string
+	attributes []string
+}
+
+var _ modulecontent.BlockFetcher = fetcher{}
+
+func (f fetcher) BlockType() string    { return f.blockType }
+func (f fetcher) LabelOne() string     { return "" }
+func (f fetcher) LabelNames() []string { return f.labelNames }
+func (f fetcher) Attributes() []string { return f.attributes }
+
+// moduleFetchers returns the fetchers of the `resource`, `data` and `module` blocks of the module.
+func moduleFetchers(config *terraform.Config) []fetcher {
+	moduleAttrs := append([]string{}, moduleAttributes...)
+	seen := make(map[string]bool)
+	for _, child := range config.Children {
+		for v := range child.Module.Variables {
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			moduleAttrs = append(moduleAttrs, v)
+		}
+	}
+	return []fetcher{
+		{blockType: "resource", labelNames: []string{"type", "name"}, attributes: resourceAttributes},
+		{blockType: "data", labelNames: []string{"type", "name"}, attributes: resourceAttributes},
+		{blockType: "module", labelNames: []string{"name"}, attributes: moduleAttrs},
+	}
+}
+
+// newNode evaluates the block of the instance into a Node.
+// Only the attributes of azapi blocks are evaluated, as the attributes of other providers have different meanings.
+func newNode(mi modulecontent.ModuleInstance, block *hclext.Block, inst modulecontent.Instance) (*Node, hcl.Diagnostics) {
+	n := &Node{
+		Module:    mi.Path,
+		CallRange: mi.CallRange,
+		Key:       inst.Key,
+		Block:     block,
+		Body:      cty.NilVal,
+		instance:  inst,
+	}
+	switch block.Type {
+	case "resource":
+		n.Kind = KindResource
+	case "data":
+		n.Kind = KindDataSource
+	case "module":
+		n.Kind = KindModuleCall
+	}
+	n.Address = modulePrefix(mi.Path) + blockAddress(block)
+	if n.Kind == KindModuleCall {
+		n.Type = "module"
+		n.Name = block.Labels[0]
+	} else {
+		n.Label = block.Labels[0]
+		n.Type = block.Labels[0]
+		n.Name = block.Labels[1]
+	}
+
+	if strings.HasPrefix(n.Label, "azapi_") {
+		if s, ok, diags := evaluateString(mi.Ctx, block, "type"); diags.HasErrors() {
+			return nil, diags
+		} else if ok {
+			if t, v, found := strings.Cut(s, "@"); found {
+				n.Type, n.ApiVersion = t, v
+			}
+		}
+		if s, ok, diags := evaluateString(mi.Ctx, block, "name"); diags.HasErrors() {
+			return nil, diags
+		} else if ok {
+			n.Name = s
+		}
+		if s, ok, diags := evaluateString(mi.Ctx, block, "parent_id"); diags.HasErrors() {
+			return nil, diags
+		} else if ok {
+			n.ParentID = s
+		}
+		if attr, exists := block.Body.Attributes["body"]; exists {
+			val, diags := mi.Ctx.EvaluateExpr(attr.Expr, cty.DynamicPseudoType)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			n.Body = val
+		}
+	}
+
+	for name, attr := range block.Body.Attributes {
+		if name == "depends_on" {
+			n.References = append(n.References, explicitReferences(mi.Path, inst, attr)...)
+			continue
+		}
+		for _, addr := range inst.References(attr.Expr) {
+			addr = modulePrefix(mi.Path) + addr
+			if n.ReferencesAddress(addr, name) {
+				continue
+			}
+			n.References = append(n.References, Reference{Address: addr, Attribute: name})
+		}
+	}
+	return n, nil
+}
+
+// evaluateString evaluates the attribute as a string, returning false if it does not exist or is not known.
+func evaluateString(ctx *terraform.Evaluator, block *hclext.Block, name string) (string, bool, hcl.Diagnostics) {
+	attr, exists := block.Body.Attributes[name]
+	if !exists {
+		return "", false, nil
+	}
+	val, diags := ctx.EvaluateExpr(attr.Expr, cty.String)
+	if diags.HasErrors() {
+		return "", false, diags
+	}
+	if !val.IsWhollyKnown() || val.IsNull() {
+		return "", false, nil
+	}
+	val, _ = val.Unmark()
+	return val.AsString(), true, nil
+}
+
+// explicitReferences returns the references in a `depends_on` attribute of a block of the instance.
+func explicitReferences(path addrs.ModuleInstance, inst modulecontent.Instance, attr *hclext.Attribute) []Reference {
+	exprs, diags := hcl.ExprList(attr.Expr)
+	if diags.HasErrors() {
+		return nil
+	}
+	res := make([]Reference, 0, len(exprs))
+	for _, expr := range exprs {
+		for _, addr := range inst.References(expr) {
+			res = append(res, Reference{
+				Address:   modulePrefix(path) + addr,
+				Attribute: attr.Name,
+				Explicit:  true,
+			})
+		}
+	}
+	return res
+}
+
+// blockAddress returns the address of the block within its module.
+func blockAddress(block *hclext.Block) string {
+	switch block.Type {
+	case "data":
+		return "data." + strings.Join(block.Labels, ".")
+	case "module":
+		return "module." + block.Labels[0]
+	}
+	return strings.Join(block.Labels, ".")
+}
+
+// modulePrefix returns the prefix for addresses in the given module instance, e.g. `module.child.`.
+func modulePrefix(path addrs.ModuleInstance) string {
+	if path.IsRoot() {
+		return ""
+	}
+	return path.String() + "."
+}
+
+// isParent reports whether the `parent_id` of the node refers to the parent, by a reference or by resource ID.
+func isParent(parent, n *Node) bool {
+	if n.Kind == KindModuleCall || parent == n {
+		return false
+	}
+	if n.ReferencesNode(parent, "parent_id") {
+		return true
+	}
+	return n.ParentID != "" && matchesResourceID(parent, n.ParentID)
+}
+
+// matchesResourceID reports whether the Azure resource ID refers to the node, by type and name.
+func matchesResourceID(n *Node, id string) bool {
+	resourceType, name, ok := modulecontent.ParseResourceID(id)
+	if !ok {
+		return false
+	}
+	return strings.EqualFold(n.Type, resourceType) && strings.EqualFold(n.Name, name)
+}
@@ -0,0 +1,221 @@
+package graph
+
+import (
+	"os"
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/prashantv/gostub"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+)
+
+const testConfig = `
+resource "azapi_resource" "rg" {
+  type      = "Microsoft.Resources/resourceGroups@2021-04-01"
+  name      = "rg"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000"
+  body      = {}
+}
+
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name      = "sa"
+  parent_id = azapi_resource.rg.id
+  body = {
+    kind = "StorageV2"
+  }
+}
+
+resource "azapi_resource" "container" {
+  type      = "Microsoft.Storage/storageAccounts/blobServices/containers@2023-01-01"
+  name      = "container"
+  parent_id = "${azapi_resource.sa.id}/blobServices/default"
+  body      = {}
+}
+
+resource "azapi_resource" "kv" {
+  type      = "Microsoft.KeyVault/vaults@2023-02-01"
+  name      = "kv"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  body      = {}
+  depends_on = [module.child]
+}
+
+data "azapi_resource" "existing" {
+  type      = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name      = "vnet"
+  parent_id = azapi_resource.rg.id
+}
+
+module "child" {
+  source    = "./child"
+  name      = "childsa"
+  parent_id = azapi_resource.sa.id
+}
+`
+
+const testChildConfig = `
+variable "name" {
+  type = string
+}
+
+variable "parent_id" {
+  type = string
+}
+
+resource "azapi_resource" "child" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = var.name
+  body = {}
+}
+`
+
+func testGraph(t *testing.T) *Graph {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "main.tf", []byte(testConfig), os.ModePerm)
+	_ = afero.WriteFile(fs, "child/main.tf", []byte(testChildConfig), os.ModePerm)
+	stub := gostub.Stub(&modulecontent.AppFs, afero.Afero{Fs: fs})
+	defer stub.Reset()
+	runner := helper.TestRunner(t, map[string]string{"main.tf": testConfig})
+	g, diags := FromRunner(runner)
+	require.False(t, diags.HasErrors(), diags.Error())
+	return g
+}
+
+func addresses(nodes []*Node) []string {
+	res := make([]string, len(nodes))
+	for i, n := range nodes {
+		res[i] = n.String()
+	}
+	return res
+}
+
+func TestGraphNodes(t *testing.T) {
+	g := testGraph(t)
+	assert.ElementsMatch(t, []string{
+		"azapi_resource.rg",
+		"azapi_resource.sa",
+		"azapi_resource.container",
+		"azapi_resource.kv",
+		"data.azapi_resource.existing",
+		"module.child",
+		"module.child.azapi_resource.child",
+	}, addresses(g.Nodes()))
+
+	sa := g.Get("azapi_resource.sa")
+	require.Len(t, sa, 1)
+	assert.Equal(t, KindResource, sa[0].Kind)
+	assert.Equal(t, "Microsoft.Storage/storageAccounts", sa[0].Type)
+	assert.Equal(t, "2023-01-01", sa[0].ApiVersion)
+	kind, err := sa[0].Query("kind")
+	require.NoError(t, err)
+	assert.Equal(t, "StorageV2", kind.String())
+
+	existing := g.Get("data.azapi_resource.existing")
+	require.Len(t, existing, 1)
+	assert.Equal(t, KindDataSource, existing[0].Kind)
+
+	child := g.Get("module.child.azapi_resource.child")
+	require.Len(t, child, 1)
+	assert.Equal(t, "childsa", child[0].Name)
+}
+
+func TestGraphChildren(t *testing.T) {
+	g := testGraph(t)
+	assert.ElementsMatch(t, []string{
+		"azapi_resource.sa",
+		"azapi_resource.kv",
+		"data.azapi_resource.existing",
+	}, addresses(g.Children("azapi_resource.rg")))
+}
+
+func TestGraphReferencedBy(t *testing.T) {
+	g := testGraph(t)
+	assert.ElementsMatch(t, []string{
+		"azapi_resource.container",
+		"module.child",
+	}, addresses(g.ReferencedBy("azapi_resource.sa")))
+	assert.ElementsMatch(t, []string{
+		"azapi_resource.kv",
+	}, addresses(g.ReferencedBy("module.child")))
+}
+
+func TestGraphOfTypeUnder(t *testing.T) {
+	g := testGraph(t)
+	assert.ElementsMatch(t, []string{
+		"azapi_resource.container",
+	}, addresses(g.OfTypeUnder("Microsoft.Storage/storageAccounts/blobServices/containers", "azapi_resource.rg")))
+	assert.Empty(t, g.OfTypeUnder("Microsoft.Storage/storageAccounts", "azapi_resource.kv"))
+}
+
+func TestGraphInstances(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	_ = afero.WriteFile(fs, "main.tf", []byte(`
+resource "azapi_resource" "vnet" {
+  for_each = toset(["hub", "spoke"])
+  type     = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name     = each.key
+}
+
+resource "azapi_resource" "subnet" {
+  for_each  = { hub = "a", spoke = "b" }
+  type      = "Microsoft.Network/virtualNetworks/subnets@2023-04-01"
+  name      = each.value
+  parent_id = azapi_resource.vnet[each.key].id
+}
+
+resource "azapi_resource" "counted" {
+  count     = 2
+  type      = "Microsoft.Network/virtualNetworks/subnets@2023-04-01"
+  name      = "counted"
+  parent_id = count.index == 0 ? azapi_resource.vnet["hub"].id : values(azapi_resource.vnet)[0].id
+}
+
+module "child" {
+  source   = "./child"
+  for_each = toset(["x"])
+}
+`), os.ModePerm)
+	_ = afero.WriteFile(fs, "child/main.tf", []byte(`
+resource "azapi_resource" "single" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "single"
+}
+`), os.ModePerm)
+	stub := gostub.Stub(&modulecontent.AppFs, afero.Afero{Fs: fs})
+	defer stub.Reset()
+	g, diags := FromRunner(helper.TestRunner(t, map[string]string{}))
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	got := make(map[string][]string)
+	for _, n := range g.OfType("Microsoft.Network/virtualNetworks/subnets") {
+		for _, ref := range n.References {
+			got[n.String()] = append(got[n.String()], ref.Address)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		`azapi_resource.subnet["hub"]`:   {`azapi_resource.vnet["hub"]`},
+		`azapi_resource.subnet["spoke"]`: {`azapi_resource.vnet["spoke"]`},
+		`azapi_resource.counted[0]`:      {`azapi_resource.vnet["hub"]`, `azapi_resource.vnet`},
+		`azapi_resource.counted[1]`:      {`azapi_resource.vnet["hub"]`, `azapi_resource.vnet`},
+	}, got)
+	assert.Equal(t, []string{`module.child["x"].azapi_resource.single`}, addresses(g.Get(`module.child["x"].azapi_resource.single`)))
+
+	assert.ElementsMatch(t, []string{
+		`azapi_resource.subnet["hub"]`,
+		`azapi_resource.counted[0]`,
+		`azapi_resource.counted[1]`,
+	}, addresses(g.Children(`azapi_resource.vnet["hub"]`)))
+	assert.ElementsMatch(t, []string{
+		`azapi_resource.subnet["spoke"]`,
+		`azapi_resource.counted[0]`,
+		`azapi_resource.counted[1]`,
+	}, addresses(g.Children(`azapi_resource.vnet["spoke"]`)), "a reference to the resource matches all of its instances")
+	assert.ElementsMatch(t, []string{
+		`azapi_resource.vnet["hub"]`,
+		`azapi_resource.vnet["spoke"]`,
+	}, addresses(g.Parents(g.Get(`azapi_resource.counted[1]`)[0])))
+}
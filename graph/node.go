@@ -0,0 +1,122 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint/terraform/addrs"
+	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Kind is the kind of block a Node was decoded from.
+type Kind int
+
+const (
+	KindResource   Kind = iota // A `resource` block.
+	KindDataSource             // A `data` block.
+	KindModuleCall             // A `module` block.
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindResource:
+		return "resource"
+	case KindDataSource:
+		return "data"
+	case KindModuleCall:
+		return "module"
+	}
+	return "unknown"
+}
+
+// Reference is a reference from a node to another block in the same module.
+type Reference struct {
+	Address   string // The address of the referenced block, including the module path, and the instance key if it is known, e.g. `azapi_resource.vnet["hub"]` or `module.child`.
+	Attribute string // The attribute that contains the reference, e.g. `parent_id`.
+	Explicit  bool   // True if the reference is declared in `depends_on`.
+}
+
+// Node is an instance of a resource, data source or module call in the configuration.
+type Node struct {
+	Kind       Kind
+	Module     addrs.ModuleInstance // The module instance that contains the block, empty for the root module.
+	CallRange  hcl.Range            // The range of the module call in the root module, the zero value for the root module.
+	Address    string               // The address of the block, including the module path, e.g. `module.child.azapi_resource.example`.
+	Key        addrs.InstanceKey    // The instance key if the block has been expanded by `count` or `for_each`, otherwise addrs.NoKey.
+	Label      string               // The first label of the block, e.g. `azapi_resource`. Empty for module calls.
+	Type       string               // The evaluated type, e.g. `Microsoft.Storage/storageAccounts` for azapi resources, otherwise the label.
+	ApiVersion string               // The API version for azapi resources, otherwise empty.
+	Name       string               // The evaluated `name` attribute of azapi resources, otherwise or if it is not known the block name.
+	ParentID   string               // The evaluated `parent_id` attribute of azapi resources, empty if unknown.
+	Body       cty.Value            // The evaluated `body` attribute of azapi resources, cty.NilVal if the attribute does not exist.
+	Block      *hclext.Block        // The block the node was decoded from.
+	References []Reference          // The references to other blocks in the same module.
+	instance   modulecontent.Instance
+}
+
+// Query runs the gjson query against the body of the node.
+// Unknown values in the body are treated as null.
+func (n *Node) Query(query string) (gjson.Result, error) {
+	if n.Body == cty.NilVal {
+		return gjson.Result{}, nil
+	}
+	return blockquery.Query(blockquery.UnknownAsNull(n.Body), cty.DynamicPseudoType, query)
+}
+
+// IsAzApi reports whether the node is an azapi resource or data source with a known `type`.
+func (n *Node) IsAzApi() bool {
+	return strings.HasPrefix(n.Label, "azapi_") && n.ApiVersion != ""
+}
+
+// ReferencesAddress reports whether the node references the block with the given address.
+// If attribute is not empty, only references from that attribute are considered.
+func (n *Node) ReferencesAddress(address, attribute string) bool {
+	for _, ref := range n.References {
+		if ref.Address != address {
+			continue
+		}
+		if attribute == "" || ref.Attribute == attribute {
+			return true
+		}
+	}
+	return false
+}
+
+// ReferencesNode reports whether the node references the target node.
+// A reference to an instance only matches that instance, e.g. `azapi_resource.vnet[1]`,
+// a reference to the block matches all of its instances, e.g. `values(azapi_resource.vnet)[0].id`.
+// If attribute is not empty, only references from that attribute are considered.
+func (n *Node) ReferencesNode(target *Node, attribute string) bool {
+	return n.ReferencesAddress(target.Address, attribute) || n.ReferencesAddress(target.String(), attribute)
+}
+
+// ExprReferencesNode reports whether the expression of the node's block, e.g. a part of its `body`, references the target node.
+// References are matched as in ReferencesNode.
+func (n *Node) ExprReferencesNode(expr hcl.Expression, target *Node) bool {
+	for _, ref := range n.instance.References(expr) {
+		ref = modulePrefix(n.Module) + ref
+		if ref == target.Address || ref == target.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueRange returns the range to use when emitting an issue for the node.
+// Issues for blocks in child modules are emitted on the module call in the root module.
+func (n *Node) IssueRange() hcl.Range {
+	if n.CallRange != (hcl.Range{}) {
+		return n.CallRange
+	}
+	return n.Block.DefRange
+}
+
+// String returns the address of the node including the instance key, if the block has been expanded,
+// e.g. `azapi_resource.vnet[0]` or `azapi_resource.vnet["hub"]`.
+func (n *Node) String() string {
+	return n.instance.Address(n.Address)
+}
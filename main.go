@@ -1,22 +1,13 @@
 package main
 
 import (
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruleset"
 	"github.com/terraform-linters/tflint-plugin-sdk/plugin"
-	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
-	"github.com/terraform-linters/tflint-ruleset-template/rules"
 )
 
 func main() {
 	plugin.Serve(&plugin.ServeOpts{
-		RuleSet: &tflint.BuiltinRuleSet{
-			Name:    "template",
-			Version: "0.1.0",
-			Rules: []tflint.Rule{
-				rules.NewAwsInstanceExampleTypeRule(),
-				rules.NewAwsS3BucketExampleLifecycleRule(),
-				rules.NewGoogleComputeSSLPolicyRule(),
-				rules.NewTerraformBackendTypeRule(),
-			},
-		},
+		RuleSet: ruleset.New("azure-wellarchitected", "0.1.0", rules.Rules),
 	})
 }
@@ -0,0 +1,83 @@
+package modulecontent
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Declarations are the declarations of the root module that expressions in its blocks can be traced back to.
+type Declarations struct {
+	Variables map[string]*Variable      // The `variable` blocks by name.
+	Locals    map[string]hcl.Expression // The expression of each local value by name.
+}
+
+// Variable is a `variable` block of the root module.
+type Variable struct {
+	Name         string
+	Default      cty.Value        // The default value converted to the variable's type, cty.NilVal if there is none.
+	DefaultRange hcl.Range        // The range of the `default` attribute, or the block if there is none.
+	DeclRange    hcl.Range        // The range of the block header.
+	Validations  []hcl.Expression // The `condition` of each `validation` block.
+}
+
+var variableSchema = &hclext.BodySchema{
+	Blocks: []hclext.BlockSchema{
+		{
+			Type:       "variable",
+			LabelNames: []string{"name"},
+			Body: &hclext.BodySchema{
+				Attributes: []hclext.AttributeSchema{{Name: "default"}},
+				Blocks: []hclext.BlockSchema{
+					{
+						Type: "validation",
+						Body: &hclext.BodySchema{
+							Attributes: []hclext.AttributeSchema{{Name: "condition"}},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// FetchDeclarations returns the variables and local values of the root module.
+func FetchDeclarations(runner tflint.Runner) (*Declarations, hcl.Diagnostics) {
+	config, _, diags := initEvaluator(runner)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	content, diags := config.Module.PartialContent(variableSchema, nil)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	decls := &Declarations{
+		Variables: make(map[string]*Variable, len(content.Blocks)),
+		Locals:    make(map[string]hcl.Expression, len(config.Module.Locals)),
+	}
+	for _, block := range content.Blocks {
+		v := &Variable{
+			Name:         block.Labels[0],
+			Default:      cty.NilVal,
+			DefaultRange: block.DefRange,
+			DeclRange:    block.DefRange,
+		}
+		if decl, ok := config.Module.Variables[v.Name]; ok {
+			v.Default = decl.Default
+		}
+		if attr, ok := block.Body.Attributes["default"]; ok {
+			v.DefaultRange = attr.Range
+		}
+		for _, validation := range block.Body.Blocks {
+			if attr, ok := validation.Body.Attributes["condition"]; ok {
+				v.Validations = append(v.Validations, attr.Expr)
+			}
+		}
+		decls.Variables[v.Name] = v
+	}
+	for name, local := range config.Module.Locals {
+		decls.Locals[name] = local.Expr
+	}
+	return decls, nil
+}
@@ -0,0 +1,49 @@
+package modulecontent
+
+import (
+	"testing"
+
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestFetchDeclarations(t *testing.T) {
+	stub := gostub.Stub(&AppFs, mockFs(map[string]string{"main.tf": `
+variable "tls" {
+  type    = string
+  default = "TLS1_0"
+
+  validation {
+    condition     = contains(["TLS1_2"], var.tls)
+    error_message = "TLS1_2 is required."
+  }
+}
+
+variable "sku" {
+  type = string
+}
+
+locals {
+  properties = { minimumTlsVersion = var.tls }
+}
+`}))
+	defer stub.Reset()
+	decls, diags := FetchDeclarations(new(MockRunner))
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	require.Contains(t, decls.Variables, "tls")
+	tls := decls.Variables["tls"]
+	assert.True(t, cty.StringVal("TLS1_0").RawEquals(tls.Default))
+	assert.Equal(t, 4, tls.DefaultRange.Start.Line)
+	assert.Len(t, tls.Validations, 1)
+
+	require.Contains(t, decls.Variables, "sku")
+	sku := decls.Variables["sku"]
+	assert.Equal(t, cty.NilVal, sku.Default)
+	assert.Equal(t, sku.DeclRange, sku.DefaultRange)
+	assert.Empty(t, sku.Validations)
+
+	assert.Contains(t, decls.Locals, "properties")
+}
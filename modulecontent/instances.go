@@ -0,0 +1,197 @@
+package modulecontent
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/terraform-linters/tflint/terraform/addrs"
+	"github.com/terraform-linters/tflint/terraform/lang"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Instance is the instance of a block expanded by `count` or `for_each`.
+type Instance struct {
+	Key   addrs.InstanceKey // The index for `count`, the key for `for_each`, or addrs.NoKey if the block is not expanded.
+	Value cty.Value         // The value of `each.value` for `for_each`, otherwise cty.NilVal.
+}
+
+// Address returns the address of the instance of the block with the given address, e.g. `azapi_resource.vnet["hub"]` for `azapi_resource.vnet`.
+func (i Instance) Address(addr string) string {
+	if i.Key == addrs.NoKey {
+		return addr
+	}
+	return addr + i.Key.String()
+}
+
+// blockInstances returns the instance of each of the expanded blocks, by evaluating the `count` and `for_each` arguments of the module's blocks.
+// The blocks must be in the order in which they were expanded.
+// If the argument cannot be evaluated the instances are numbered in order.
+func blockInstances(ctx *terraform.Evaluator, module *terraform.Module, schema hclext.BlockSchema, blocks []*hclext.Block) (map[*hclext.Block]Instance, hcl.Diagnostics) {
+	raw, diags := module.PartialContent(&hclext.BodySchema{
+		Blocks: []hclext.BlockSchema{
+			{
+				Type:       schema.Type,
+				LabelNames: schema.LabelNames,
+				Body: &hclext.BodySchema{
+					Attributes: []hclext.AttributeSchema{{Name: "count"}, {Name: "for_each"}},
+				},
+			},
+		},
+	}, nil)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	expanded := make(map[string]bool, len(raw.Blocks))
+	instances := make(map[string][]Instance, len(raw.Blocks))
+	for _, block := range raw.Blocks {
+		addr := strings.Join(block.Labels, ".")
+		_, count := block.Body.Attributes["count"]
+		_, forEach := block.Body.Attributes["for_each"]
+		expanded[addr] = count || forEach
+		instances[addr] = metaArgInstances(ctx, block)
+	}
+	res := make(map[*hclext.Block]Instance, len(blocks))
+	seen := make(map[string]int, len(blocks))
+	for _, block := range blocks {
+		addr := strings.Join(block.Labels, ".")
+		n := seen[addr]
+		seen[addr]++
+		switch {
+		case !expanded[addr]:
+			res[block] = Instance{Key: addrs.NoKey}
+		case n < len(instances[addr]):
+			res[block] = instances[addr][n]
+		default:
+			res[block] = Instance{Key: addrs.IntKey(n)}
+		}
+	}
+	return res, nil
+}
+
+// metaArgInstances returns the instances of the block in the order in which they are expanded, nil if it has no known `count` or `for_each`.
+func metaArgInstances(ctx *terraform.Evaluator, block *hclext.Block) []Instance {
+	if attr, ok := block.Body.Attributes["count"]; ok {
+		val, diags := ctx.EvaluateExpr(attr.Expr, cty.Number)
+		if diags.HasErrors() || !val.IsKnown() || val.IsNull() {
+			return nil
+		}
+		n, _ := val.AsBigFloat().Int64()
+		res := make([]Instance, 0, n)
+		for i := 0; i < int(n); i++ {
+			res = append(res, Instance{Key: addrs.IntKey(i)})
+		}
+		return res
+	}
+	attr, ok := block.Body.Attributes["for_each"]
+	if !ok {
+		return nil
+	}
+	val, diags := ctx.EvaluateExpr(attr.Expr, cty.DynamicPseudoType)
+	if diags.HasErrors() || !val.IsKnown() || val.IsNull() || !val.CanIterateElements() {
+		return nil
+	}
+	val, _ = val.UnmarkDeep()
+	res := make([]Instance, 0, val.LengthInt())
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		if val.Type().IsSetType() {
+			k = v
+		}
+		if !k.IsKnown() || k.IsNull() || k.Type() != cty.String {
+			return nil
+		}
+		res = append(res, Instance{Key: addrs.StringKey(k.AsString()), Value: v})
+	}
+	return res
+}
+
+// References returns the addresses of the resources and module calls referenced in the expression of a block of the instance.
+// A reference to a resource instance includes its key, e.g. `azapi_resource.vnet["a"]`,
+// also if the key is an expression of `count.index`, `each.key` or `each.value`, e.g. `azapi_resource.vnet[each.key].id`.
+// Other references are to the resource or module call, e.g. `azapi_resource.vnet` or `module.child`.
+func (i Instance) References(expr hcl.Expression) []string {
+	resolved := make(map[hcl.Range]addrs.InstanceKey)
+	if syntaxExpr, ok := hcl.UnwrapExpression(expr).(hclsyntax.Expression); ok {
+		_ = hclsyntax.VisitAll(syntaxExpr, func(node hclsyntax.Node) hcl.Diagnostics {
+			ie, ok := node.(*hclsyntax.IndexExpr)
+			if !ok {
+				return nil
+			}
+			coll, ok := ie.Collection.(*hclsyntax.ScopeTraversalExpr)
+			if !ok {
+				return nil
+			}
+			if key, ok := i.evaluateKey(ie.Key); ok {
+				resolved[coll.SrcRange] = key
+			}
+			return nil
+		})
+	}
+	refs, _ := lang.ReferencesInExpr(expr)
+	res := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		addr := referenceAddress(ref)
+		if addr == "" {
+			continue
+		}
+		if key, ok := resolved[ref.SourceRange]; ok {
+			if _, isResource := ref.Subject.(addrs.Resource); isResource {
+				addr += key.String()
+			}
+		}
+		res = append(res, addr)
+	}
+	return res
+}
+
+// evaluateKey evaluates an instance key expression that can only refer to `count.index`, `each.key` and `each.value`.
+func (i Instance) evaluateKey(expr hcl.Expression) (addrs.InstanceKey, bool) {
+	vars := map[string]cty.Value{}
+	switch k := i.Key.(type) {
+	case addrs.IntKey:
+		vars["count"] = cty.ObjectVal(map[string]cty.Value{"index": cty.NumberIntVal(int64(k))})
+	case addrs.StringKey:
+		value := i.Value
+		if value == cty.NilVal {
+			value = cty.DynamicVal
+		}
+		vars["each"] = cty.ObjectVal(map[string]cty.Value{"key": cty.StringVal(string(k)), "value": value})
+	}
+	val, diags := expr.Value(&hcl.EvalContext{Variables: vars})
+	if diags.HasErrors() || !val.IsWhollyKnown() || val.IsNull() {
+		return nil, false
+	}
+	val, _ = val.UnmarkDeep()
+	switch val.Type() {
+	case cty.String:
+		return addrs.StringKey(val.AsString()), true
+	case cty.Number:
+		n, acc := val.AsBigFloat().Int64()
+		if acc != 0 {
+			return nil, false
+		}
+		return addrs.IntKey(int(n)), true
+	}
+	return nil, false
+}
+
+// referenceAddress returns the address of the resource, resource instance or module call referenced,
+// or an empty string if the reference is not to a block.
+func referenceAddress(ref *addrs.Reference) string {
+	switch subj := ref.Subject.(type) {
+	case addrs.Resource:
+		return subj.String()
+	case addrs.ResourceInstance:
+		return subj.String()
+	case addrs.ModuleCall:
+		return subj.String()
+	case addrs.ModuleCallInstance:
+		return subj.Call.String()
+	case addrs.ModuleCallInstanceOutput:
+		return subj.Call.Call.String()
+	}
+	return ""
+}
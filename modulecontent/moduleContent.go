@@ -1,6 +1,8 @@
 package modulecontent
 
 import (
+	"sort"
+
 	"github.com/hashicorp/hcl/v2"
 	"github.com/spf13/afero"
 	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
@@ -20,7 +22,7 @@ var AppFs = afero.Afero{
 // retrieve the resources and attributes of a given resource type.
 type BlockFetcher interface {
 	BlockType() string    // The type of block to fetch, e.g. `resource`.
-	LabelOne() string     // The value of the first label of the block to fetch, e.g. `azapi_resource`.
+	LabelOne() string     // The value of the first label of the block to fetch, e.g. `azapi_resource`, or empty to fetch all blocks of the type. See also BlockSelector.
 	LabelNames() []string // The labels of the block to fetch, e.g. `["type", "name"]` for Terraform resources.
 	Attributes() []string // The attributes to fetch from the block.
 }
@@ -41,38 +43,86 @@ func FetchBlocks(f BlockFetcher, runner tflint.Runner) (*terraform.Evaluator, []
 	if diags.HasErrors() {
 		return nil, nil, diags
 	}
-	blocks, diags := blocksFilterByLabelOne(ctx, config.Module, f)
+	blocks, _, diags := selectBlocks(ctx, config.Module, f)
 
 	return ctx, blocks, diags
 }
 
-// blocksFilterByLabelOne returns a slice of resources with the given resource type and the attribute if they exist.
-func blocksFilterByLabelOne(ctx *terraform.Evaluator, module *terraform.Module, bf BlockFetcher) ([]*hclext.Block, hcl.Diagnostics) {
-	resources, diags := blocksWithPartialContent(ctx, module, bf)
+// FetchBlockInstances is like FetchBlocks, and also returns the instance of each block, e.g. to address the instances of a resource.
+func FetchBlockInstances(f BlockFetcher, runner tflint.Runner) (*terraform.Evaluator, []*hclext.Block, map[*hclext.Block]Instance, hcl.Diagnostics) {
+	config, ctx, diags := initEvaluator(runner)
+	if diags.HasErrors() {
+		return nil, nil, nil, diags
+	}
+	blocks, instances, diags := selectBlocks(ctx, config.Module, f)
+	return ctx, blocks, instances, diags
+}
+
+// FetchFiles returns the parsed files of the root module by name, as in the ranges of the fetched blocks.
+// The input values are not needed, so they are not loaded.
+func FetchFiles(runner tflint.Runner) (map[string]*hcl.File, hcl.Diagnostics) {
+	_, config, diags := loadConfig(runner)
 	if diags.HasErrors() {
 		return nil, diags
 	}
+	return config.Module.Files, nil
+}
+
+// selectBlocks returns the blocks of the fetcher's type whose first label is LabelOne, if it is set,
+// and that are selected by the fetcher's predicate if it is a BlockSelector, ordered by their position,
+// together with the instance of each block.
+func selectBlocks(ctx *terraform.Evaluator, module *terraform.Module, bf BlockFetcher) ([]*hclext.Block, map[*hclext.Block]Instance, hcl.Diagnostics) {
+	resources, diags := blocksWithPartialContent(ctx, module, bf)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+	instances, diags := blockInstances(ctx, module, hclext.BlockSchema{Type: bf.BlockType(), LabelNames: bf.LabelNames()}, resources.Blocks)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+	var pred Predicate
+	if bs, ok := bf.(BlockSelector); ok {
+		pred = bs.Selector()
+	}
 	filteredResources := make([]*hclext.Block, 0, len(resources.Blocks))
 	for _, resource := range resources.Blocks {
-		if resource.Labels[0] != bf.LabelOne() {
+		if bf.LabelOne() != "" && resource.Labels[0] != bf.LabelOne() {
 			continue
 		}
+		if pred != nil {
+			ok, err := pred(ctx, resource)
+			if err != nil {
+				return nil, nil, hcl.Diagnostics{{
+					Severity: hcl.DiagError,
+					Summary:  err.Error(),
+					Subject:  resource.DefRange.Ptr(),
+				}}
+			}
+			if !ok {
+				continue
+			}
+		}
 		filteredResources = append(filteredResources, resource)
 	}
-	return filteredResources, nil
+	// The module's files are held in a map, so order the blocks by their position for stable results.
+	sort.SliceStable(filteredResources, func(i, j int) bool {
+		a, b := filteredResources[i].DefRange, filteredResources[j].DefRange
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Start.Byte < b.Start.Byte
+	})
+	return filteredResources, instances, nil
 }
 
-// getAttributes returns a slice of attributes with the given attribute name from the resources of the given resource type.
+// getAttributes returns a slice of attributes with the given attribute name from the blocks selected by the fetcher.
 func getAttributes(ctx *terraform.Evaluator, module *terraform.Module, bf BlockFetcher) ([]*hclext.Attribute, hcl.Diagnostics) {
-	resources, diags := blocksWithPartialContent(ctx, module, bf)
+	resources, _, diags := selectBlocks(ctx, module, bf)
 	if diags.HasErrors() {
 		return nil, diags
 	}
-	attrs := make([]*hclext.Attribute, 0, len(resources.Blocks))
-	for _, resource := range resources.Blocks {
-		if resource.Labels[0] != bf.LabelOne() {
-			continue
-		}
+	attrs := make([]*hclext.Attribute, 0, len(resources))
+	for _, resource := range resources {
 		for _, attribute := range bf.Attributes() {
 			if attribute := attrFromBlock(resource, attribute); attribute != nil {
 				attrs = append(attrs, attribute)
@@ -95,19 +145,26 @@ func attrFromBlock(block *hclext.Block, attributeName string) *hclext.Attribute
 // This uses a virtual filesystem to load the Terraform configuration so we can use it in prod and testing.
 // It dows not use the tflint test runner as this limits the tests we can run.
 // e.g. using this we have support for `optional()` evaluation, etc.
+// Input values are read from the autoloaded values files, `TF_VAR_*` environment variables, and the runner's Variables if it is a VariablesRunner.
 func initEvaluator(runner tflint.Runner) (*terraform.Config, *terraform.Evaluator, hcl.Diagnostics) {
+	loader, config, diags := loadConfig(runner)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
 	wd, _ := runner.GetOriginalwd()
-	loader, err := terraform.NewLoader(AppFs, wd)
-	if err != nil {
-		return nil, nil, hcl.Diagnostics{{
-			Summary: err.Error(),
-		}}
+	var vars Variables
+	if vr, ok := runner.(VariablesRunner); ok {
+		vars = vr.Variables()
 	}
-	config, diags := loader.LoadConfig(".", terraform.CallLocalModule)
+	values, diags := loader.LoadValuesFiles(".", vars.Files...)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+	cliValues, diags := terraform.ParseVariableValues(vars.Values, config.Module.Variables)
 	if diags.HasErrors() {
 		return nil, nil, diags
 	}
-	vvals, diags := terraform.VariableValues(config)
+	vvals, diags := terraform.VariableValues(config, append(values, cliValues)...)
 	if diags.HasErrors() {
 		return nil, nil, diags
 	}
@@ -145,3 +202,20 @@ func blocksWithPartialContent(ctx *terraform.Evaluator, module *terraform.Module
 	}, ctx)
 	return resources, diags
 }
+
+// loadConfig loads the configuration in the current directory, calling local child modules.
+func loadConfig(runner tflint.Runner) (*terraform.Loader, *terraform.Config, hcl.Diagnostics) {
+	wd, _ := runner.GetOriginalwd()
+	loader, err := terraform.NewLoader(AppFs, wd)
+	if err != nil {
+		return nil, nil, hcl.Diagnostics{{
+			Severity: hcl.DiagError,
+			Summary:  err.Error(),
+		}}
+	}
+	config, diags := loader.LoadConfig(".", terraform.CallLocalModule)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+	return loader, config, nil
+}
@@ -1,11 +1,13 @@
 package modulecontent
 
 import (
+	"os"
 	"testing"
 
+	"github.com/prashantv/gostub"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/mock"
 	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
-	"github.com/terraform-linters/tflint/terraform"
 )
 
 func TestFetchBlocks(t *testing.T) {
@@ -17,27 +19,19 @@ func TestFetchBlocks(t *testing.T) {
 	mockBlockFetcher.On("LabelNames").Return([]string{"type", "name"})
 	mockBlockFetcher.On("Attributes").Return([]string{"name", "type"})
 
-	config, ctx, diags := initEvaluator(mockRunner)
-	if diags.HasErrors() {
-		t.Fatalf("Failed to initialize evaluator: %v", diags)
-	}
-
 	// Mock the module content
-	module := &terraform.Module{
-		Blocks: []*hclext.Block{
-			{
-				Labels: []string{"azapi_resource", "example"},
-				Body: &hclext.Body{
-					Attributes: map[string]*hclext.Attribute{
-						"name": {Name: "name"},
-						"type": {Name: "type"},
-					},
-				},
-			},
-		},
-	}
+	stub := gostub.Stub(&AppFs, mockFs(map[string]string{
+		"main.tf": `
+resource "azapi_resource" "example" {
+  name = "example"
+  type = "testType@0000-00-00"
+}
 
-	ctx.Config.Module = module
+resource "not_azapi_resource" "example" {
+  name = "example"
+}`,
+	}))
+	defer stub.Reset()
 
 	_, blocks, diags := FetchBlocks(mockBlockFetcher, mockRunner)
 	if diags.HasErrors() {
@@ -53,13 +47,44 @@ func TestFetchBlocks(t *testing.T) {
 	}
 }
 
+func TestFetchBlocksAllLabels(t *testing.T) {
+	mockBlockFetcher := new(MockBlockFetcher)
+	mockBlockFetcher.On("BlockType").Return("module")
+	mockBlockFetcher.On("LabelOne").Return("")
+	mockBlockFetcher.On("LabelNames").Return([]string{"name"})
+	mockBlockFetcher.On("Attributes").Return([]string{"source"})
+
+	stub := gostub.Stub(&AppFs, mockFs(map[string]string{
+		"main.tf": `
+module "b" {
+  source = "Azure/avm-res-keyvault-vault/azurerm"
+}
+
+module "a" {
+  source = "Azure/avm-res-storage-storageaccount/azurerm"
+}`,
+	}))
+	defer stub.Reset()
+
+	_, blocks, diags := FetchBlocks(mockBlockFetcher, new(MockRunner))
+	if diags.HasErrors() {
+		t.Fatalf("FetchBlocks returned errors: %v", diags)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("Expected 2 blocks, got %d", len(blocks))
+	}
+	if blocks[0].Labels[0] != "b" || blocks[1].Labels[0] != "a" {
+		t.Errorf("Expected blocks in file order, got '%s', '%s'", blocks[0].Labels[0], blocks[1].Labels[0])
+	}
+}
+
 // MockRunner is a mock implementation of tflint.Runner for testing purposes.
 type MockRunner struct {
 	tflint.Runner
 }
 
 func (m *MockRunner) GetOriginalwd() (string, error) {
-	return "/mock/path", nil
+	return os.Getwd()
 }
 
 // MockBlockFetcher is a mock implementation of BlockFetcher for testing purposes.
@@ -87,3 +112,12 @@ func (m *MockBlockFetcher) Attributes() []string {
 	args := m.Called()
 	return args.Get(0).([]string)
 }
+
+// mockFs returns an in-memory filesystem containing the given files.
+func mockFs(files map[string]string) afero.Afero {
+	fs := afero.NewMemMapFs()
+	for name, content := range files {
+		_ = afero.WriteFile(fs, name, []byte(content), os.ModePerm)
+	}
+	return afero.Afero{Fs: fs}
+}
@@ -0,0 +1,169 @@
+package modulecontent
+
+import (
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/terraform-linters/tflint/terraform/addrs"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ModuleBlocks are the blocks fetched from a single module instance, together with the evaluator for that instance.
+type ModuleBlocks struct {
+	Path      addrs.ModuleInstance       // The path of the module instance, empty for the root module.
+	CallRange hcl.Range                  // The range of the module call in the root module, the zero value for the root module.
+	Ctx       *terraform.Evaluator       // The evaluator to use for expressions in the blocks.
+	Blocks    []*hclext.Block            // The blocks with the given type and first label.
+	Instances map[*hclext.Block]Instance // The instance of each block.
+}
+
+// ModuleInstance is a root or child module instance in the configuration, together with its evaluator.
+type ModuleInstance struct {
+	Path      addrs.ModuleInstance // The path of the module instance, empty for the root module.
+	CallRange hcl.Range            // The range of the module call in the root module, the zero value for the root module.
+	Config    *terraform.Config    // The configuration of the module.
+	Ctx       *terraform.Evaluator // The evaluator to use for expressions in the module.
+}
+
+// FetchBlocksRecursive returns the blocks of the root module and of every child module instance.
+// Child modules are evaluated with the values of the arguments passed in the calling module block,
+// modules that use `count` or `for_each` are expanded into one ModuleBlocks per instance.
+func FetchBlocksRecursive(f BlockFetcher, runner tflint.Runner) ([]ModuleBlocks, hcl.Diagnostics) {
+	instances, diags := ModuleInstances(runner)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	res := make([]ModuleBlocks, 0, len(instances))
+	for _, mi := range instances {
+		blocks, instances, diags := mi.FetchBlocks(f)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		res = append(res, ModuleBlocks{
+			Path:      mi.Path,
+			CallRange: mi.CallRange,
+			Ctx:       mi.Ctx,
+			Blocks:    blocks,
+			Instances: instances,
+		})
+	}
+	return res, nil
+}
+
+// FetchBlocks returns the blocks of the module instance selected by the fetcher, as in FetchBlockInstances.
+func (mi ModuleInstance) FetchBlocks(f BlockFetcher) ([]*hclext.Block, map[*hclext.Block]Instance, hcl.Diagnostics) {
+	return selectBlocks(mi.Ctx, mi.Config.Module, f)
+}
+
+// ModuleInstances returns the root module and every child module instance in the configuration.
+// Child modules are evaluated with the values of the arguments passed in the calling module block,
+// modules that use `count` or `for_each` are expanded into one ModuleInstance per instance.
+func ModuleInstances(runner tflint.Runner) ([]ModuleInstance, hcl.Diagnostics) {
+	config, ctx, diags := initEvaluator(runner)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return walkModules(ctx, config, hcl.Range{})
+}
+
+// walkModules returns the module instance and recurses into its children.
+func walkModules(ctx *terraform.Evaluator, config *terraform.Config, callRange hcl.Range) ([]ModuleInstance, hcl.Diagnostics) {
+	res := []ModuleInstance{{
+		Path:      ctx.ModulePath,
+		CallRange: callRange,
+		Config:    config,
+		Ctx:       ctx,
+	}}
+	names := make([]string, 0, len(config.Children))
+	for name := range config.Children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := config.Children[name]
+		calls, instances, diags := moduleCallBlocks(ctx, config.Module, child, name)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		for _, call := range calls {
+			childCtx := childEvaluator(ctx, call, name, instances[call].Key)
+			childRange := callRange
+			if ctx.ModulePath.IsRoot() {
+				childRange = call.DefRange
+			}
+			children, diags := walkModules(childCtx, child, childRange)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			res = append(res, children...)
+		}
+	}
+	return res, nil
+}
+
+// moduleCallBlocks returns the expanded module blocks that call the child module, with the child's variables as attributes,
+// and the instance of each block.
+func moduleCallBlocks(ctx *terraform.Evaluator, module *terraform.Module, child *terraform.Config, name string) ([]*hclext.Block, map[*hclext.Block]Instance, hcl.Diagnostics) {
+	attrSchema := make([]hclext.AttributeSchema, 0, len(child.Module.Variables))
+	for v := range child.Module.Variables {
+		attrSchema = append(attrSchema, hclext.AttributeSchema{
+			Name:     v,
+			Required: false,
+		})
+	}
+	schema := hclext.BlockSchema{
+		Type:       "module",
+		LabelNames: []string{"name"},
+		Body: &hclext.BodySchema{
+			Attributes: attrSchema,
+		},
+	}
+	content, diags := module.PartialContent(&hclext.BodySchema{Blocks: []hclext.BlockSchema{schema}}, ctx)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+	instances, diags := blockInstances(ctx, module, schema, content.Blocks)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+	calls := make([]*hclext.Block, 0, 1)
+	for _, block := range content.Blocks {
+		if block.Labels[0] == name {
+			calls = append(calls, block)
+		}
+	}
+	return calls, instances, nil
+}
+
+// childEvaluator returns an evaluator for the child module instance.
+// The input variables of the child are the evaluated arguments of the module call,
+// arguments that cannot be evaluated are treated as unknown.
+func childEvaluator(ctx *terraform.Evaluator, call *hclext.Block, name string, key addrs.InstanceKey) *terraform.Evaluator {
+	path := make(addrs.ModuleInstance, len(ctx.ModulePath), len(ctx.ModulePath)+1)
+	copy(path, ctx.ModulePath)
+	path = append(path, addrs.ModuleInstanceStep{Name: name, InstanceKey: key})
+
+	vals := make(map[string]cty.Value, len(call.Body.Attributes))
+	for attrName, attr := range call.Body.Attributes {
+		val, diags := ctx.EvaluateExpr(attr.Expr, cty.DynamicPseudoType)
+		if diags.HasErrors() {
+			val = cty.DynamicVal
+		}
+		vals[attrName] = val
+	}
+	vvals := make(map[string]map[string]cty.Value, len(ctx.VariableValues)+1)
+	for k, v := range ctx.VariableValues {
+		vvals[k] = v
+	}
+	vvals[path.String()] = vals
+
+	return &terraform.Evaluator{
+		Meta:           ctx.Meta,
+		Config:         ctx.Config,
+		VariableValues: vvals,
+		ModulePath:     path,
+	}
+}
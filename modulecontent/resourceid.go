@@ -0,0 +1,36 @@
+package modulecontent
+
+import "strings"
+
+// ParseResourceID returns the resource type and name of the given Azure resource ID.
+// E.g. `/subscriptions/xxx/resourceGroups/rg/providers/Microsoft.Sql/servers/sql/databases/db`
+// returns `Microsoft.Sql/servers/databases` and `db`.
+// Resource group and subscription IDs return `Microsoft.Resources/resourceGroups` and `Microsoft.Resources/subscriptions`.
+func ParseResourceID(id string) (string, string, bool) {
+	idx := strings.LastIndex(strings.ToLower(id), "/providers/")
+	if idx == -1 {
+		return parseScopeID(id)
+	}
+	segments := strings.Split(strings.Trim(id[idx+len("/providers/"):], "/"), "/")
+	// The namespace must be followed by at least one type and name pair.
+	if len(segments) < 3 || len(segments)%2 != 1 {
+		return "", "", false
+	}
+	types := []string{segments[0]}
+	for i := 1; i < len(segments); i += 2 {
+		types = append(types, segments[i])
+	}
+	return strings.Join(types, "/"), segments[len(segments)-1], true
+}
+
+// parseScopeID returns the resource type and name of a subscription or resource group ID.
+func parseScopeID(id string) (string, string, bool) {
+	segments := strings.Split(strings.Trim(id, "/"), "/")
+	switch {
+	case len(segments) == 2 && strings.EqualFold(segments[0], "subscriptions"):
+		return "Microsoft.Resources/subscriptions", segments[1], true
+	case len(segments) == 4 && strings.EqualFold(segments[0], "subscriptions") && strings.EqualFold(segments[2], "resourceGroups"):
+		return "Microsoft.Resources/resourceGroups", segments[3], true
+	}
+	return "", "", false
+}
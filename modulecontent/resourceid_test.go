@@ -0,0 +1,51 @@
+package modulecontent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResourceID(t *testing.T) {
+	testCases := []struct {
+		id       string
+		wantType string
+		wantName string
+		wantOk   bool
+	}{
+		{
+			id:       "/subscriptions/xxx/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa",
+			wantType: "Microsoft.Storage/storageAccounts",
+			wantName: "sa",
+			wantOk:   true,
+		},
+		{
+			id:       "/subscriptions/xxx/resourceGroups/rg/providers/Microsoft.Sql/servers/sql/databases/db",
+			wantType: "Microsoft.Sql/servers/databases",
+			wantName: "db",
+			wantOk:   true,
+		},
+		{
+			id:       "/subscriptions/xxx/resourceGroups/rg",
+			wantType: "Microsoft.Resources/resourceGroups",
+			wantName: "rg",
+			wantOk:   true,
+		},
+		{
+			id:     "/subscriptions/xxx/resourceGroups",
+			wantOk: false,
+		},
+		{
+			id:     "/subscriptions/xxx/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts",
+			wantOk: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.id, func(t *testing.T) {
+			gotType, gotName, ok := ParseResourceID(tc.id)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantType, gotType)
+			assert.Equal(t, tc.wantName, gotName)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package modulecontent
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Predicate reports whether a fetched block is selected. Attribute values are evaluated with the evaluator of the block's module.
+type Predicate func(ctx *terraform.Evaluator, block *hclext.Block) (bool, error)
+
+// BlockSelector is implemented by a BlockFetcher that selects blocks with a predicate, in addition to LabelOne.
+// The attributes that the predicate uses must be included in the fetcher's Attributes.
+//
+//	func (r *Rule) LabelOne() string { return "" }
+//	func (r *Rule) Selector() modulecontent.Predicate {
+//		return modulecontent.AttributeMatches("source", regexp.MustCompile(`^Azure/avm-res-`))
+//	}
+type BlockSelector interface {
+	Selector() Predicate
+}
+
+// LabelGlob selects blocks whose label at the index matches the pattern, using the syntax of path.Match, e.g. `azapi_*`.
+func LabelGlob(index int, pattern string) Predicate {
+	return func(_ *terraform.Evaluator, block *hclext.Block) (bool, error) {
+		if index >= len(block.Labels) {
+			return false, nil
+		}
+		ok, err := path.Match(pattern, block.Labels[index])
+		if err != nil {
+			return false, fmt.Errorf("invalid label pattern `%s`: %s", pattern, err)
+		}
+		return ok, nil
+	}
+}
+
+// AttributeEquals selects blocks with the attribute set to the value.
+// Blocks without the attribute, or whose value is not known, are not selected.
+func AttributeEquals(name string, want cty.Value) Predicate {
+	return func(ctx *terraform.Evaluator, block *hclext.Block) (bool, error) {
+		val, ok, err := attributeValue(ctx, block, name, want.Type())
+		if err != nil || !ok {
+			return false, err
+		}
+		return val.Equals(want).True(), nil
+	}
+}
+
+// AttributeMatches selects blocks with a string attribute that matches the regular expression.
+// Blocks without the attribute, or whose value is not known, are not selected.
+func AttributeMatches(name string, re *regexp.Regexp) Predicate {
+	return func(ctx *terraform.Evaluator, block *hclext.Block) (bool, error) {
+		val, ok, err := attributeValue(ctx, block, name, cty.String)
+		if err != nil || !ok {
+			return false, err
+		}
+		return re.MatchString(val.AsString()), nil
+	}
+}
+
+// AllOf selects blocks that are selected by all of the predicates.
+func AllOf(preds ...Predicate) Predicate {
+	return func(ctx *terraform.Evaluator, block *hclext.Block) (bool, error) {
+		for _, pred := range preds {
+			ok, err := pred(ctx, block)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+}
+
+// attributeValue evaluates the attribute of the block as the type.
+// It returns false if the block does not have the attribute, or the value is null or not wholly known.
+// Marks such as sensitive are removed, as the value is only compared and not reported.
+func attributeValue(ctx *terraform.Evaluator, block *hclext.Block, name string, ty cty.Type) (cty.Value, bool, error) {
+	attr, ok := block.Body.Attributes[name]
+	if !ok {
+		return cty.NilVal, false, nil
+	}
+	val, diags := ctx.EvaluateExpr(attr.Expr, ty)
+	if diags.HasErrors() {
+		return cty.NilVal, false, fmt.Errorf("could not evaluate `%s` expression: %s", name, diags)
+	}
+	val, _ = val.UnmarkDeep()
+	if val.IsNull() || !val.IsWhollyKnown() {
+		return cty.NilVal, false, nil
+	}
+	return val, true, nil
+}
@@ -0,0 +1,135 @@
+package modulecontent
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSelector(t *testing.T) {
+	stub := gostub.Stub(&AppFs, mockFs(map[string]string{
+		"main.tf": `
+variable "version" {
+  type = string
+}
+
+variable "pinned" {
+  type      = string
+  default   = "0.9.1"
+  sensitive = true
+}
+
+module "vault" {
+  source  = "Azure/avm-res-keyvault-vault/azurerm"
+  version = "0.9.1"
+}
+
+module "storage" {
+  source  = "Azure/avm-res-storage-storageaccount/azurerm"
+  version = var.version
+}
+
+module "network" {
+  source = "git::https://github.com/contoso/network.git"
+}
+
+module "pinned" {
+  source  = "git::https://github.com/contoso/pinned.git"
+  version = var.pinned
+}`,
+	}))
+	defer stub.Reset()
+
+	testCases := []struct {
+		name     string
+		pred     Predicate
+		expected []string
+	}{
+		{
+			name:     "label glob",
+			pred:     LabelGlob(0, "s*"),
+			expected: []string{"storage"},
+		},
+		{
+			name:     "attribute equals",
+			pred:     AttributeEquals("version", cty.StringVal("0.9.1")),
+			expected: []string{"vault", "pinned"},
+		},
+		{
+			name:     "attribute matches",
+			pred:     AttributeMatches("source", regexp.MustCompile(`^Azure/avm-res-`)),
+			expected: []string{"vault", "storage"},
+		},
+		{
+			name:     "unknown value",
+			pred:     AttributeMatches("version", regexp.MustCompile(`.*`)),
+			expected: []string{"vault", "pinned"},
+		},
+		{
+			name:     "sensitive value",
+			pred:     AttributeMatches("version", regexp.MustCompile(`^0\.9\.`)),
+			expected: []string{"vault", "pinned"},
+		},
+		{
+			name:     "all of",
+			pred:     AllOf(LabelGlob(0, "*e*"), AttributeMatches("source", regexp.MustCompile(`^Azure/`))),
+			expected: []string{"storage"},
+		},
+		{
+			name:     "label index out of range",
+			pred:     LabelGlob(1, "*"),
+			expected: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := &selectingBlockFetcher{MockBlockFetcher: new(MockBlockFetcher), pred: tc.pred}
+			fetcher.On("BlockType").Return("module")
+			fetcher.On("LabelOne").Return("")
+			fetcher.On("LabelNames").Return([]string{"name"})
+			fetcher.On("Attributes").Return([]string{"source", "version"})
+
+			_, blocks, diags := FetchBlocks(fetcher, new(MockRunner))
+			if diags.HasErrors() {
+				t.Fatalf("FetchBlocks returned errors: %v", diags)
+			}
+			var got []string
+			for _, block := range blocks {
+				got = append(got, block.Labels[0])
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestSelectorInvalidPattern(t *testing.T) {
+	stub := gostub.Stub(&AppFs, mockFs(map[string]string{
+		"main.tf": `
+module "vault" {
+  source = "Azure/avm-res-keyvault-vault/azurerm"
+}`,
+	}))
+	defer stub.Reset()
+
+	fetcher := &selectingBlockFetcher{MockBlockFetcher: new(MockBlockFetcher), pred: LabelGlob(0, "[")}
+	fetcher.On("BlockType").Return("module")
+	fetcher.On("LabelOne").Return("")
+	fetcher.On("LabelNames").Return([]string{"name"})
+	fetcher.On("Attributes").Return([]string{"source"})
+
+	_, _, diags := FetchBlocks(fetcher, new(MockRunner))
+	assert.True(t, diags.HasErrors())
+}
+
+// selectingBlockFetcher is a mock BlockFetcher that also implements BlockSelector.
+type selectingBlockFetcher struct {
+	*MockBlockFetcher
+	pred Predicate
+}
+
+func (s *selectingBlockFetcher) Selector() Predicate {
+	return s.pred
+}
@@ -0,0 +1,43 @@
+package modulecontent
+
+import (
+	"strings"
+
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// Variables are the input values of the root module in addition to the variable defaults,
+// the autoloaded `terraform.tfvars` and `*.auto.tfvars` files, and `TF_VAR_*` environment variables.
+type Variables struct {
+	Files  []string // Values files relative to the working directory, in increasing order of precedence.
+	Values []string // Values in `name=value` format, which take precedence over the files.
+}
+
+// VariablesRunner is implemented by runners that provide the input values of the root module.
+type VariablesRunner interface {
+	Variables() Variables
+}
+
+// VariablesKey returns a key of the input values of the runner, if it is a VariablesRunner, e.g. to cache results by the values they are evaluated with.
+func VariablesKey(runner tflint.Runner) string {
+	vr, ok := runner.(VariablesRunner)
+	if !ok {
+		return ""
+	}
+	vars := vr.Variables()
+	return strings.Join(vars.Files, "\x00") + "\x01" + strings.Join(vars.Values, "\x00")
+}
+
+// WithVariables returns a runner that provides the input values to the evaluator, and otherwise behaves like runner.
+func WithVariables(runner tflint.Runner, vars Variables) tflint.Runner {
+	return &variablesRunner{Runner: runner, vars: vars}
+}
+
+type variablesRunner struct {
+	tflint.Runner
+	vars Variables
+}
+
+func (r *variablesRunner) Variables() Variables {
+	return r.vars
+}
@@ -0,0 +1,99 @@
+package modulecontent
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestInitEvaluatorVariables(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		vars  *Variables
+		env   map[string]string
+		want  cty.Value
+	}{
+		{
+			name: "no value",
+			want: cty.UnknownVal(cty.String),
+		},
+		{
+			name:  "auto tfvars",
+			files: map[string]string{"prod.auto.tfvars": `tls = "TLS1_1"`},
+			want:  cty.StringVal("TLS1_1"),
+		},
+		{
+			name:  "values file is not loaded without runner variables",
+			files: map[string]string{"prod.tfvars": `tls = "TLS1_1"`},
+			want:  cty.UnknownVal(cty.String),
+		},
+		{
+			name: "values file overrides auto tfvars",
+			files: map[string]string{
+				"a.auto.tfvars": `tls = "TLS1_0"`,
+				"prod.tfvars":   `tls = "TLS1_1"`,
+			},
+			vars: &Variables{Files: []string{"prod.tfvars"}},
+			want: cty.StringVal("TLS1_1"),
+		},
+		{
+			name: "later values file takes precedence",
+			files: map[string]string{
+				"a.tfvars": `tls = "TLS1_0"`,
+				"b.tfvars": `tls = "TLS1_1"`,
+			},
+			vars: &Variables{Files: []string{"a.tfvars", "b.tfvars"}},
+			want: cty.StringVal("TLS1_1"),
+		},
+		{
+			name:  "values override files",
+			files: map[string]string{"prod.tfvars": `tls = "TLS1_1"`},
+			vars:  &Variables{Files: []string{"prod.tfvars"}, Values: []string{"tls=TLS1_2"}},
+			want:  cty.StringVal("TLS1_2"),
+		},
+		{
+			name: "environment variable",
+			env:  map[string]string{"TF_VAR_tls": "TLS1_2"},
+			want: cty.StringVal("TLS1_2"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"main.tf": `variable "tls" { type = string }`}
+			for name, content := range tc.files {
+				files[name] = content
+			}
+			stub := gostub.Stub(&AppFs, mockFs(files))
+			defer stub.Reset()
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			var runner tflint.Runner = new(MockRunner)
+			if tc.vars != nil {
+				runner = WithVariables(runner, *tc.vars)
+			}
+			_, ctx, diags := initEvaluator(runner)
+			require.False(t, diags.HasErrors(), diags.Error())
+
+			expr, diags := hclsyntax.ParseExpression([]byte("var.tls"), "expr.tf", hcl.InitialPos)
+			require.False(t, diags.HasErrors(), diags.Error())
+			got, diags := ctx.EvaluateExpr(expr, cty.String)
+			require.False(t, diags.HasErrors(), diags.Error())
+			assert.True(t, tc.want.RawEquals(got), "got %#v", got)
+		})
+	}
+}
+
+func TestInitEvaluatorUndeclaredValue(t *testing.T) {
+	stub := gostub.Stub(&AppFs, mockFs(map[string]string{"main.tf": ``}))
+	defer stub.Reset()
+	_, _, diags := initEvaluator(WithVariables(new(MockRunner), Variables{Values: []string{"tls=TLS1_2"}}))
+	assert.True(t, diags.HasErrors())
+}
@@ -0,0 +1,118 @@
+package plan
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/check"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruleset"
+	"github.com/spf13/afero"
+)
+
+// Run runs the ruleset against the `azapi_resource` instances in the plan file, read from modulecontent.AppFs.
+// Issues are reported against the resource or module block of the instance in the configuration in the current directory,
+// or against the plan file if the configuration is not there. Addresses in messages are the instance addresses in the plan.
+func Run(rs *ruleset.RuleSet, config *check.Config, wd, planFile string) (*check.Result, error) {
+	p, err := Load(planFile)
+	if err != nil {
+		return nil, err
+	}
+	g, err := generate(p)
+	if err != nil {
+		return nil, err
+	}
+	ranges, err := configRanges()
+	if err != nil {
+		return nil, err
+	}
+
+	fs := afero.Afero{Fs: afero.NewMemMapFs()}
+	for name, content := range g.files {
+		if err := fs.WriteFile(name, content, 0o644); err != nil {
+			return nil, fmt.Errorf("could not write generated configuration: %s", err)
+		}
+	}
+	// Exemptions match the addresses of blocks in the configuration, which the generated blocks do not have.
+	rs.IgnoreExemptions()
+	orig := modulecontent.AppFs
+	modulecontent.AppFs = fs
+	defer func() { modulecontent.AppFs = orig }()
+
+	res, err := check.Run(rs, config, wd)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]string, 0, 2*len(g.addresses))
+	for generated, addr := range g.addresses {
+		pairs = append(pairs, generated, addr)
+	}
+	addresses := strings.NewReplacer(pairs...)
+	for i, issue := range res.Issues {
+		res.Issues[i].Message = addresses.Replace(issue.Message)
+		res.Issues[i].Range = g.configRange(issue.Range, ranges, planFile)
+	}
+	for i, c := range res.Checks {
+		res.Checks[i].Address = addresses.Replace(c.Address)
+	}
+	return res, nil
+}
+
+// configRange returns the range in the configuration of the instance whose generated block contains the range.
+func (g *generated) configRange(r hcl.Range, ranges map[string]hcl.Range, planFile string) hcl.Range {
+	rc, ok := g.resources[r.Filename]
+	if !ok {
+		return r
+	}
+	key := azapiResourceType + "." + rc.Name
+	if names := moduleNames(rc.ModuleAddress); len(names) > 0 {
+		key = "module." + names[0]
+	}
+	if cr, ok := ranges[key]; ok {
+		return cr
+	}
+	return hcl.Range{Filename: planFile}
+}
+
+// configRanges returns the ranges of the `azapi_resource` and module blocks in the root module in the current directory,
+// e.g. `azapi_resource.sa` and `module.app`. Files that cannot be parsed are skipped.
+func configRanges() (map[string]hcl.Range, error) {
+	res := make(map[string]hcl.Range)
+	files, err := modulecontent.AppFs.ReadDir(".")
+	if err != nil {
+		return res, nil
+	}
+	parser := hclparse.NewParser()
+	schema := &hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{
+			{Type: "resource", LabelNames: []string{"type", "name"}},
+			{Type: "module", LabelNames: []string{"name"}},
+		},
+	}
+	for _, fi := range files {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".tf" {
+			continue
+		}
+		b, err := modulecontent.AppFs.ReadFile(fi.Name())
+		if err != nil {
+			return nil, fmt.Errorf("could not read configuration: %s", err)
+		}
+		f, diags := parser.ParseHCL(b, fi.Name())
+		if diags.HasErrors() {
+			continue
+		}
+		content, _, _ := f.Body.PartialContent(schema)
+		for _, block := range content.Blocks {
+			switch {
+			case block.Type == "module":
+				res["module."+block.Labels[0]] = block.DefRange
+			case block.Labels[0] == azapiResourceType:
+				res[azapiResourceType+"."+block.Labels[1]] = block.DefRange
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,9 @@
+// Package plan runs the ruleset against the `azapi_resource` instances in a Terraform plan,
+// as written by `terraform show -json`, instead of the HCL configuration.
+//
+// Values from data sources, remote state and computed outputs are known in a plan, so bodies
+// that cannot be evaluated statically can be checked. Each planned instance is written as a
+// resource block with literal values into an in-memory configuration, which the rules read
+// through modulecontent.AppFs as usual. Issues are then mapped back to the instance addresses
+// and, if the configuration is in the working directory, to the ranges of its resource and module blocks.
+package plan
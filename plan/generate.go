@@ -0,0 +1,200 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	// unknownVariable is the variable without a value that stands in for unknown values.
+	unknownVariable = "unknown"
+	// variablesFile is the generated file that declares unknownVariable.
+	variablesFile = "variables.tf"
+)
+
+// generatedAttributes are the attributes of the planned values that are written to the generated resource blocks.
+var generatedAttributes = []string{"type", "name", "parent_id", "body", "tags", "schema_validation_enabled"}
+
+// generated is the configuration generated from a plan, with a resource block per `azapi_resource` instance.
+type generated struct {
+	files     map[string][]byte         // The generated files by name.
+	resources map[string]ResourceChange // The instance of each generated file.
+	addresses map[string]string         // The instance address of each generated resource address.
+}
+
+// generate writes each `azapi_resource` instance in the plan as a resource block in its own file.
+// The blocks are named `plan_<n>` as instance addresses are not valid block labels.
+// Unknown values are written as references to the resources referenced by the attribute in the configuration,
+// so that rules that match resources by reference still work, or to an unknown variable.
+func generate(p *Plan) (*generated, error) {
+	rcs := p.AzApiResources()
+	names := make([]string, len(rcs))
+	byConfigAddress := make(map[string][]string)
+	for i, rc := range rcs {
+		names[i] = fmt.Sprintf("plan_%0*d", len(fmt.Sprint(len(rcs))), i+1)
+		key := configAddressKey(rc.ModuleAddress, rc.Name)
+		byConfigAddress[key] = append(byConfigAddress[key], names[i])
+	}
+
+	g := &generated{
+		files:     map[string][]byte{variablesFile: []byte(fmt.Sprintf("variable %q {}\n", unknownVariable))},
+		resources: make(map[string]ResourceChange, len(rcs)),
+		addresses: make(map[string]string, len(rcs)),
+	}
+	for i, rc := range rcs {
+		cr, _ := p.ConfigResource(rc)
+		f := hclwrite.NewEmptyFile()
+		block := f.Body().AppendNewBlock("resource", []string{azapiResourceType, names[i]}).Body()
+		for _, attr := range generatedAttributes {
+			val, exists := rc.Change.After[attr]
+			unknown := rc.Change.AfterUnknown[attr]
+			if (!exists || val == nil) && unknown == nil {
+				continue
+			}
+			if s, ok := val.(string); ok && attr == "body" {
+				val = decodeJSONString(s)
+			}
+			tokens, err := valueTokens(val, unknown, unknownTokens(rc, cr, attr, byConfigAddress))
+			if err != nil {
+				return nil, fmt.Errorf("could not write `%s` of `%s`: %s", attr, rc.Address, err)
+			}
+			block.SetAttributeRaw(attr, tokens)
+		}
+		filename := names[i] + ".tf"
+		g.files[filename] = f.Bytes()
+		g.resources[filename] = rc
+		g.addresses[azapiResourceType+"."+names[i]] = rc.Address
+	}
+	return g, nil
+}
+
+// configAddressKey returns the key of a resource in a module instance, e.g. `module.app[0]|sa`.
+func configAddressKey(moduleAddress, name string) string {
+	return moduleAddress + "|" + name
+}
+
+// unknownTokens returns the expression that stands in for unknown values of the attribute.
+// It is a reference to the `azapi_resource` instances that the attribute references in the module instance,
+// combined with `coalesce` if there are several, or else the unknown variable.
+func unknownTokens(rc ResourceChange, cr *ConfigResource, attr string, byConfigAddress map[string][]string) hclwrite.Tokens {
+	refs := make([]hclwrite.Tokens, 0)
+	seen := make(map[string]bool)
+	if cr != nil {
+		for _, ref := range cr.References(attr) {
+			steps := splitAddress(ref)
+			if len(steps) < 2 || steps[0] != azapiResourceType {
+				continue
+			}
+			name, _, _ := strings.Cut(steps[1], "[")
+			for _, generated := range byConfigAddress[configAddressKey(rc.ModuleAddress, name)] {
+				if seen[generated] {
+					continue
+				}
+				seen[generated] = true
+				refs = append(refs, hclwrite.TokensForTraversal(hcl.Traversal{
+					hcl.TraverseRoot{Name: azapiResourceType},
+					hcl.TraverseAttr{Name: generated},
+					hcl.TraverseAttr{Name: "id"},
+				}))
+			}
+		}
+	}
+	switch len(refs) {
+	case 0:
+		return hclwrite.TokensForTraversal(hcl.Traversal{
+			hcl.TraverseRoot{Name: "var"},
+			hcl.TraverseAttr{Name: unknownVariable},
+		})
+	case 1:
+		return refs[0]
+	}
+	return hclwrite.TokensForFunctionCall("coalesce", refs...)
+}
+
+// valueTokens returns the expression of a planned value, where unknown is the corresponding value of `after_unknown`.
+// Unknown values are written as unknownTokens.
+func valueTokens(val, unknown interface{}, unknownTokens hclwrite.Tokens) (hclwrite.Tokens, error) {
+	if unknown == true {
+		return unknownTokens, nil
+	}
+	switch v := val.(type) {
+	case map[string]interface{}:
+		um, _ := unknown.(map[string]interface{})
+		return objectTokens(v, um, unknownTokens)
+	case []interface{}:
+		ul, _ := unknown.([]interface{})
+		elems := make([]hclwrite.Tokens, len(v))
+		for i, e := range v {
+			var u interface{}
+			if i < len(ul) {
+				u = ul[i]
+			}
+			tokens, err := valueTokens(e, u, unknownTokens)
+			if err != nil {
+				return nil, err
+			}
+			elems[i] = tokens
+		}
+		return hclwrite.TokensForTuple(elems), nil
+	case nil:
+		if um, ok := unknown.(map[string]interface{}); ok {
+			return objectTokens(map[string]interface{}{}, um, unknownTokens)
+		}
+		return hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType)), nil
+	case string:
+		return hclwrite.TokensForValue(cty.StringVal(v)), nil
+	case bool:
+		return hclwrite.TokensForValue(cty.BoolVal(v)), nil
+	case json.Number:
+		n, err := cty.ParseNumberVal(v.String())
+		if err != nil {
+			return nil, err
+		}
+		return hclwrite.TokensForValue(n), nil
+	}
+	return nil, fmt.Errorf("unsupported value of type %T", val)
+}
+
+// objectTokens returns the expression of an object with the known attributes and the attributes that are unknown.
+func objectTokens(val, unknown map[string]interface{}, unknownTokens hclwrite.Tokens) (hclwrite.Tokens, error) {
+	keys := make([]string, 0, len(val))
+	for k := range val {
+		keys = append(keys, k)
+	}
+	for k, u := range unknown {
+		if _, ok := val[k]; !ok && u != false {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	attrs := make([]hclwrite.ObjectAttrTokens, len(keys))
+	for i, k := range keys {
+		tokens, err := valueTokens(val[k], unknown[k], unknownTokens)
+		if err != nil {
+			return nil, err
+		}
+		attrs[i] = hclwrite.ObjectAttrTokens{
+			Name:  hclwrite.TokensForValue(cty.StringVal(k)),
+			Value: tokens,
+		}
+	}
+	return hclwrite.TokensForObject(attrs), nil
+}
+
+// decodeJSONString returns the decoded value of a JSON encoded body, as used by version 1 of the azapi provider,
+// or the string itself if it is not JSON.
+func decodeJSONString(s string) interface{} {
+	var res interface{}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	if err := dec.Decode(&res); err != nil {
+		return s
+	}
+	return res
+}
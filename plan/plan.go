@@ -0,0 +1,171 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+)
+
+// azapiResourceType is the Terraform resource type that the rules check.
+const azapiResourceType = "azapi_resource"
+
+// Plan is the subset of the JSON plan format used by the rules.
+type Plan struct {
+	FormatVersion   string           `json:"format_version"`
+	ResourceChanges []ResourceChange `json:"resource_changes"`
+	Configuration   Configuration    `json:"configuration"`
+}
+
+// ResourceChange is the planned change of a resource instance.
+type ResourceChange struct {
+	Address       string `json:"address"`        // The instance address, e.g. `module.app[0].azapi_resource.sa["a"]`.
+	ModuleAddress string `json:"module_address"` // The module instance address, empty for the root module.
+	Mode          string `json:"mode"`
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	Change        Change `json:"change"`
+}
+
+// Change is the planned values of a resource instance.
+type Change struct {
+	Actions      []string               `json:"actions"`
+	After        map[string]interface{} `json:"after"`         // The planned values, without unknown values.
+	AfterUnknown map[string]interface{} `json:"after_unknown"` // The values that are unknown until apply, as `true` leaves.
+}
+
+// Configuration is the configuration of the root module in the plan.
+type Configuration struct {
+	RootModule ConfigModule `json:"root_module"`
+}
+
+// ConfigModule is the configuration of a module in the plan.
+type ConfigModule struct {
+	Resources   []ConfigResource      `json:"resources"`
+	ModuleCalls map[string]ModuleCall `json:"module_calls"`
+}
+
+// ModuleCall is a module block in the configuration in the plan.
+type ModuleCall struct {
+	Module ConfigModule `json:"module"`
+}
+
+// ConfigResource is a resource block in the configuration in the plan.
+type ConfigResource struct {
+	Address     string                     `json:"address"` // The address relative to the module, e.g. `azapi_resource.sa`.
+	Mode        string                     `json:"mode"`
+	Type        string                     `json:"type"`
+	Name        string                     `json:"name"`
+	Expressions map[string]json.RawMessage `json:"expressions"`
+}
+
+// expression is an attribute expression in the configuration in the plan.
+type expression struct {
+	References []string `json:"references"`
+}
+
+// Load reads the JSON plan from the file in modulecontent.AppFs.
+func Load(filename string) (*Plan, error) {
+	b, err := modulecontent.AppFs.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read plan: %s", err)
+	}
+	return Parse(b)
+}
+
+// Parse parses a JSON plan, as written by `terraform show -json`.
+// Numbers are kept as json.Number so that they are not rounded.
+func Parse(b []byte) (*Plan, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	p := &Plan{}
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("could not parse plan: %s", err)
+	}
+	if p.FormatVersion == "" {
+		return nil, fmt.Errorf("could not parse plan: `format_version` is missing, the file must be the output of `terraform show -json`")
+	}
+	return p, nil
+}
+
+// AzApiResources returns the `azapi_resource` instances that exist after the plan is applied.
+func (p *Plan) AzApiResources() []ResourceChange {
+	res := make([]ResourceChange, 0)
+	for _, rc := range p.ResourceChanges {
+		if rc.Mode != "managed" || rc.Type != azapiResourceType || rc.Change.After == nil {
+			continue
+		}
+		res = append(res, rc)
+	}
+	return res
+}
+
+// ConfigResource returns the configuration of the resource instance, if the plan includes it.
+func (p *Plan) ConfigResource(rc ResourceChange) (*ConfigResource, bool) {
+	module := &p.Configuration.RootModule
+	for _, name := range moduleNames(rc.ModuleAddress) {
+		call, ok := module.ModuleCalls[name]
+		if !ok {
+			return nil, false
+		}
+		module = &call.Module
+	}
+	for i, cr := range module.Resources {
+		if cr.Mode == rc.Mode && cr.Type == rc.Type && cr.Name == rc.Name {
+			return &module.Resources[i], true
+		}
+	}
+	return nil, false
+}
+
+// References returns the references of the attribute's expression, or nil if there are none.
+func (cr *ConfigResource) References(attr string) []string {
+	raw, ok := cr.Expressions[attr]
+	if !ok {
+		return nil
+	}
+	expr := &expression{}
+	if err := json.Unmarshal(raw, expr); err != nil {
+		return nil
+	}
+	return expr.References
+}
+
+// moduleNames returns the names of the module calls in a module instance address, e.g. `app` and `db` for `module.app[0].module.db`.
+func moduleNames(moduleAddress string) []string {
+	res := make([]string, 0)
+	for _, step := range splitAddress(moduleAddress) {
+		name, _, _ := strings.Cut(step, "[")
+		if name != "module" {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// splitAddress splits an address at the dots that are not within an instance key, e.g. `module.a["x.y"]` into `module` and `a["x.y"]`.
+func splitAddress(addr string) []string {
+	if addr == "" {
+		return nil
+	}
+	res := make([]string, 0)
+	start, inKey, inString := 0, false, false
+	for i := 0; i < len(addr); i++ {
+		switch c := addr[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"' && inKey:
+			inString = !inString
+		case c == '[' && !inString:
+			inKey = true
+		case c == ']' && !inString:
+			inKey = false
+		case c == '.' && !inKey:
+			res = append(res, addr[start:i])
+			start = i + 1
+		}
+	}
+	return append(res, addr[start:])
+}
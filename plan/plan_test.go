@@ -0,0 +1,141 @@
+package plan
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/check"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruleset"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotPlan(t *testing.T) {
+	_, err := Parse([]byte(`{"resources": []}`))
+	assert.ErrorContains(t, err, "`format_version` is missing")
+	_, err = Parse([]byte(`not json`))
+	assert.ErrorContains(t, err, "could not parse plan")
+}
+
+func TestSplitAddress(t *testing.T) {
+	assert.Equal(t, []string{"module", `a["x.y"]`, "module", "b[0]", "azapi_resource", "c"}, splitAddress(`module.a["x.y"].module.b[0].azapi_resource.c`))
+	assert.Equal(t, []string{"a", "b"}, moduleNames(`module.a["x]."].module.b`))
+	assert.Empty(t, moduleNames(""))
+}
+
+func TestGenerate(t *testing.T) {
+	files, err := ruletest.LoadFiles("testdata")
+	require.NoError(t, err)
+	p, err := Parse([]byte(files["plan.json"]))
+	require.NoError(t, err)
+	g, err := generate(p)
+	require.NoError(t, err)
+
+	// The data source and the deleted instance are not generated.
+	assert.Len(t, g.resources, 3)
+	assert.Equal(t, map[string]string{
+		"azapi_resource.plan_1": "azapi_resource.pe",
+		"azapi_resource.plan_2": "azapi_resource.sa",
+		"azapi_resource.plan_3": `module.vaults[0].azapi_resource.kv["a.b"]`,
+	}, g.addresses)
+
+	// The unknown private link service ID references the storage account.
+	assert.Contains(t, string(g.files["plan_1.tf"]), `"privateLinkServiceId" = azapi_resource.plan_2.id`)
+	// Tags are generated for tag exemptions.
+	assert.Contains(t, string(g.files["plan_2.tf"]), `"waf-exempt" = "RE:05"`)
+	// A JSON encoded body is decoded.
+	assert.Contains(t, string(g.files["plan_3.tf"]), `"softDeleteRetentionInDays" = 90`)
+}
+
+func TestValueTokensUnknown(t *testing.T) {
+	unknown := unknownTokens(ResourceChange{}, nil, "body", nil)
+	tokens, err := valueTokens(
+		map[string]interface{}{"a": "${x}"},
+		map[string]interface{}{"b": true, "c": false},
+		unknown,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\" = \"$${x}\"\n  \"b\" = var.unknown\n}", string(tokens.Bytes()))
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  bool
+		plan    string
+		want    []string
+		checked string // An address that must have been checked.
+	}{
+		{
+			name:   "with configuration",
+			config: true,
+			want: []string{
+				"main.tf:27,1-16: azapi_private_endpoint_required: `module.vaults[0].azapi_resource.kv[\"a.b\"]` has public network access disabled",
+				"main.tf:1,1-31: azapi_storage_account_min_tls_version: returned value `TLS1_0` not in expected values `[TLS1_2]`",
+				"main.tf:1,1-31: waf_tag_exemption: `azapi_storage_account_zone_redundancy` is exempted by the `waf-exempt` tag: returned value `Standard_LRS` not in expected values",
+			},
+			checked: `module.vaults[0].azapi_resource.kv["a.b"]`,
+		},
+		{
+			name: "without configuration",
+			want: []string{
+				"plan.json:0,0-0: azapi_private_endpoint_required: `module.vaults[0].azapi_resource.kv[\"a.b\"]` has public network access disabled",
+				"plan.json:0,0-0: azapi_storage_account_min_tls_version: returned value `TLS1_0` not in expected values `[TLS1_2]`",
+				"plan.json:0,0-0: waf_tag_exemption: `azapi_storage_account_zone_redundancy` is exempted by the `waf-exempt` tag: returned value `Standard_LRS` not in expected values",
+			},
+			checked: `module.vaults[0].azapi_resource.kv["a.b"]`,
+		},
+		{
+			name: "unknown value in body",
+			plan: "plan_unknown.json",
+			want: []string{
+				"plan_unknown.json:0,0-0: azapi_private_endpoint_required: `azapi_resource.sa` has public network access disabled",
+				"plan_unknown.json:0,0-0: azapi_storage_account_min_tls_version: returned value `TLS1_0` not in expected values `[TLS1_2]`",
+			},
+			checked: "azapi_resource.sa",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := ruletest.LoadFiles("testdata")
+			require.NoError(t, err)
+			if !tc.config {
+				delete(files, "main.tf")
+			}
+			fs := ruletest.MemFs(files)
+			stub := gostub.Stub(&modulecontent.AppFs, fs)
+			defer stub.Reset()
+
+			wd, _ := os.Getwd()
+			config, err := check.LoadConfig(check.DefaultConfigFile)
+			require.NoError(t, err)
+			plan := tc.plan
+			if plan == "" {
+				plan = "plan.json"
+			}
+			res, err := Run(ruleset.New(check.PluginName, "0.0.0", rules.Rules), config, wd, plan)
+			require.NoError(t, err)
+			assert.Equal(t, fs, modulecontent.AppFs, "the file system must be restored")
+
+			got := make([]string, len(res.Issues))
+			for i, issue := range res.Issues {
+				got[i] = issue.Range.String() + ": " + issue.Rule.Name() + ": " + issue.Message
+			}
+			require.Len(t, got, len(tc.want))
+			for i, want := range tc.want {
+				assert.True(t, strings.HasPrefix(got[i], want), "got %s, want prefix %s", got[i], want)
+			}
+
+			addresses := make(map[string]bool)
+			for _, c := range res.Checks {
+				addresses[c.Address] = true
+			}
+			assert.True(t, addresses[tc.checked])
+		})
+	}
+}
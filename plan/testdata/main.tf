@@ -0,0 +1,30 @@
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name      = data.external.names.result.storage
+  parent_id = data.azapi_resource.rg.id
+  body      = local.storage_body
+}
+
+resource "azapi_resource" "pe" {
+  type      = "Microsoft.Network/privateEndpoints@2023-11-01"
+  name      = "pe-sa"
+  parent_id = data.azapi_resource.rg.id
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "blob"
+          properties = {
+            privateLinkServiceId = azapi_resource.sa.id
+            groupIds             = ["blob"]
+          }
+        }
+      ]
+    }
+  }
+}
+
+module "vaults" {
+  source = "./modules/vaults"
+  count  = 1
+}
@@ -0,0 +1,3 @@
+// Package report writes the issues found by the standalone runner in machine readable formats,
+// including the Well-Architected pillar, recommendation IDs and remediation of each rule.
+package report
@@ -0,0 +1,84 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/check"
+)
+
+// JSONSchemaVersion is the version of the JSON report format, incremented on incompatible changes.
+const JSONSchemaVersion = 1
+
+// JSONReport is the JSON report format.
+type JSONReport struct {
+	SchemaVersion int         `json:"schema_version"`
+	Issues        []JSONIssue `json:"issues"`
+}
+
+// JSONIssue is an issue in the JSON report format.
+type JSONIssue struct {
+	Rule              string    `json:"rule"`
+	Severity          string    `json:"severity"`
+	Message           string    `json:"message"`
+	Link              string    `json:"link"`
+	Pillar            string    `json:"pillar"`
+	RecommendationIDs []string  `json:"recommendation_ids"`
+	Remediation       string    `json:"remediation"`
+	Range             JSONRange `json:"range"`
+}
+
+// JSONRange is the location of an issue in the JSON report format.
+type JSONRange struct {
+	Filename string  `json:"filename"`
+	Start    JSONPos `json:"start"`
+	End      JSONPos `json:"end"`
+}
+
+// JSONPos is a position in a file in the JSON report format.
+type JSONPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// NewJSONReport returns the JSON report of the issues.
+func NewJSONReport(issues []check.Issue) JSONReport {
+	res := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		Issues:        make([]JSONIssue, len(issues)),
+	}
+	for i, issue := range issues {
+		md := metadata(issue.Rule)
+		ids := md.RecommendationIDs
+		if ids == nil {
+			ids = []string{}
+		}
+		res.Issues[i] = JSONIssue{
+			Rule:              issue.Rule.Name(),
+			Severity:          severityName(issue.Rule.Severity()),
+			Message:           messageWithoutMetadata(issue.Rule, issue.Message),
+			Link:              issue.Rule.Link(),
+			Pillar:            string(md.Pillar),
+			RecommendationIDs: ids,
+			Remediation:       md.Remediation,
+			Range:             newJSONRange(issue.Range),
+		}
+	}
+	return res
+}
+
+// WriteJSON writes the issues as an indented JSONReport.
+func WriteJSON(w io.Writer, issues []check.Issue) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewJSONReport(issues))
+}
+
+func newJSONRange(r hcl.Range) JSONRange {
+	return JSONRange{
+		Filename: r.Filename,
+		Start:    JSONPos{Line: r.Start.Line, Column: r.Start.Column},
+		End:      JSONPos{Line: r.End.Line, Column: r.End.Column},
+	}
+}
@@ -0,0 +1,260 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/check"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// otherSuite is the test suite of rules without Well-Architected metadata.
+const otherSuite = "Other"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name       string           `xml:"name,attr"`
+	Classname  string           `xml:"classname,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Failure    *junitFailure    `xml:"failure,omitempty"`
+}
+
+type junitProperties struct {
+	Properties []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the issues as JUnit XML with a test suite per pillar.
+// Each rule has a failed test case per resource instance with issues, and a passing test case per resource instance that passed its checks,
+// named after the address of the instance, e.g. `azapi_resource.sa["a"]`, see issueAddresses.
+// Issues that are not of a failed check, such as issues of rules that do not record their checks, are named after their location instead.
+// Enabled rules without issues or passed checks have a single passing test case named after the rule.
+// The Well-Architected metadata is in the test case properties, so it is removed from the failure messages.
+func WriteJUnit(w io.Writer, rules []tflint.Rule, issues []check.Issue, checks []score.Check) error {
+	type key struct {
+		rule string
+		name string
+	}
+	suites := make(map[string]*junitTestSuite)
+	suite := func(rule tflint.Rule) *junitTestSuite {
+		name := otherSuite
+		if md := waf.FromRule(rule); md != nil && md.Pillar != "" {
+			name = string(md.Pillar)
+		}
+		if suites[name] == nil {
+			suites[name] = &junitTestSuite{Name: name}
+		}
+		return suites[name]
+	}
+
+	type caseRef struct {
+		suite *junitTestSuite
+		index int
+	}
+	tested := make(map[string]bool)
+	cases := make(map[key]caseRef)
+	addresses := issueAddresses(issues, checks)
+	for i, issue := range issues {
+		name := addresses[i]
+		if name == "" {
+			name = fmt.Sprintf("%s:%d", issue.Range.Filename, issue.Range.Start.Line)
+		}
+		k := key{issue.Rule.Name(), name}
+		tested[k.rule] = true
+		msg := messageWithoutMetadata(issue.Rule, issue.Message)
+		if ref, ok := cases[k]; ok {
+			f := ref.suite.Cases[ref.index].Failure
+			f.Message += "\n" + msg
+			f.Text += "\n" + issue.Range.String()
+			continue
+		}
+		s := suite(issue.Rule)
+		cases[k] = caseRef{s, len(s.Cases)}
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:       name,
+			Classname:  issue.Rule.Name(),
+			Properties: newJUnitProperties(issue.Rule),
+			Failure: &junitFailure{
+				Message: msg,
+				Type:    severityName(issue.Rule.Severity()),
+				Text:    issue.Range.String(),
+			},
+		})
+	}
+	for _, c := range passedChecks(checks) {
+		tested[c.Rule.Name()] = true
+		s := suite(c.Rule)
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:       c.Address,
+			Classname:  c.Rule.Name(),
+			Properties: newJUnitProperties(c.Rule),
+		})
+	}
+	for _, rule := range rules {
+		if tested[rule.Name()] {
+			continue
+		}
+		s := suite(rule)
+		s.Cases = append(s.Cases, junitTestCase{
+			Name:       rule.Name(),
+			Classname:  rule.Name(),
+			Properties: newJUnitProperties(rule),
+		})
+	}
+
+	res := junitTestSuites{}
+	for _, name := range suiteNames() {
+		s, ok := suites[name]
+		if !ok {
+			continue
+		}
+		s.Tests = len(s.Cases)
+		for _, tc := range s.Cases {
+			if tc.Failure != nil {
+				s.Failures++
+			}
+		}
+		res.Tests += s.Tests
+		res.Failures += s.Failures
+		res.Suites = append(res.Suites, *s)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// passedChecks returns the checks of the resource addresses that passed all of the checks of a rule, once per rule and address.
+func passedChecks(checks []score.Check) []score.Check {
+	type key struct {
+		rule    string
+		address string
+	}
+	failed := make(map[key]bool)
+	for _, c := range checks {
+		if !c.Passed {
+			failed[key{c.Rule.Name(), c.Address}] = true
+		}
+	}
+	seen := make(map[key]bool)
+	res := make([]score.Check, 0, len(checks))
+	for _, c := range checks {
+		k := key{c.Rule.Name(), c.Address}
+		if !c.Passed || failed[k] || seen[k] {
+			continue
+		}
+		seen[k] = true
+		res = append(res, c)
+	}
+	return res
+}
+
+// issueAddresses returns the address of the resource instance of each issue, or an empty string if it is not known.
+// The address is that of a failed check of the issue's rule at the issue's range.
+// The instances of an expanded resource share the range, so the address mentioned first in the message is preferred,
+// and otherwise the issues at a range are assigned to the failed checks there in order.
+func issueAddresses(issues []check.Issue, checks []score.Check) []string {
+	type key struct {
+		rule  string
+		start hcl.Pos
+		file  string
+	}
+	failed := make(map[key][]string)
+	seen := make(map[key]map[string]bool)
+	for _, c := range checks {
+		if c.Passed || c.Range.Filename == "" {
+			continue
+		}
+		k := key{c.Rule.Name(), c.Range.Start, c.Range.Filename}
+		if seen[k] == nil {
+			seen[k] = make(map[string]bool)
+		}
+		if seen[k][c.Address] {
+			continue
+		}
+		seen[k][c.Address] = true
+		failed[k] = append(failed[k], c.Address)
+	}
+	res := make([]string, len(issues))
+	n := make(map[key]int)
+	for i, issue := range issues {
+		k := key{issue.Rule.Name(), issue.Range.Start, issue.Range.Filename}
+		candidates := failed[k]
+		if len(candidates) == 0 {
+			continue
+		}
+		pos := -1
+		for _, addr := range candidates {
+			if p := strings.Index(issue.Message, "`"+addr+"`"); p >= 0 && (pos < 0 || p < pos) {
+				res[i], pos = addr, p
+			}
+		}
+		if res[i] == "" {
+			res[i] = candidates[min(n[k], len(candidates)-1)]
+			n[k]++
+		}
+	}
+	return res
+}
+
+// suiteNames returns the test suite names in the order of waf.Pillars, followed by the suite of rules without metadata.
+func suiteNames() []string {
+	res := make([]string, 0, len(waf.Pillars)+1)
+	for _, p := range waf.Pillars {
+		res = append(res, string(p))
+	}
+	return append(res, otherSuite)
+}
+
+// newJUnitProperties returns the Well-Architected metadata and link of the rule as test case properties.
+func newJUnitProperties(rule tflint.Rule) *junitProperties {
+	md := metadata(rule)
+	props := []junitProperty{{Name: "severity", Value: severityName(rule.Severity())}}
+	if rule.Link() != "" {
+		props = append(props, junitProperty{Name: "link", Value: rule.Link()})
+	}
+	if md.Pillar != "" {
+		props = append(props, junitProperty{Name: "pillar", Value: string(md.Pillar)})
+	}
+	if len(md.RecommendationIDs) > 0 {
+		props = append(props, junitProperty{Name: "recommendation_ids", Value: strings.Join(md.RecommendationIDs, ", ")})
+	}
+	if md.Remediation != "" {
+		props = append(props, junitProperty{Name: "remediation", Value: md.Remediation})
+	}
+	return &junitProperties{Properties: props}
+}
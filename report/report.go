@@ -0,0 +1,85 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/check"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// Format is an output format of the report.
+type Format string
+
+// Supported formats.
+const (
+	Text  Format = "text"
+	JSON  Format = "json"
+	SARIF Format = "sarif"
+	JUnit Format = "junit"
+)
+
+// Formats are the supported formats.
+var Formats = []Format{Text, JSON, SARIF, JUnit}
+
+// ParseFormat parses a format name case insensitively.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("invalid format `%s`, valid formats are: %s", s, strings.Join(names, ", "))
+}
+
+// Tool describes the program that produced the report.
+type Tool struct {
+	Name    string // The name of the program, e.g. `wafcheck`.
+	Version string // The version of the ruleset.
+	URI     string // The home page of the ruleset.
+}
+
+// Write writes the issues in the format. The rules are the enabled rules, which are described in the SARIF and JUnit formats.
+// The checks of the rules are the passing test cases of the JUnit format.
+func Write(w io.Writer, format Format, tool Tool, rules []tflint.Rule, res *check.Result) error {
+	switch format {
+	case Text:
+		return check.WriteText(w, res.Issues)
+	case JSON:
+		return WriteJSON(w, res.Issues)
+	case SARIF:
+		return WriteSARIF(w, tool, rules, res.Issues)
+	case JUnit:
+		return WriteJUnit(w, rules, res.Issues, res.Checks)
+	}
+	return fmt.Errorf("unsupported format `%s`", format)
+}
+
+// metadata returns the Well-Architected metadata of the rule, or empty metadata if it has none.
+func metadata(rule tflint.Rule) waf.Metadata {
+	if md := waf.FromRule(rule); md != nil {
+		return *md
+	}
+	return waf.Metadata{}
+}
+
+// messageWithoutMetadata returns the issue message without the Well-Architected metadata appended by waf.Metadata.IssueMessage,
+// as the reports have the metadata in fields of their own.
+func messageWithoutMetadata(rule tflint.Rule, msg string) string {
+	suffix := waf.FromRule(rule).IssueMessage("")
+	if suffix == "" {
+		return msg
+	}
+	return strings.TrimSuffix(msg, suffix)
+}
+
+// severityName returns the lower case name of the severity, e.g. `error`.
+func severityName(s tflint.Severity) string {
+	return strings.ToLower(s.String())
+}
@@ -0,0 +1,284 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/check"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+var (
+	tlsRule = rules.NewAzApiRule("tls", "https://example.com/tls", "Microsoft.Storage/storageAccounts", "", "", "properties.minimumTlsVersion", blockquery.IsOneOf, blockquery.NewStringResults("TLS1_2")...).
+		WithMetadata(waf.Metadata{Pillar: waf.Security, RecommendationIDs: []string{"SE:07"}, Remediation: "Use TLS1_2.", Rationale: "Old TLS is insecure."})
+	zrsRule = rules.NewAzApiRule("zrs", "https://example.com/zrs", "Microsoft.Storage/storageAccounts", "", "", "sku.name", blockquery.IsOneOf, blockquery.NewStringResults("Standard_ZRS")...).
+		WithMetadata(waf.Metadata{Pillar: waf.Reliability, RecommendationIDs: []string{"RE:05"}})
+	plainRule = rules.NewAzApiRule("plain", "", "Microsoft.Storage/storageAccounts", "", "", "kind", blockquery.IsOneOf, blockquery.NewStringResults("StorageV2")...)
+)
+
+func testIssues() []check.Issue {
+	rng := func(line int) hcl.Range {
+		return hcl.Range{
+			Filename: "main.tf",
+			Start:    hcl.Pos{Line: line, Column: 3},
+			End:      hcl.Pos{Line: line + 4, Column: 4},
+		}
+	}
+	return []check.Issue{
+		{Rule: tlsRule, Message: "tls one", Range: rng(5)},
+		{Rule: tlsRule, Message: "tls two", Range: rng(20)},
+		{Rule: plainRule, Message: "plain", Range: rng(5)},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat("SARIF")
+	require.NoError(t, err)
+	assert.Equal(t, SARIF, f)
+	_, err = ParseFormat("xml")
+	assert.ErrorContains(t, err, "valid formats are: text, json, sarif, junit")
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, testIssues()))
+	var got JSONReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, JSONSchemaVersion, got.SchemaVersion)
+	require.Len(t, got.Issues, 3)
+	assert.Equal(t, JSONIssue{
+		Rule:              "tls",
+		Severity:          "error",
+		Message:           "tls one",
+		Link:              "https://example.com/tls",
+		Pillar:            "Security",
+		RecommendationIDs: []string{"SE:07"},
+		Remediation:       "Use TLS1_2.",
+		Range: JSONRange{
+			Filename: "main.tf",
+			Start:    JSONPos{Line: 5, Column: 3},
+			End:      JSONPos{Line: 9, Column: 4},
+		},
+	}, got.Issues[0])
+	assert.Equal(t, []string{}, got.Issues[2].RecommendationIDs)
+}
+
+func TestWriteJSONNoIssues(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, nil))
+	assert.JSONEq(t, `{"schema_version": 1, "issues": []}`, buf.String())
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	tool := Tool{Name: "wafcheck", Version: "1.2.3", URI: "https://example.com"}
+	require.NoError(t, WriteSARIF(&buf, tool, []tflint.Rule{tlsRule, zrsRule}, testIssues()))
+
+	var got struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name    string `json:"name"`
+					Version string `json:"version"`
+					Rules   []struct {
+						ID               string `json:"id"`
+						HelpURI          string `json:"helpUri"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+						Properties struct {
+							Pillar            string   `json:"pillar"`
+							RecommendationIDs []string `json:"recommendationIds"`
+						} `json:"properties"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "2.1.0", got.Version)
+	require.Len(t, got.Runs, 1)
+	run := got.Runs[0]
+	assert.Equal(t, "wafcheck", run.Tool.Driver.Name)
+	assert.Equal(t, "1.2.3", run.Tool.Driver.Version)
+
+	// The rule of an issue is described even if it is not in the list of rules.
+	require.Len(t, run.Tool.Driver.Rules, 3)
+	assert.Equal(t, "tls", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "https://example.com/tls", run.Tool.Driver.Rules[0].HelpURI)
+	assert.Equal(t, "Old TLS is insecure.", run.Tool.Driver.Rules[0].ShortDescription.Text)
+	assert.Equal(t, "Security", run.Tool.Driver.Rules[0].Properties.Pillar)
+	assert.Equal(t, []string{"SE:07"}, run.Tool.Driver.Rules[0].Properties.RecommendationIDs)
+	assert.Equal(t, "plain", run.Tool.Driver.Rules[2].ID)
+
+	require.Len(t, run.Results, 3)
+	assert.Equal(t, "tls", run.Results[1].RuleID)
+	assert.Equal(t, 0, run.Results[1].RuleIndex)
+	assert.Equal(t, "error", run.Results[1].Level)
+	assert.Equal(t, "main.tf", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 20, run.Results[1].Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, 2, run.Results[2].RuleIndex)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, []tflint.Rule{tlsRule, zrsRule, plainRule}, testIssues(), nil))
+
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 4, got.Tests)
+	assert.Equal(t, 3, got.Failures)
+
+	var names []string
+	for _, s := range got.Suites {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"Reliability", "Security", "Other"}, names)
+
+	reliability := got.Suites[0]
+	require.Len(t, reliability.Cases, 1)
+	assert.Equal(t, "zrs", reliability.Cases[0].Name)
+	assert.Nil(t, reliability.Cases[0].Failure)
+
+	security := got.Suites[1]
+	assert.Equal(t, 2, security.Failures)
+	require.Len(t, security.Cases, 2)
+	assert.Equal(t, "main.tf:5", security.Cases[0].Name)
+	assert.Equal(t, "tls", security.Cases[0].Classname)
+	require.NotNil(t, security.Cases[0].Failure)
+	assert.Equal(t, "tls one", security.Cases[0].Failure.Message)
+	assert.Equal(t, "error", security.Cases[0].Failure.Type)
+	assert.Contains(t, security.Cases[0].Properties.Properties, junitProperty{Name: "recommendation_ids", Value: "SE:07"})
+}
+
+func TestWriteJUnitMergesIssuesAtLocation(t *testing.T) {
+	issues := testIssues()
+	issues = append(issues, check.Issue{Rule: tlsRule, Message: "tls three", Range: issues[0].Range})
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, nil, issues, nil))
+
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 3, got.Failures)
+	assert.Equal(t, "tls one\ntls three", got.Suites[0].Cases[0].Failure.Message)
+}
+
+func TestWriteJUnitChecks(t *testing.T) {
+	issues := []check.Issue{
+		{Rule: tlsRule, Message: tlsRule.Metadata().(*waf.Metadata).IssueMessage("tls one"), Range: testIssues()[0].Range},
+	}
+	checks := []score.Check{
+		{Rule: tlsRule, Address: "azapi_resource.sa", Passed: false, Range: testIssues()[0].Range},
+		{Rule: tlsRule, Address: "azapi_resource.other", Passed: true},
+		{Rule: tlsRule, Address: "azapi_resource.other", Passed: true},
+		{Rule: zrsRule, Address: "azapi_resource.sa", Passed: true},
+		{Rule: zrsRule, Address: "azapi_resource.other", Passed: true},
+		{Rule: zrsRule, Address: "azapi_resource.other", Passed: false},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, []tflint.Rule{tlsRule, zrsRule, plainRule}, issues, checks))
+
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 4, got.Tests)
+	assert.Equal(t, 1, got.Failures)
+
+	cases := func(s junitTestSuite) []string {
+		var names []string
+		for _, tc := range s.Cases {
+			names = append(names, tc.Classname+" "+tc.Name)
+		}
+		return names
+	}
+	require.Len(t, got.Suites, 3)
+	assert.Equal(t, []string{"zrs azapi_resource.sa"}, cases(got.Suites[0]), "a resource with a failed check does not pass")
+	assert.Equal(t, []string{"tls azapi_resource.sa", "tls azapi_resource.other"}, cases(got.Suites[1]), "a rule with a failure has passing cases")
+	assert.Equal(t, []string{"plain plain"}, cases(got.Suites[2]))
+	assert.Equal(t, "tls one", got.Suites[1].Cases[0].Failure.Message, "the metadata is only in the properties")
+	assert.Contains(t, got.Suites[1].Cases[0].Properties.Properties, junitProperty{Name: "remediation", Value: "Use TLS1_2."})
+}
+
+func TestWriteJUnitInstances(t *testing.T) {
+	rng := testIssues()[0].Range
+	issues := []check.Issue{
+		{Rule: tlsRule, Message: "first", Range: rng},
+		{Rule: tlsRule, Message: "second", Range: rng},
+		{Rule: zrsRule, Message: "`azapi_resource.sa[\"b\"]` is not zone redundant, unlike `azapi_resource.sa[\"a\"]`", Range: rng},
+		{Rule: plainRule, Message: "plain", Range: testIssues()[1].Range},
+	}
+	checks := []score.Check{
+		{Rule: tlsRule, Address: "azapi_resource.sa[0]", Passed: false, Range: rng},
+		{Rule: tlsRule, Address: "azapi_resource.sa[1]", Passed: false, Range: rng},
+		{Rule: zrsRule, Address: `azapi_resource.sa["a"]`, Passed: false, Range: rng},
+		{Rule: zrsRule, Address: `azapi_resource.sa["b"]`, Passed: false, Range: rng},
+		{Rule: plainRule, Address: "azapi_resource.other", Passed: false, Range: rng},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, nil, issues, checks))
+
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	var names []string
+	for _, s := range got.Suites {
+		for _, tc := range s.Cases {
+			names = append(names, tc.Classname+" "+tc.Name)
+		}
+	}
+	assert.Equal(t, []string{
+		`zrs azapi_resource.sa["b"]`,
+		"tls azapi_resource.sa[0]",
+		"tls azapi_resource.sa[1]",
+		"plain main.tf:20",
+	}, names, "issues are named after the failed check at their range, or their location")
+}
+
+func TestWriteMessagesWithoutMetadata(t *testing.T) {
+	issues := []check.Issue{
+		{Rule: tlsRule, Message: tlsRule.Metadata().(*waf.Metadata).IssueMessage("tls one"), Range: testIssues()[0].Range},
+	}
+	var jsonBuf bytes.Buffer
+	require.NoError(t, WriteJSON(&jsonBuf, issues))
+	var report JSONReport
+	require.NoError(t, json.Unmarshal(jsonBuf.Bytes(), &report))
+	assert.Equal(t, "tls one", report.Issues[0].Message)
+	assert.Equal(t, "Use TLS1_2.", report.Issues[0].Remediation)
+
+	var sarifBuf bytes.Buffer
+	require.NoError(t, WriteSARIF(&sarifBuf, Tool{Name: "wafcheck"}, nil, issues))
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(sarifBuf.Bytes(), &log))
+	assert.Equal(t, "tls one", log.Runs[0].Results[0].Message.Text)
+}
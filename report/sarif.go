@@ -0,0 +1,188 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/check"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string          `json:"name"`
+	Version        string          `json:"version,omitempty"`
+	InformationURI string          `json:"informationUri,omitempty"`
+	Rules          []sarifRuleDesc `json:"rules"`
+}
+
+type sarifRuleDesc struct {
+	ID                   string          `json:"id"`
+	ShortDescription     *sarifMessage   `json:"shortDescription,omitempty"`
+	FullDescription      *sarifMessage   `json:"fullDescription,omitempty"`
+	HelpURI              string          `json:"helpUri,omitempty"`
+	Help                 *sarifMessage   `json:"help,omitempty"`
+	DefaultConfiguration sarifRuleConfig `json:"defaultConfiguration"`
+	Properties           sarifProperties `json:"properties"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+// sarifProperties is the property bag of rule descriptors and results with the Well-Architected metadata.
+type sarifProperties struct {
+	Pillar            string   `json:"pillar,omitempty"`
+	RecommendationIDs []string `json:"recommendationIds,omitempty"`
+	Remediation       string   `json:"remediation,omitempty"`
+	Tags              []string `json:"tags,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations"`
+	Properties sarifProperties `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// WriteSARIF writes the issues as a SARIF 2.1.0 log with a single run.
+// The enabled rules and the rules of the issues are described in the tool's rule descriptors,
+// with the rule's link as the help URI and the Well-Architected metadata in the property bag.
+func WriteSARIF(w io.Writer, tool Tool, rules []tflint.Rule, issues []check.Issue) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           tool.Name,
+			Version:        tool.Version,
+			InformationURI: tool.URI,
+			Rules:          []sarifRuleDesc{},
+		}},
+		Results: make([]sarifResult, 0, len(issues)),
+	}
+	index := make(map[string]int)
+	addRule := func(rule tflint.Rule) int {
+		if i, ok := index[rule.Name()]; ok {
+			return i
+		}
+		index[rule.Name()] = len(run.Tool.Driver.Rules)
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, newSarifRuleDesc(rule))
+		return index[rule.Name()]
+	}
+	for _, rule := range rules {
+		addRule(rule)
+	}
+	for _, issue := range issues {
+		r := issue.Range
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    issue.Rule.Name(),
+			RuleIndex: addRule(issue.Rule),
+			Level:     sarifLevel(issue.Rule.Severity()),
+			Message:   sarifMessage{Text: messageWithoutMetadata(issue.Rule, issue.Message)},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(r.Filename)},
+					Region: sarifRegion{
+						StartLine:   r.Start.Line,
+						StartColumn: r.Start.Column,
+						EndLine:     r.End.Line,
+						EndColumn:   r.End.Column,
+					},
+				},
+			}},
+			Properties: newSarifProperties(issue.Rule),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+func newSarifRuleDesc(rule tflint.Rule) sarifRuleDesc {
+	md := metadata(rule)
+	desc := sarifRuleDesc{
+		ID:                   rule.Name(),
+		HelpURI:              rule.Link(),
+		DefaultConfiguration: sarifRuleConfig{Level: sarifLevel(rule.Severity())},
+		Properties:           newSarifProperties(rule),
+	}
+	if md.Rationale != "" {
+		desc.ShortDescription = &sarifMessage{Text: md.Rationale}
+	}
+	if md.Remediation != "" {
+		desc.Help = &sarifMessage{Text: md.Remediation}
+	}
+	return desc
+}
+
+func newSarifProperties(rule tflint.Rule) sarifProperties {
+	md := metadata(rule)
+	props := sarifProperties{
+		Pillar:            string(md.Pillar),
+		RecommendationIDs: md.RecommendationIDs,
+		Remediation:       md.Remediation,
+	}
+	if md.Pillar != "" {
+		props.Tags = append([]string{string(md.Pillar)}, md.RecommendationIDs...)
+	}
+	return props
+}
+
+// sarifLevel returns the SARIF level of the severity.
+func sarifLevel(s tflint.Severity) string {
+	switch s {
+	case tflint.ERROR:
+		return "error"
+	case tflint.WARNING:
+		return "warning"
+	}
+	return "note"
+}
@@ -0,0 +1,222 @@
+package rules
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/azschema"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/graph"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// DefaultMaxNewerVersions is the number of newer stable API versions after which ApiVersionRule reports a version as superseded.
+const DefaultMaxNewerVersions = 3
+
+// ApiVersionRule checks the API version of each `azapi_resource` against the versions in the bundled schemas.
+// It reports versions that do not exist for the resource type, versions superseded by a number of newer stable versions,
+// and, if configured, versions older than a maximum age when a newer stable version exists.
+// Resource types that are not in the bundle are ignored.
+type ApiVersionRule struct {
+	tflint.DefaultRule
+	ruleName string
+	link     string
+	metadata *waf.Metadata
+	now      func() time.Time
+}
+
+var _ tflint.Rule = &ApiVersionRule{}
+
+// apiVersionRuleConfig is the configuration of the rule in its `rule` block in `.tflint.hcl`.
+type apiVersionRuleConfig struct {
+	MaxNewerVersions int `hclext:"max_newer_versions,optional"` // Report versions with this many newer stable versions, 0 to disable.
+	MaxAgeMonths     int `hclext:"max_age_months,optional"`     // Report versions older than this many months, 0 to disable.
+}
+
+// NewApiVersionRule creates a rule to check for API versions that do not exist or are out of date.
+func NewApiVersionRule(ruleName, link string) *ApiVersionRule {
+	return &ApiVersionRule{
+		ruleName: ruleName,
+		link:     link,
+		now:      time.Now,
+	}
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *ApiVersionRule) WithMetadata(md waf.Metadata) *ApiVersionRule {
+	r.metadata = &md
+	return r
+}
+
+func (r *ApiVersionRule) Metadata() interface{} {
+	return r.metadata
+}
+
+func (r *ApiVersionRule) Link() string {
+	return r.link
+}
+
+func (r *ApiVersionRule) Enabled() bool {
+	return true
+}
+
+func (r *ApiVersionRule) Severity() tflint.Severity {
+	return tflint.WARNING
+}
+
+func (r *ApiVersionRule) Name() string {
+	return r.ruleName
+}
+
+func (r *ApiVersionRule) Check(runner tflint.Runner) error {
+	config := &apiVersionRuleConfig{MaxNewerVersions: DefaultMaxNewerVersions}
+	if err := runner.DecodeRuleConfig(r.Name(), config); err != nil {
+		return fmt.Errorf("could not decode rule config: %w", err)
+	}
+	return forEachApiVersion(r, runner, func(res *graph.Node, versions []string) string {
+		latest := azschema.LatestStable(versions)
+		if !containsVersion(versions, res.ApiVersion) {
+			return r.metadata.IssueMessage(fmt.Sprintf("`%s` uses API version `%s` which does not exist for `%s`%s", res.String(), res.ApiVersion, res.Type, latestSuggestion(latest)))
+		}
+		if latest == "" || azschema.CompareVersions(res.ApiVersion, latest) >= 0 {
+			return ""
+		}
+		newer := 0
+		for _, v := range versions {
+			if !azschema.IsPreview(v) && azschema.CompareVersions(v, res.ApiVersion) > 0 {
+				newer++
+			}
+		}
+		if config.MaxNewerVersions > 0 && newer >= config.MaxNewerVersions {
+			return r.metadata.IssueMessage(fmt.Sprintf("`%s` uses API version `%s` of `%s` which has been superseded by %s%s", res.String(), res.ApiVersion, res.Type, newerVersions(newer), latestSuggestion(latest)))
+		}
+		if config.MaxAgeMonths <= 0 {
+			return ""
+		}
+		date, err := azschema.VersionDate(res.ApiVersion)
+		if err != nil {
+			return ""
+		}
+		if date.AddDate(0, config.MaxAgeMonths, 0).Before(r.now()) {
+			return r.metadata.IssueMessage(fmt.Sprintf("`%s` uses API version `%s` of `%s` which is older than %d months%s", res.String(), res.ApiVersion, res.Type, config.MaxAgeMonths, latestSuggestion(latest)))
+		}
+		return ""
+	})
+}
+
+// PreviewApiVersionRule checks that `azapi_resource` resources do not use preview API versions.
+// Resource types that are not in the bundled schemas are ignored.
+type PreviewApiVersionRule struct {
+	tflint.DefaultRule
+	ruleName string
+	link     string
+	metadata *waf.Metadata
+}
+
+var _ tflint.Rule = &PreviewApiVersionRule{}
+
+// NewPreviewApiVersionRule creates a rule to check for preview API versions.
+func NewPreviewApiVersionRule(ruleName, link string) *PreviewApiVersionRule {
+	return &PreviewApiVersionRule{
+		ruleName: ruleName,
+		link:     link,
+	}
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *PreviewApiVersionRule) WithMetadata(md waf.Metadata) *PreviewApiVersionRule {
+	r.metadata = &md
+	return r
+}
+
+func (r *PreviewApiVersionRule) Metadata() interface{} {
+	return r.metadata
+}
+
+func (r *PreviewApiVersionRule) Link() string {
+	return r.link
+}
+
+func (r *PreviewApiVersionRule) Enabled() bool {
+	return true
+}
+
+func (r *PreviewApiVersionRule) Severity() tflint.Severity {
+	return tflint.WARNING
+}
+
+func (r *PreviewApiVersionRule) Name() string {
+	return r.ruleName
+}
+
+func (r *PreviewApiVersionRule) Check(runner tflint.Runner) error {
+	return forEachApiVersion(r, runner, func(res *graph.Node, versions []string) string {
+		if !azschema.IsPreview(res.ApiVersion) || !containsVersion(versions, res.ApiVersion) {
+			return ""
+		}
+		return r.metadata.IssueMessage(fmt.Sprintf("`%s` uses preview API version `%s` of `%s`%s", res.String(), res.ApiVersion, res.Type, latestSuggestion(azschema.LatestStable(versions))))
+	})
+}
+
+// forEachApiVersion calls check with each resource whose type is in the bundled schemas, and the API versions of its type.
+// If check returns a message it is emitted as an issue of the rule, otherwise the resource passed the check.
+// Resources expanded by `count` or `for_each` are only checked once per API version.
+func forEachApiVersion(rule tflint.Rule, runner tflint.Runner, check func(*graph.Node, []string) string) error {
+	schema, err := azschema.Default()
+	if err != nil {
+		return fmt.Errorf("could not load schemas: %s", err)
+	}
+	g, diags := graph.FromRunner(runner)
+	if diags.HasErrors() {
+		return fmt.Errorf("could not build resource graph: %s", diags)
+	}
+	seen := make(map[string]bool)
+	for _, res := range azapiResources(g.Nodes()) {
+		if seen[res.Address+"@"+res.ApiVersion] {
+			continue
+		}
+		seen[res.Address+"@"+res.ApiVersion] = true
+		versions := schema.APIVersions(res.Type)
+		if len(versions) == 0 {
+			continue
+		}
+		msg := check(res, versions)
+		score.Record(runner, score.Check{
+			Rule:         rule,
+			ResourceType: res.Type,
+			Address:      res.String(),
+			Passed:       msg == "",
+			Range:        res.IssueRange(),
+		})
+		if msg != "" {
+			runner.EmitIssue(rule, msg, res.IssueRange())
+		}
+	}
+	return nil
+}
+
+func containsVersion(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// newerVersions returns the number of newer stable versions for an issue message.
+func newerVersions(n int) string {
+	if n == 1 {
+		return "1 newer stable version"
+	}
+	return fmt.Sprintf("%d newer stable versions", n)
+}
+
+// latestSuggestion returns the suffix of an issue message suggesting the latest stable version, if there is one.
+func latestSuggestion(latest string) string {
+	if latest == "" {
+		return ", there is no known stable version"
+	}
+	return fmt.Sprintf(", the latest known stable version is `%s`", latest)
+}
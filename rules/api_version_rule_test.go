@@ -0,0 +1,203 @@
+package rules
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/azschema"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+)
+
+func TestApiVersionRule(t *testing.T) {
+	rule := NewApiVersionRule("test", "https://example.com")
+	rule.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	testCases := []struct {
+		name     string
+		config   string
+		content  string
+		expected helper.Issues
+	}{
+		{
+			name: "latest version",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name = "sa"
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "version that does not exist",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2099-01-01"
+  name = "sa"
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` uses API version `2099-01-01` which does not exist for `Microsoft.Storage/storageAccounts`, the latest known stable version is `2023-05-01`",
+				},
+			},
+		},
+		{
+			name: "superseded version",
+			content: `
+resource "azapi_resource" "sa" {
+  count = 2
+  type  = "Microsoft.Storage/storageAccounts@2019-06-01"
+  name  = "sa${count.index}"
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa[0]` uses API version `2019-06-01` of `Microsoft.Storage/storageAccounts` which has been superseded by 4 newer stable versions, the latest known stable version is `2023-05-01`",
+				},
+			},
+		},
+		{
+			name: "superseded version with configured maximum",
+			config: `
+rule "test" {
+  enabled            = true
+  max_newer_versions = 2
+}`,
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2022-09-01"
+  name = "sa"
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` uses API version `2022-09-01` of `Microsoft.Storage/storageAccounts` which has been superseded by 2 newer stable versions, the latest known stable version is `2023-05-01`",
+				},
+			},
+		},
+		{
+			name: "older than maximum age",
+			config: `
+rule "test" {
+  enabled        = true
+  max_age_months = 36
+}`,
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2022-09-01"
+  name = "sa"
+}
+
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-07-01"
+  name = "kv"
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` uses API version `2022-09-01` of `Microsoft.Storage/storageAccounts` which is older than 36 months, the latest known stable version is `2023-05-01`",
+				},
+			},
+		},
+		{
+			name: "unknown resource type",
+			content: `
+resource "azapi_resource" "thing" {
+  type = "Microsoft.Unknown/things@2015-01-01"
+  name = "thing"
+}`,
+			expected: helper.Issues{},
+		},
+	}
+
+	for _, c := range testCases {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{"main.tf": tc.content}
+			if tc.config != "" {
+				files[".tflint.hcl"] = tc.config
+			}
+			runner := helper.TestRunner(t, files)
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(tc.content))
+			defer stub.Reset()
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			helper.AssertIssuesWithoutRange(t, tc.expected, runner.Issues)
+		})
+	}
+}
+
+func TestApiVersionRuleReportsMadeUpVersions(t *testing.T) {
+	schema, err := azschema.Default()
+	require.NoError(t, err)
+	rule := NewApiVersionRule("test", "https://example.com")
+	for _, resourceType := range schema.ResourceTypes() {
+		t.Run(resourceType, func(t *testing.T) {
+			versions := schema.APIVersions(resourceType)
+			require.NotEmpty(t, versions)
+			require.NotContains(t, versions, "2019-13-45")
+			content := fmt.Sprintf(`
+resource "azapi_resource" "made_up" {
+  type = "%s@2019-13-45"
+  name = "made_up"
+}`, resourceType)
+			runner := helper.TestRunner(t, map[string]string{"main.tf": content})
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(content))
+			defer stub.Reset()
+			require.NoError(t, rule.Check(runner))
+			require.Len(t, runner.Issues, 1)
+			assert.Contains(t, runner.Issues[0].Message, fmt.Sprintf("uses API version `2019-13-45` which does not exist for `%s`", resourceType))
+		})
+	}
+}
+
+func TestPreviewApiVersionRule(t *testing.T) {
+	rule := NewPreviewApiVersionRule("test", "https://example.com")
+	testCases := []struct {
+		name     string
+		content  string
+		expected helper.Issues
+	}{
+		{
+			name: "stable version",
+			content: `
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-07-01"
+  name = "kv"
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "preview version",
+			content: `
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2021-04-01-preview"
+  name = "kv"
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.kv` uses preview API version `2021-04-01-preview` of `Microsoft.KeyVault/vaults`, the latest known stable version is `2023-07-01`",
+				},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			runner := helper.TestRunner(t, map[string]string{"main.tf": tc.content})
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(tc.content))
+			defer stub.Reset()
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			helper.AssertIssuesWithoutRange(t, tc.expected, runner.Issues)
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
 	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
 	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
 	"github.com/tidwall/gjson"
 	"github.com/zclconf/go-cty/cty"
@@ -16,9 +18,11 @@ type AzApiRule struct {
 	tflint.DefaultRule // Embed the default rule to reuse its implementation
 	blockquery.BlockQuery
 	expected          []gjson.Result
+	fix               cty.Value
 	maximumApiVersion string
 	minimumApiVersion string
 	link              string
+	metadata          *waf.Metadata
 	resourceType      string
 	ruleName          string
 }
@@ -55,6 +59,28 @@ func NewAzApiRule(
 	}
 }
 
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *AzApiRule) WithMetadata(md waf.Metadata) *AzApiRule {
+	r.metadata = &md
+	return r
+}
+
+// WithFix sets the value that `tflint --fix` writes at the query path of the body.
+// The fix is only applied when the body is an object literal in the resource block, and the value satisfies the expected results.
+func (r *AzApiRule) WithFix(val cty.Value) *AzApiRule {
+	r.fix = val
+	return r
+}
+
+// Expected returns the expected results of the rule, which the RunConfig and the rule's `rule` block can replace.
+func (r *AzApiRule) Expected() []gjson.Result {
+	return r.expected
+}
+
+func (r *AzApiRule) Metadata() interface{} {
+	return r.metadata
+}
+
 func (r *AzApiRule) Link() string {
 	return r.link
 }
@@ -87,66 +113,123 @@ func (r *AzApiRule) Attributes() []string {
 	return []string{"name", "type", "body"}
 }
 
+// azApiRuleConfig is the configuration of the rule in its `rule` block in `.tflint.hcl`.
+type azApiRuleConfig struct {
+	Expected cty.Value `hclext:"expected,optional"` // Overrides the expected results, e.g. `expected = ["TLS1_2", "TLS1_3"]`.
+}
+
 func (r *AzApiRule) Check(runner tflint.Runner) error {
-	return r.queryResource(runner, cty.DynamicPseudoType)
+	expected, err := r.expectedResults(runner)
+	if err != nil {
+		return err
+	}
+	return r.queryResource(runner, expected)
+}
+
+// expectedResults returns the expected results of the rule in the run, see decodeExpected.
+func (r *AzApiRule) expectedResults(runner tflint.Runner) ([]gjson.Result, error) {
+	return decodeExpected(runner, r.Name(), r.expected)
 }
 
-func (r *AzApiRule) queryResource(runner tflint.Runner, ct cty.Type) error {
-	ctx, resources, diags := modulecontent.FetchBlocks(r, runner)
-	if diags.HasErrors() {
-		return fmt.Errorf("could not get partial content: %s", diags)
+// decodeExpected returns the expected results from the `rule` block of the rule, or if it has none those of the RunConfig, e.g. from a profile,
+// or else the defaults.
+func decodeExpected(runner tflint.Runner, ruleName string, defaults []gjson.Result) ([]gjson.Result, error) {
+	config := &azApiRuleConfig{}
+	if err := runner.DecodeRuleConfig(ruleName, config); err != nil {
+		return nil, fmt.Errorf("could not decode rule config: %w", err)
+	}
+	if config.Expected == cty.NilVal {
+		return RunConfigOf(runner).expected(ruleName, defaults), nil
+	}
+	results, err := blockquery.NewResultsFromCty(config.Expected)
+	if err != nil {
+		return nil, fmt.Errorf("invalid `expected` in rule config: %w", err)
 	}
-	for _, resource := range resources {
-		typeAttr, typeAttrExists := resource.Body.Attributes["type"]
-		if !typeAttrExists {
+	return results, nil
+}
+
+// queryResource compares the results of the query against each resource with the expected results.
+// The query runs in the pass of the engine of the run, or a pass of its own if the rule is not in it, see engineFor.
+func (r *AzApiRule) queryResource(runner tflint.Runner, expected []gjson.Result) error {
+	results, err := engineFor(runner, r).results(runner, r)
+	if err != nil {
+		return err
+	}
+	for _, res := range results {
+		if res.err != nil {
+			return res.err
+		}
+		resource := res.resource
+		check := score.Check{
+			Rule:         r,
+			ResourceType: r.resourceType,
+			Address:      res.address,
+		}
+		check.Range = resource.DefRange
+		if !res.typed {
+			score.Record(runner, check)
 			runner.EmitIssue(
 				r,
-				"Resource does not have a `type` attribute",
+				r.metadata.IssueMessage("Resource does not have a `type` attribute"),
 				resource.DefRange,
 			)
 			continue
 		}
-		typeVal, diags := ctx.EvaluateExpr(typeAttr.Expr, cty.String)
-		if diags.HasErrors() {
-			return fmt.Errorf("could not evaluate type expression: %s", diags)
-		}
-		typeStr := typeVal.AsString()
-		if !checkAzApiType(typeStr, r.resourceType, r.minimumApiVersion, r.maximumApiVersion) {
-			continue
-		}
-		bodyAttr, bodyAttrExists := resource.Body.Attributes["body"]
-		if !bodyAttrExists {
+		bodyAttr := res.body
+		if bodyAttr == nil {
+			score.Record(runner, check)
 			runner.EmitIssue(
 				r,
-				"Resource does not have a `body` attribute",
+				r.metadata.IssueMessage("Resource does not have a `body` attribute"),
 				resource.DefRange,
 			)
 			continue
 		}
-
-		val, diags := ctx.EvaluateExpr(bodyAttr.Expr, ct)
-		if diags.HasErrors() {
-			return fmt.Errorf("could not evaluate body expression: %s", diags)
-		}
-		qr, err := blockquery.Query(val, ct, r.Query)
-		if err != nil {
-			return fmt.Errorf("could not query value: %s", err)
-		}
-		ok, msg, err := r.CompareFunc(qr, r.expected...)
+		ok, msg, err := r.CompareFunc(res.result, expected...)
 		if err != nil {
 			return fmt.Errorf("could not compare values: %w", err)
 		}
-		if !ok {
+		check.Passed = ok
+		check.Range = bodyAttr.Range
+		score.Record(runner, check)
+		if ok {
+			continue
+		}
+		if !r.fixable(expected) {
 			runner.EmitIssue(
 				r,
-				msg,
+				r.metadata.IssueMessage(msg),
 				bodyAttr.Range,
 			)
+			continue
+		}
+		if err := runner.EmitIssueWithFix(
+			r,
+			r.metadata.IssueMessage(msg),
+			bodyAttr.Range,
+			func(f tflint.Fixer) error {
+				return blockquery.FixObjectValue(f, bodyAttr.Expr, r.Query, r.fix)
+			},
+		); err != nil {
+			return fmt.Errorf("could not fix value: %w", err)
 		}
 	}
 	return nil
 }
 
+// fixable reports whether the rule has a fix value that satisfies the expected results.
+func (r *AzApiRule) fixable(expected []gjson.Result) bool {
+	if r.fix == cty.NilVal {
+		return false
+	}
+	qr, err := blockquery.Query(cty.ObjectVal(map[string]cty.Value{"fix": r.fix}), cty.DynamicPseudoType, "fix")
+	if err != nil {
+		return false
+	}
+	ok, _, err := r.CompareFunc(qr, expected...)
+	return err == nil && ok
+}
+
 func checkAzApiType(gotType, wantType, minimumApiVersion, maximumApiVersion string) bool {
 	gotSplit := strings.Split(gotType, "@")
 	if len(gotSplit) != 2 {
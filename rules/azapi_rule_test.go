@@ -1,15 +1,17 @@
 package rules
 
 import (
-	"os"
 	"testing"
 
 	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
 	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
 	"github.com/prashantv/gostub"
 	"github.com/spf13/afero"
 	"github.com/terraform-linters/tflint-plugin-sdk/helper"
 	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestAzapiRule(t *testing.T) {
@@ -63,6 +65,28 @@ func TestAzapiRule(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "incorrect string with metadata",
+			rule: NewAzApiRule("test", "https://example.com", "testType", "", "", "foo", blockquery.IsOneOf, blockquery.NewStringResults("not_fiz")...).WithMetadata(waf.Metadata{
+				Pillar:            waf.Security,
+				RecommendationIDs: []string{"SE:07"},
+				Remediation:       "Set `foo` to `not_fiz`.",
+			}),
+			content: `
+		resource "azapi_resource" "test" {
+		  type = "testType@0000-00-00"
+		  body = {
+			  foo = "fiz"
+				bar = "biz"
+			}
+		}`,
+			expected: helper.Issues{
+				{
+					Rule:    NewAzApiRule("test", "https://example.com", "testType", "", "", "foo", blockquery.IsOneOf, blockquery.NewStringResults("not_fiz")...),
+					Message: "returned value `fiz` not in expected values `[not_fiz]` [Security SE:07] Remediation: Set `foo` to `not_fiz`.",
+				},
+			},
+		},
 		{
 			name: "string not present but doesn't need to exist",
 			rule: NewAzApiRule("test", "https://example.com", "testType", "", "", "bat", blockquery.IsOneOf, blockquery.NewStringResults()...),
@@ -344,7 +368,188 @@ resource "azapi_resource" "test" {
 }
 
 func mockFs(c string) afero.Afero {
-	fs := afero.NewMemMapFs()
-	_ = afero.WriteFile(fs, "main.tf", []byte(c), os.ModePerm)
-	return afero.Afero{Fs: fs}
+	return ruletest.MemFs(map[string]string{"main.tf": c})
+}
+
+func TestAzapiRuleConfigExpected(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   string
+		expected helper.Issues
+	}{
+		{
+			name: "expected overridden by rule block",
+			config: `
+rule "test" {
+  enabled  = true
+  expected = ["fiz"]
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "rule block without expected",
+			config: `
+rule "test" {
+  enabled = true
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    NewAzApiRule("test", "https://example.com", "testType", "", "", "foo", blockquery.IsOneOf, blockquery.NewStringResults("not_fiz")...),
+					Message: "returned value `fiz` not in expected values `[not_fiz]`",
+				},
+			},
+		},
+	}
+
+	content := `
+resource "azapi_resource" "test" {
+  type = "testType@0000-00-00"
+  body = {
+    foo = "fiz"
+  }
+}`
+	for _, c := range testCases {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			rule := NewAzApiRule("test", "https://example.com", "testType", "", "", "foo", blockquery.IsOneOf, blockquery.NewStringResults("not_fiz")...)
+			runner := helper.TestRunner(t, map[string]string{"main.tf": content, ".tflint.hcl": tc.config})
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(content))
+			defer stub.Reset()
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			helper.AssertIssuesWithoutRange(t, tc.expected, runner.Issues)
+		})
+	}
+}
+
+func TestAzapiRuleFix(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		content  string
+		expected map[string]string
+	}{
+		{
+			name:  "replace literal",
+			query: "properties.minimumTlsVersion",
+			content: `
+resource "azapi_resource" "test" {
+  type = "testType@0000-00-00"
+  body = {
+    properties = {
+      minimumTlsVersion = "TLS1_0"
+    }
+  }
+}`,
+			expected: map[string]string{
+				"main.tf": `
+resource "azapi_resource" "test" {
+  type = "testType@0000-00-00"
+  body = {
+    properties = {
+      minimumTlsVersion = "TLS1_2"
+    }
+  }
+}`,
+			},
+		},
+		{
+			name:  "insert missing value and parent object",
+			query: "properties.minimumTlsVersion",
+			content: `
+resource "azapi_resource" "test" {
+  type = "testType@0000-00-00"
+  body = {
+    kind = "StorageV2"
+  }
+}`,
+			expected: map[string]string{
+				"main.tf": `
+resource "azapi_resource" "test" {
+  type = "testType@0000-00-00"
+  body = {
+    kind = "StorageV2"
+    properties = {
+      minimumTlsVersion = "TLS1_2"
+    }
+  }
+}`,
+			},
+		},
+		{
+			name:  "value is not a literal",
+			query: "properties.minimumTlsVersion",
+			content: `
+variable "tls" {
+  type    = string
+  default = "TLS1_0"
+}
+
+resource "azapi_resource" "test" {
+  type = "testType@0000-00-00"
+  body = {
+    properties = {
+      minimumTlsVersion = var.tls
+    }
+  }
+}`,
+			expected: map[string]string{},
+		},
+		{
+			name:  "body is not an object literal",
+			query: "properties.minimumTlsVersion",
+			content: `
+locals {
+  body = {
+    properties = {
+      minimumTlsVersion = "TLS1_0"
+    }
+  }
+}
+
+resource "azapi_resource" "test" {
+  type = "testType@0000-00-00"
+  body = local.body
+}`,
+			expected: map[string]string{},
+		},
+	}
+
+	for _, c := range testCases {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			rule := NewAzApiRule("test", "https://example.com", "testType", "", "", tc.query, blockquery.IsOneOfAndMustExist, blockquery.NewStringResults("TLS1_2")...).
+				WithFix(cty.StringVal("TLS1_2"))
+			runner := helper.TestRunner(t, map[string]string{"main.tf": tc.content})
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(tc.content))
+			defer stub.Reset()
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("expected 1 issue, got %d", len(runner.Issues))
+			}
+			helper.AssertChanges(t, tc.expected, runner.Changes())
+		})
+	}
+}
+
+func TestAzapiRuleFixNotExpected(t *testing.T) {
+	content := `
+resource "azapi_resource" "test" {
+  type = "testType@0000-00-00"
+  body = {
+    foo = "fiz"
+  }
+}`
+	rule := NewAzApiRule("test", "https://example.com", "testType", "", "", "foo", blockquery.IsOneOf, blockquery.NewStringResults("fuz")...).
+		WithFix(cty.StringVal("fiz"))
+	runner := helper.TestRunner(t, map[string]string{"main.tf": content})
+	stub := gostub.Stub(&modulecontent.AppFs, mockFs(content))
+	defer stub.Reset()
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	helper.AssertChanges(t, map[string]string{}, runner.Changes())
 }
@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/azschema"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/graph"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// AzApiSchemaRule validates the `body` of each `azapi_resource` against the bundled schema of its `type@apiVersion`,
+// reporting unknown properties, values of the wrong type, invalid enum values and missing required properties.
+// Resource types and API versions that are not in the bundle are ignored,
+// as are resources with `schema_validation_enabled = false`.
+type AzApiSchemaRule struct {
+	tflint.DefaultRule
+	ruleName string
+	link     string
+	metadata *waf.Metadata
+}
+
+var _ tflint.Rule = &AzApiSchemaRule{}
+
+// NewAzApiSchemaRule creates a rule to validate resource bodies against the bundled schemas, see azschema.Default.
+func NewAzApiSchemaRule(ruleName, link string) *AzApiSchemaRule {
+	return &AzApiSchemaRule{
+		ruleName: ruleName,
+		link:     link,
+	}
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *AzApiSchemaRule) WithMetadata(md waf.Metadata) *AzApiSchemaRule {
+	r.metadata = &md
+	return r
+}
+
+func (r *AzApiSchemaRule) Metadata() interface{} {
+	return r.metadata
+}
+
+func (r *AzApiSchemaRule) Link() string {
+	return r.link
+}
+
+func (r *AzApiSchemaRule) Enabled() bool {
+	return true
+}
+
+func (r *AzApiSchemaRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+func (r *AzApiSchemaRule) Name() string {
+	return r.ruleName
+}
+
+func (r *AzApiSchemaRule) Check(runner tflint.Runner) error {
+	schema, err := azschema.Default()
+	if err != nil {
+		return fmt.Errorf("could not load schemas: %s", err)
+	}
+	g, diags := graph.FromRunner(runner)
+	if diags.HasErrors() {
+		return fmt.Errorf("could not build resource graph: %s", diags)
+	}
+	for _, res := range azapiResources(g.Nodes()) {
+		if res.Body == cty.NilVal || schemaValidationDisabled(res) {
+			continue
+		}
+		rt, ok := schema.Resource(res.Type, res.ApiVersion)
+		if !ok {
+			continue
+		}
+		violations, err := schema.ValidateResource(rt, res.Body)
+		if err != nil {
+			return fmt.Errorf("could not validate `%s`: %s", res.String(), err)
+		}
+		score.Record(runner, score.Check{
+			Rule:         r,
+			ResourceType: res.Type,
+			Address:      res.String(),
+			Passed:       len(violations) == 0,
+			Range:        res.IssueRange(),
+		})
+		for _, v := range violations {
+			runner.EmitIssue(
+				r,
+				r.metadata.IssueMessage(fmt.Sprintf("`%s` body does not match the schema of `%s@%s`: %s", res.String(), res.Type, res.ApiVersion, v)),
+				res.IssueRange(),
+			)
+		}
+	}
+	return nil
+}
+
+// schemaValidationDisabled reports whether the resource sets `schema_validation_enabled = false`.
+func schemaValidationDisabled(res *graph.Node) bool {
+	attr, ok := res.Block.Body.Attributes["schema_validation_enabled"]
+	if !ok {
+		return false
+	}
+	val, diags := attr.Expr.Value(nil)
+	return !diags.HasErrors() && val.Type() == cty.Bool && val.IsKnown() && val.False()
+}
@@ -0,0 +1,147 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/prashantv/gostub"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+)
+
+func TestAzApiSchemaRule(t *testing.T) {
+	rule := NewAzApiSchemaRule("test", "https://example.com")
+	testCases := []struct {
+		name     string
+		content  string
+		expected helper.Issues
+	}{
+		{
+			name: "valid body",
+			content: `
+resource "azapi_resource" "sa" {
+  type     = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name     = "sa"
+  location = "westeurope"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = "Standard_ZRS"
+    }
+    properties = {
+      minimumTlsVersion = "TLS1_2"
+    }
+  }
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "typo in property name",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = "sa"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = "Standard_ZRS"
+    }
+    properties = {
+      minimumTLSVersion = "TLS1_2"
+    }
+  }
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` body does not match the schema of `Microsoft.Storage/storageAccounts@2023-01-01`: `properties.minimumTLSVersion` is not a valid property, did you mean `minimumTlsVersion`?",
+				},
+			},
+		},
+		{
+			name: "invalid enum and missing required",
+			content: `
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-07-01"
+  name = "kv"
+  body = {
+    properties = {
+      sku = {
+        family = "A"
+        name   = "basic"
+      }
+    }
+  }
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.kv` body does not match the schema of `Microsoft.KeyVault/vaults@2023-07-01`: `properties.sku.name` value `basic` is not one of `standard`, `premium`",
+				},
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.kv` body does not match the schema of `Microsoft.KeyVault/vaults@2023-07-01`: `properties.tenantId` is required",
+				},
+			},
+		},
+		{
+			name: "references are not validated",
+			content: `
+variable "tenant_id" {
+  type = string
+}
+
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-07-01"
+  name = "kv"
+  body = {
+    properties = {
+      tenantId = var.tenant_id
+      sku = {
+        family = "A"
+        name   = "standard"
+      }
+    }
+  }
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "schema validation disabled",
+			content: `
+resource "azapi_resource" "sa" {
+  type                      = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name                      = "sa"
+  schema_validation_enabled = false
+  body = {
+    foo = "bar"
+  }
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "api version not in bundle",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2015-01-01"
+  name = "sa"
+  body = {
+    foo = "bar"
+  }
+}`,
+			expected: helper.Issues{},
+		},
+	}
+
+	for _, c := range testCases {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			runner := helper.TestRunner(t, map[string]string{"main.tf": tc.content})
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(tc.content))
+			defer stub.Reset()
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			helper.AssertIssuesWithoutRange(t, tc.expected, runner.Issues)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// CelRule evaluates a CEL expression against `azapi_resource` resources and emits an issue if it is not true.
+// The expression can use the variables `body`, `name`, `location`, `tags` and `parent_id` of the resource,
+// and `resource`, a map of the same attributes and `type`, which is a reserved word in CEL, e.g. `resource.type`.
+type CelRule struct {
+	tflint.DefaultRule // Embed the default rule to reuse its implementation
+	expression         string
+	program            cel.Program
+	message            string
+	severity           tflint.Severity
+	maximumApiVersion  string
+	minimumApiVersion  string
+	link               string
+	metadata           *waf.Metadata
+	resourceType       string
+	ruleName           string
+}
+
+var _ tflint.Rule = &CelRule{}
+var _ modulecontent.BlockFetcher = &CelRule{}
+
+// celEnv declares the variables of the expressions of CelRule, with optional field selection, e.g. `body.?properties.?publicNetworkAccess`,
+// the string functions of the extension library, e.g. `name.lowerAscii()`, and `cel.bind`.
+var celEnv = func() *cel.Env {
+	env, err := cel.NewEnv(
+		cel.OptionalTypes(),
+		ext.Strings(),
+		ext.Bindings(),
+		cel.Variable("body", cel.DynType),
+		cel.Variable("name", cel.StringType),
+		cel.Variable("location", cel.StringType),
+		cel.Variable("tags", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("parent_id", cel.StringType),
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+	)
+	if err != nil {
+		panic(fmt.Sprintf("could not create CEL environment: %s", err))
+	}
+	return env
+}()
+
+// NewCelRule creates a rule that evaluates a CEL expression against `azapi_resource` resources of the resource type,
+// e.g. `body.properties.networkAcls.defaultAction == 'Deny' || size(body.properties.ipRules) > 0`.
+// The expression is compiled and type checked once, it returns an error if it is invalid or does not evaluate to a bool.
+// The resource type and API versions filter resources as for NewAzApiRule.
+func NewCelRule(ruleName, link, resourceType, minimumApiVersion, maximumApiVersion, expression string) (*CelRule, error) {
+	ast, issues := celEnv.Compile(expression)
+	if issues.Err() != nil {
+		return nil, fmt.Errorf("could not compile CEL expression of rule `%s`: %s", ruleName, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("CEL expression of rule `%s` evaluates to %s, not bool", ruleName, ast.OutputType())
+	}
+	program, err := celEnv.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("could not create CEL program of rule `%s`: %s", ruleName, err)
+	}
+	return &CelRule{
+		expression:        expression,
+		program:           program,
+		severity:          tflint.ERROR,
+		link:              link,
+		maximumApiVersion: maximumApiVersion,
+		minimumApiVersion: minimumApiVersion,
+		resourceType:      resourceType,
+		ruleName:          ruleName,
+	}, nil
+}
+
+// MustCelRule returns the rule, and panics if err is not nil. It is intended for rules created at start-up, e.g.
+//
+//	MustCelRule(NewCelRule(ruleName, link, resourceType, "", "", expression))
+func MustCelRule(r *CelRule, err error) *CelRule {
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *CelRule) WithMetadata(md waf.Metadata) *CelRule {
+	r.metadata = &md
+	return r
+}
+
+// WithSeverity sets the severity of the rule, ERROR by default.
+func (r *CelRule) WithSeverity(severity tflint.Severity) *CelRule {
+	r.severity = severity
+	return r
+}
+
+// WithMessage sets the message of the issues of resources that do not satisfy the expression.
+// By default the message is the expression.
+func (r *CelRule) WithMessage(msg string) *CelRule {
+	r.message = msg
+	return r
+}
+
+// Expression returns the CEL expression of the rule.
+func (r *CelRule) Expression() string {
+	return r.expression
+}
+
+func (r *CelRule) Metadata() interface{} {
+	return r.metadata
+}
+
+func (r *CelRule) Link() string {
+	return r.link
+}
+
+func (r *CelRule) Enabled() bool {
+	return true
+}
+
+func (r *CelRule) Severity() tflint.Severity {
+	return r.severity
+}
+
+func (r *CelRule) Name() string {
+	return r.ruleName
+}
+
+func (r *CelRule) LabelOne() string {
+	return "azapi_resource"
+}
+
+func (r *CelRule) LabelNames() []string {
+	return []string{"type", "name"}
+}
+
+func (r *CelRule) BlockType() string {
+	return "resource"
+}
+
+func (r *CelRule) Attributes() []string {
+	return []string{"name", "type", "body", "location", "tags", "parent_id"}
+}
+
+func (r *CelRule) Check(runner tflint.Runner) error {
+	ctx, resources, instances, diags := modulecontent.FetchBlockInstances(r, runner)
+	if diags.HasErrors() {
+		return fmt.Errorf("could not get partial content: %s", diags)
+	}
+	for _, resource := range resources {
+		typeAttr, typeAttrExists := resource.Body.Attributes["type"]
+		if !typeAttrExists {
+			continue
+		}
+		typeVal, diags := ctx.EvaluateExpr(typeAttr.Expr, cty.String)
+		if diags.HasErrors() {
+			return fmt.Errorf("could not evaluate type expression: %s", diags)
+		}
+		if !typeVal.IsWhollyKnown() || typeVal.IsNull() {
+			continue
+		}
+		typeStr := typeVal.AsString()
+		if !checkAzApiType(typeStr, r.resourceType, r.minimumApiVersion, r.maximumApiVersion) {
+			continue
+		}
+		vars, err := celVariables(ctx, resource, typeStr)
+		if err != nil {
+			return err
+		}
+		out, _, err := r.program.Eval(vars)
+		var msg string
+		switch {
+		case err != nil:
+			msg = fmt.Sprintf("`%s` could not be evaluated: %s", r.expression, err)
+		case out.Value() != true && r.message != "":
+			msg = r.message
+		case out.Value() != true:
+			msg = fmt.Sprintf("`%s` is not true", r.expression)
+		}
+		rng := resource.DefRange
+		if bodyAttr, exists := resource.Body.Attributes["body"]; exists {
+			rng = bodyAttr.Range
+		}
+		score.Record(runner, score.Check{
+			Rule:         r,
+			ResourceType: r.resourceType,
+			Address:      instances[resource].Address(strings.Join(resource.Labels, ".")),
+			Passed:       msg == "",
+			Range:        rng,
+		})
+		if msg == "" {
+			continue
+		}
+		if err := runner.EmitIssue(r, r.metadata.IssueMessage(msg), rng); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// celVariables returns the variables of the resource for the expression, see resourceDocument.
+func celVariables(ctx *terraform.Evaluator, resource *hclext.Block, typeStr string) (map[string]any, error) {
+	attrs, err := resourceDocument(ctx, resource, typeStr)
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]any, len(attrs)+1)
+	for k, v := range attrs {
+		if k != "type" {
+			vars[k] = v
+		}
+	}
+	vars["resource"] = attrs
+	return vars, nil
+}
+
+// resourceDocument returns the `type`, `body`, `name`, `location`, `tags` and `parent_id` of the resource as JSON types.
+// Unknown values are null, and attributes that are not set are empty.
+func resourceDocument(ctx *terraform.Evaluator, resource *hclext.Block, typeStr string) (map[string]any, error) {
+	attrs := map[string]any{
+		"type":      typeStr,
+		"body":      map[string]any{},
+		"name":      "",
+		"location":  "",
+		"tags":      map[string]string{},
+		"parent_id": "",
+	}
+	for _, name := range []string{"body", "name", "location", "tags", "parent_id"} {
+		attr, exists := resource.Body.Attributes[name]
+		if !exists {
+			continue
+		}
+		val, diags := ctx.EvaluateExpr(attr.Expr, cty.DynamicPseudoType)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("could not evaluate %s expression: %s", name, diags)
+		}
+		val = blockquery.UnknownAsNull(val)
+		if val.IsNull() {
+			continue
+		}
+		switch name {
+		case "body":
+			body, err := jsonValue(val)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode body: %s", err)
+			}
+			attrs[name] = body
+		case "tags":
+			attrs[name] = tagsValue(val)
+		default:
+			if val.Type() == cty.String {
+				attrs[name] = val.AsString()
+			}
+		}
+	}
+	return attrs, nil
+}
+
+// jsonValue converts the value to JSON types. A JSON encoded string, e.g. from `jsonencode`, is decoded.
+func jsonValue(val cty.Value) (any, error) {
+	if val.Type() == cty.String {
+		var res any
+		if err := json.Unmarshal([]byte(val.AsString()), &res); err == nil {
+			return res, nil
+		}
+	}
+	src, err := ctyjson.Marshal(val, cty.DynamicPseudoType)
+	if err != nil {
+		return nil, err
+	}
+	var res struct {
+		Value any `json:"value"`
+	}
+	if err := json.Unmarshal(src, &res); err != nil {
+		return nil, err
+	}
+	return res.Value, nil
+}
+
+// tagsValue returns the known string values of a map or object of tags.
+func tagsValue(val cty.Value) map[string]string {
+	tags := map[string]string{}
+	if !val.CanIterateElements() {
+		return tags
+	}
+	for it := val.ElementIterator(); it.Next(); {
+		k, v := it.Element()
+		if k.Type() == cty.String && !v.IsNull() && v.Type() == cty.String {
+			tags[k.AsString()] = v.AsString()
+		}
+	}
+	return tags
+}
@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+)
+
+func TestCelRule(t *testing.T) {
+	content := `
+variable "subnet_id" {
+  type = string
+}
+
+resource "azapi_resource" "test" {
+  type      = "testType@2023-01-01"
+  name      = "test"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  tags = {
+    env = "prod"
+  }
+  body = {
+    properties = {
+      networkAcls = {
+        defaultAction = "Allow"
+        virtualNetworkRules = [
+          {
+            id = var.subnet_id
+          }
+        ]
+      }
+      privateEndpointConnections = []
+    }
+  }
+}
+
+resource "azapi_resource" "other" {
+  type = "otherType@2023-01-01"
+}`
+
+	testCases := []struct {
+		name       string
+		expression string
+		expected   []string
+	}{
+		{
+			name:       "true",
+			expression: "body.properties.networkAcls.defaultAction == 'Allow' && size(body.properties.networkAcls.virtualNetworkRules) == 1",
+		},
+		{
+			name:       "false",
+			expression: "body.properties.networkAcls.defaultAction == 'Deny' || size(body.properties.privateEndpointConnections) > 0",
+			expected:   []string{"`body.properties.networkAcls.defaultAction == 'Deny' || size(body.properties.privateEndpointConnections) > 0` is not true"},
+		},
+		{
+			name:       "resource attributes",
+			expression: "resource.type.startsWith('testType@') && name == 'test' && location == 'westeurope' && tags['env'] == 'prod' && parent_id.endsWith('/rg')",
+		},
+		{
+			name:       "unknown value is null",
+			expression: "body.properties.networkAcls.virtualNetworkRules[0].id == null",
+		},
+		{
+			name:       "missing key",
+			expression: "body.properties.minimumTlsVersion == 'TLS1_2'",
+			expected:   []string{"`body.properties.minimumTlsVersion == 'TLS1_2'` could not be evaluated: no such key: minimumTlsVersion"},
+		},
+		{
+			name:       "optional key",
+			expression: "body.?properties.?minimumTlsVersion.orValue('TLS1_2') == 'TLS1_2'",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := NewCelRule("test", "https://example.com", "testType", "", "", tc.expression)
+			require.NoError(t, err)
+			runner := helper.TestRunner(t, map[string]string{"main.tf": content})
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(content))
+			defer stub.Reset()
+			require.NoError(t, rule.Check(runner))
+			var got []string
+			for _, issue := range runner.Issues {
+				got = append(got, issue.Message)
+			}
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestNewCelRuleInvalid(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expression string
+		wantErr    string
+	}{
+		{
+			name:       "syntax error",
+			expression: "body.properties ==",
+			wantErr:    "could not compile CEL expression of rule `test`: ERROR: <input>:1:19: Syntax error",
+		},
+		{
+			name:       "undeclared variable",
+			expression: "properties.publicNetworkAccess == 'Disabled'",
+			wantErr:    "undeclared reference to 'properties'",
+		},
+		{
+			name:       "type mismatch",
+			expression: "name == 1",
+			wantErr:    "found no matching overload for '_==_' applied to '(string, int)'",
+		},
+		{
+			name:       "not bool",
+			expression: "size(name)",
+			wantErr:    "CEL expression of rule `test` evaluates to int, not bool",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewCelRule("test", "https://example.com", "testType", "", "", tc.expression)
+			assert.ErrorContains(t, err, tc.wantErr)
+			assert.Panics(t, func() {
+				MustCelRule(NewCelRule("test", "https://example.com", "testType", "", "", tc.expression))
+			})
+		})
+	}
+}
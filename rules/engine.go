@@ -0,0 +1,165 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Engine runs the queries of a set of AzApiRule rules in a single pass over the `azapi_resource` resources.
+// Each resource is evaluated once, its body is marshalled to JSON once, and the queries of the rules for its resource type run against the cached document.
+//
+// The pass runs when the first of the rules is checked, and the other rules use its results,
+// so each rule is still checked, and emits its issues, with its own runner.
+// The results are cached by the input values of the runner, as each scenario evaluates the configuration differently.
+type Engine struct {
+	rules  []*AzApiRule
+	byType map[string][]*AzApiRule                 // The rules by lower case resource type.
+	passes map[string]map[*AzApiRule][]azApiResult // The results of each rule by the input values of the pass, see modulecontent.VariablesKey.
+}
+
+var _ modulecontent.BlockFetcher = &Engine{}
+
+// NewEngine returns an engine for the rules.
+// The rules use the engine when they are checked with a runner whose RunConfig has it.
+func NewEngine(rules []*AzApiRule) *Engine {
+	e := &Engine{
+		rules:  rules,
+		byType: make(map[string][]*AzApiRule),
+		passes: make(map[string]map[*AzApiRule][]azApiResult),
+	}
+	for _, r := range rules {
+		t := strings.ToLower(r.resourceType)
+		e.byType[t] = append(e.byType[t], r)
+	}
+	return e
+}
+
+// engineFor returns the engine of the run of the runner if it has the rule, otherwise an engine for the rule alone.
+func engineFor(runner tflint.Runner, rule *AzApiRule) *Engine {
+	if rc := RunConfigOf(runner); rc != nil && rc.Engine != nil {
+		for _, r := range rc.Engine.rules {
+			if r == rule {
+				return rc.Engine
+			}
+		}
+	}
+	return NewEngine([]*AzApiRule{rule})
+}
+
+// Reset drops the cached results, e.g. before the configuration is checked again.
+func (e *Engine) Reset() {
+	e.passes = make(map[string]map[*AzApiRule][]azApiResult)
+}
+
+func (e *Engine) BlockType() string {
+	return "resource"
+}
+
+func (e *Engine) LabelOne() string {
+	return "azapi_resource"
+}
+
+func (e *Engine) LabelNames() []string {
+	return []string{"type", "name"}
+}
+
+func (e *Engine) Attributes() []string {
+	return []string{"name", "type", "body"}
+}
+
+// azApiResult is the result of the query of a rule against a resource, which the rule compares with its expected results when it is checked.
+type azApiResult struct {
+	resource *hclext.Block
+	address  string            // The address of the resource instance, e.g. `azapi_resource.sa[0]`.
+	typed    bool              // Whether the resource has a `type` attribute, otherwise there is no query result.
+	body     *hclext.Attribute // The `body` attribute, nil if the resource does not have one.
+	result   gjson.Result
+	err      error // The error evaluating or marshalling the body, returned when the rule is checked.
+}
+
+// azApiDocument is a resource whose body is evaluated and marshalled on the first query.
+// Unknown values in the body, e.g. the unknown values of a plan, are queried as null.
+type azApiDocument struct {
+	body      *hclext.Attribute
+	evaluated bool
+	json      []byte
+	err       error
+}
+
+func (d *azApiDocument) query(ctx *terraform.Evaluator, query string) (gjson.Result, error) {
+	if !d.evaluated {
+		d.evaluated = true
+		val, diags := ctx.EvaluateExpr(d.body.Expr, cty.DynamicPseudoType)
+		if diags.HasErrors() {
+			d.err = fmt.Errorf("could not evaluate body expression: %s", diags)
+		} else if d.json, d.err = blockquery.Marshal(blockquery.UnknownAsNull(val), cty.DynamicPseudoType); d.err != nil {
+			d.err = fmt.Errorf("could not query value: %s", d.err)
+		}
+	}
+	if d.err != nil {
+		return gjson.Result{}, d.err
+	}
+	return blockquery.QueryDocument(d.json, query), nil
+}
+
+// results returns the results of the rule, running the pass if there are no cached results for the input values of the runner.
+func (e *Engine) results(runner tflint.Runner, rule *AzApiRule) ([]azApiResult, error) {
+	key := modulecontent.VariablesKey(runner)
+	pass, ok := e.passes[key]
+	if !ok {
+		var err error
+		if pass, err = e.pass(runner); err != nil {
+			return nil, err
+		}
+		e.passes[key] = pass
+	}
+	return pass[rule], nil
+}
+
+// pass evaluates each resource once and runs the queries of the rules for its resource type and API version.
+func (e *Engine) pass(runner tflint.Runner) (map[*AzApiRule][]azApiResult, error) {
+	ctx, resources, instances, diags := modulecontent.FetchBlockInstances(e, runner)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("could not get partial content: %s", diags)
+	}
+	pass := make(map[*AzApiRule][]azApiResult, len(e.rules))
+	for _, resource := range resources {
+		address := instances[resource].Address(strings.Join(resource.Labels, "."))
+		typeAttr, typeAttrExists := resource.Body.Attributes["type"]
+		if !typeAttrExists {
+			for _, r := range e.rules {
+				pass[r] = append(pass[r], azApiResult{resource: resource, address: address})
+			}
+			continue
+		}
+		typeVal, diags := ctx.EvaluateExpr(typeAttr.Expr, cty.String)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("could not evaluate type expression: %s", diags)
+		}
+		if !typeVal.IsWhollyKnown() || typeVal.IsNull() {
+			continue
+		}
+		typeStr := typeVal.AsString()
+		resourceType, _, _ := strings.Cut(typeStr, "@")
+		doc := &azApiDocument{body: resource.Body.Attributes["body"]}
+		for _, r := range e.byType[strings.ToLower(resourceType)] {
+			if !checkAzApiType(typeStr, r.resourceType, r.minimumApiVersion, r.maximumApiVersion) {
+				continue
+			}
+			res := azApiResult{resource: resource, address: address, typed: true, body: doc.body}
+			if doc.body != nil {
+				res.result, res.err = doc.query(ctx, r.Query)
+			}
+			pass[r] = append(pass[r], res)
+		}
+	}
+	return pass, nil
+}
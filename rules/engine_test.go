@@ -0,0 +1,220 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+const engineConfig = `
+variable "tls" {
+  type    = string
+  default = "TLS1_2"
+}
+
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  body = {
+    properties = {
+      minimumTlsVersion = var.tls
+    }
+  }
+}
+
+resource "azapi_resource" "sa_old" {
+  type = "Microsoft.Storage/storageAccounts@2021-01-01"
+  body = {
+    properties = {
+      minimumTlsVersion = "TLS1_0"
+    }
+  }
+}
+
+resource "azapi_resource" "kv" {
+  type = "microsoft.keyvault/vaults@2023-07-01"
+}
+
+resource "azapi_resource" "untyped" {
+  body = {}
+}
+`
+
+func engineRules() []*AzApiRule {
+	return []*AzApiRule{
+		NewAzApiRule("tls", "", "Microsoft.Storage/storageAccounts", "", "", "properties.minimumTlsVersion", blockquery.IsOneOf, blockquery.NewStringResults("TLS1_2")...),
+		NewAzApiRule("tls_new", "", "Microsoft.Storage/storageAccounts", "2022-01-01", "", "properties.minimumTlsVersion", blockquery.IsOneOf, blockquery.NewStringResults("TLS1_2")...),
+		NewAzApiRule("purge", "", "Microsoft.KeyVault/vaults", "", "", "properties.enablePurgeProtection", blockquery.IsOneOf, blockquery.NewTrueResult()),
+	}
+}
+
+func TestEngine(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, mockFs(engineConfig))
+	defer stub.Reset()
+
+	check := func(rules []*AzApiRule, runner tflint.Runner) {
+		for _, rule := range rules {
+			require.NoError(t, rule.Check(runner))
+		}
+	}
+	issues := func(runner *helper.Runner) string {
+		var sb strings.Builder
+		for _, issue := range runner.Issues {
+			fmt.Fprintf(&sb, "%s: %s: %s\n", issue.Rule.Name(), issue.Range, issue.Message)
+		}
+		return sb.String()
+	}
+
+	rules := engineRules()
+	runner := helper.TestRunner(t, map[string]string{"main.tf": engineConfig})
+	check(rules, runner)
+	want := issues(runner)
+	assert.Equal(t, "tls: main.tf:18,3-22,4: returned value `TLS1_0` not in expected values `[TLS1_2]`\n"+
+		"tls: main.tf:29,1-36: Resource does not have a `type` attribute\n"+
+		"tls_new: main.tf:29,1-36: Resource does not have a `type` attribute\n"+
+		"purge: main.tf:25,1-31: Resource does not have a `body` attribute\n"+
+		"purge: main.tf:29,1-36: Resource does not have a `type` attribute\n",
+		want)
+
+	e := NewEngine(rules)
+	config := &RunConfig{Engine: e}
+	runner = helper.TestRunner(t, map[string]string{"main.tf": engineConfig})
+	check(rules, &runConfigRunner{Runner: runner, config: config})
+	assert.Equal(t, want, issues(runner), "the engine emits the same issues as the rules")
+	assert.Len(t, e.passes, 1, "the rules share a pass")
+
+	runner = helper.TestRunner(t, map[string]string{"main.tf": engineConfig})
+	check(rules, runner)
+	assert.Len(t, e.passes, 1, "the rules only use the engine of the run")
+
+	runner = helper.TestRunner(t, map[string]string{"main.tf": engineConfig})
+	check(rules, &runConfigRunner{Runner: modulecontent.WithVariables(runner, modulecontent.Variables{Values: []string{"tls=TLS1_1"}}), config: config})
+	assert.Len(t, e.passes, 2, "each set of input values has its own pass")
+	assert.Contains(t, issues(runner), "tls_new: main.tf:9,3-13,4: returned value `TLS1_1` not in expected values `[TLS1_2]`")
+
+	e.Reset()
+	assert.Empty(t, e.passes)
+}
+
+// runConfigRunner provides the configuration of the run, and the input values of the underlying runner.
+type runConfigRunner struct {
+	tflint.Runner
+	config *RunConfig
+}
+
+func (r *runConfigRunner) RunConfig() *RunConfig {
+	return r.config
+}
+
+func (r *runConfigRunner) Variables() modulecontent.Variables {
+	if vr, ok := r.Runner.(modulecontent.VariablesRunner); ok {
+		return vr.Variables()
+	}
+	return modulecontent.Variables{}
+}
+
+// benchmarkConfig returns a configuration with the number of resources of each resource type,
+// and rules that each query a different path of the body of one of the resource types.
+func benchmarkConfig(resourceTypes, resources, rules int) (string, []*AzApiRule) {
+	var sb strings.Builder
+	for t := 0; t < resourceTypes; t++ {
+		for i := 0; i < resources; i++ {
+			fmt.Fprintf(&sb, "resource \"azapi_resource\" \"r%d_%d\" {\n  type = \"Microsoft.Bench/type%d@2023-01-01\"\n  body = {\n    properties = {\n", t, i, t)
+			for p := 0; p < rules/resourceTypes; p++ {
+				fmt.Fprintf(&sb, "      p%d = \"%d\"\n", p, (i+p)%2)
+			}
+			sb.WriteString("    }\n  }\n}\n\n")
+		}
+	}
+	rs := make([]*AzApiRule, 0, rules)
+	for i := 0; i < rules; i++ {
+		t := i % resourceTypes
+		rs = append(rs, NewAzApiRule(
+			fmt.Sprintf("bench_%d", i), "",
+			fmt.Sprintf("Microsoft.Bench/type%d", t), "", "",
+			fmt.Sprintf("properties.p%d", i/resourceTypes),
+			blockquery.IsOneOf,
+			blockquery.NewStringResults("0")...,
+		))
+	}
+	return sb.String(), rs
+}
+
+// benchRunner is a runner for benchmarks, as helper.TestRunner needs a *testing.T. It counts the emitted issues.
+type benchRunner struct {
+	tflint.Runner
+	config *RunConfig
+	issues int
+}
+
+func (r *benchRunner) RunConfig() *RunConfig {
+	return r.config
+}
+
+func (r *benchRunner) GetOriginalwd() (string, error) {
+	return os.Getwd()
+}
+
+func (r *benchRunner) DecodeRuleConfig(string, interface{}) error {
+	return nil
+}
+
+func (r *benchRunner) EmitIssue(tflint.Rule, string, hcl.Range) error {
+	r.issues++
+	return nil
+}
+
+func (r *benchRunner) EmitIssueWithFix(tflint.Rule, string, hcl.Range, func(f tflint.Fixer) error) error {
+	r.issues++
+	return nil
+}
+
+// BenchmarkEngine compares checking each rule with a pass of its own to checking the rules with an engine.
+//
+//	go test ./rules -run '^$' -bench BenchmarkEngine
+func BenchmarkEngine(b *testing.B) {
+	for _, size := range []struct {
+		resourceTypes, resources, rules int
+	}{
+		{10, 10, 50},
+		{10, 30, 200},
+	} {
+		config, rules := benchmarkConfig(size.resourceTypes, size.resources, size.rules)
+		stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{"main.tf": config}))
+		name := fmt.Sprintf("%d_resources_%d_rules", size.resourceTypes*size.resources, size.rules)
+
+		b.Run(name+"/per_rule", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				runner := &benchRunner{}
+				for _, r := range rules {
+					if err := r.Check(runner); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(name+"/engine", func(b *testing.B) {
+			config := &RunConfig{Engine: NewEngine(rules)}
+			for i := 0; i < b.N; i++ {
+				config.Engine.Reset()
+				runner := &benchRunner{config: config}
+				for _, r := range rules {
+					if err := r.Check(runner); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		stub.Reset()
+	}
+}
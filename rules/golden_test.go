@@ -0,0 +1,22 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+)
+
+func TestRulesGolden(t *testing.T) {
+	for _, rule := range Rules {
+		dir := filepath.Join("testdata", rule.Name())
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("rule %s has no test cases in %s", rule.Name(), dir)
+			continue
+		}
+		t.Run(rule.Name(), func(t *testing.T) {
+			ruletest.Run(t, rule, dir)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ModuleInputRule runs the specified gjson query on an input argument of the `module` blocks that call a module from the given source,
+// e.g. an Azure Verified Module, and checks if the result is as expected.
+// An argument that is not set is checked as missing, the default of the module's variable is not known to the rule.
+type ModuleInputRule struct {
+	tflint.DefaultRule
+	blockquery.BlockQuery
+	expected          []gjson.Result
+	link              string
+	metadata          *waf.Metadata
+	ruleName          string
+	source            string
+	versionConstraint version.Constraints
+}
+
+var _ tflint.Rule = &ModuleInputRule{}
+var _ modulecontent.BlockFetcher = &ModuleInputRule{}
+var _ modulecontent.BlockSelector = &ModuleInputRule{}
+
+// NewModuleInputRule creates a rule to check an input argument of the calls to a registry module.
+// The `source` parameter is the registry address of the module, e.g. `Azure/avm-res-keyvault-vault/azurerm`.
+// The `versionConstraint` parameter restricts the rule to versions of the module, e.g. `>= 0.5.0`, an empty constraint matches all versions.
+// The version of a module call is the lowest version allowed by its `version` argument, e.g. `0.5.0` for `~> 0.5`.
+// It panics if the version constraint is invalid.
+// The `input` parameter is the name of the input argument, and the `query` parameter a gjson query to run against its value,
+// or empty to check the value itself.
+func NewModuleInputRule(
+	ruleName, link, source, versionConstraint, input, query string,
+	compareFunc blockquery.ResultCompareFunc,
+	expectedResults ...gjson.Result,
+) *ModuleInputRule {
+	var constraint version.Constraints
+	if versionConstraint != "" {
+		constraint = version.MustConstraints(version.NewConstraint(versionConstraint))
+	}
+	return &ModuleInputRule{
+		BlockQuery: blockquery.NewBlockQuery(
+			"module",
+			"",
+			[]string{"name"},
+			input,
+			query,
+			compareFunc,
+		),
+		expected:          expectedResults,
+		link:              link,
+		ruleName:          ruleName,
+		source:            normalizeModuleSource(source),
+		versionConstraint: constraint,
+	}
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *ModuleInputRule) WithMetadata(md waf.Metadata) *ModuleInputRule {
+	r.metadata = &md
+	return r
+}
+
+// Expected returns the expected results of the rule, which the RunConfig and the rule's `rule` block can replace.
+func (r *ModuleInputRule) Expected() []gjson.Result {
+	return r.expected
+}
+
+func (r *ModuleInputRule) Metadata() interface{} {
+	return r.metadata
+}
+
+func (r *ModuleInputRule) Link() string {
+	return r.link
+}
+
+func (r *ModuleInputRule) Enabled() bool {
+	return true
+}
+
+func (r *ModuleInputRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+func (r *ModuleInputRule) Name() string {
+	return r.ruleName
+}
+
+func (r *ModuleInputRule) LabelOne() string {
+	return ""
+}
+
+func (r *ModuleInputRule) LabelNames() []string {
+	return []string{"name"}
+}
+
+func (r *ModuleInputRule) BlockType() string {
+	return "module"
+}
+
+func (r *ModuleInputRule) Attributes() []string {
+	return []string{"source", "version", r.QueryAttribute}
+}
+
+// Selector selects the module blocks whose `source` is the rule's registry address, with or without the public registry host.
+func (r *ModuleInputRule) Selector() modulecontent.Predicate {
+	return modulecontent.AttributeMatches("source", regexp.MustCompile(`(?i)^\s*(registry\.terraform\.io/)?`+regexp.QuoteMeta(r.source)+`\s*$`))
+}
+
+func (r *ModuleInputRule) Check(runner tflint.Runner) error {
+	expected, err := decodeExpected(runner, r.Name(), r.expected)
+	if err != nil {
+		return err
+	}
+	ctx, modules, diags := modulecontent.FetchBlocks(r, runner)
+	if diags.HasErrors() {
+		return fmt.Errorf("could not get partial content: %s", diags)
+	}
+	query := r.QueryPath()
+	for _, module := range modules {
+		ok, err := r.matchesVersion(ctx, module.Body.Attributes)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		val := cty.EmptyObjectVal
+		issueRange := module.DefRange
+		if inputAttr, exists := module.Body.Attributes[r.QueryAttribute]; exists {
+			inputVal, diags := ctx.EvaluateExpr(inputAttr.Expr, cty.DynamicPseudoType)
+			if diags.HasErrors() {
+				return fmt.Errorf("could not evaluate `%s` expression: %s", r.QueryAttribute, diags)
+			}
+			if !inputVal.IsKnown() {
+				continue
+			}
+			val = cty.ObjectVal(map[string]cty.Value{r.QueryAttribute: blockquery.UnknownAsNull(inputVal)})
+			issueRange = inputAttr.Range
+		}
+		qr, err := blockquery.Query(val, cty.DynamicPseudoType, query)
+		if err != nil {
+			return fmt.Errorf("could not query value: %s", err)
+		}
+		ok, msg, err := r.CompareFunc(qr, expected...)
+		if err != nil {
+			return fmt.Errorf("could not compare values: %w", err)
+		}
+		score.Record(runner, score.Check{
+			Rule:         r,
+			ResourceType: r.source,
+			Address:      "module." + module.Labels[0],
+			Passed:       ok,
+			Range:        issueRange,
+		})
+		if ok {
+			continue
+		}
+		runner.EmitIssue(
+			r,
+			r.metadata.IssueMessage(fmt.Sprintf("`%s`: %s", query, msg)),
+			issueRange,
+		)
+	}
+	return nil
+}
+
+// matchesVersion reports whether the `version` argument of a module block matches the rule's version constraint.
+// A module call whose version is not known matches any version constraint.
+func (r *ModuleInputRule) matchesVersion(ctx *terraform.Evaluator, attrs hclext.Attributes) (bool, error) {
+	versionAttr, ok := attrs["version"]
+	if !ok || r.versionConstraint == nil {
+		return true, nil
+	}
+	versionVal, diags := ctx.EvaluateExpr(versionAttr.Expr, cty.String)
+	if diags.HasErrors() {
+		return false, fmt.Errorf("could not evaluate version expression: %s", diags)
+	}
+	if !versionVal.IsKnown() || versionVal.IsNull() {
+		return true, nil
+	}
+	v := lowestVersion(versionVal.AsString())
+	if v == nil {
+		return true, nil
+	}
+	return r.versionConstraint.Check(v), nil
+}
+
+// normalizeModuleSource returns the registry address in lower case without the public registry host,
+// e.g. `azure/avm-res-keyvault-vault/azurerm` for `registry.terraform.io/Azure/avm-res-keyvault-vault/azurerm`.
+// It is the resource type of the rule's checks when scoring.
+func normalizeModuleSource(source string) string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	return strings.TrimPrefix(source, "registry.terraform.io/")
+}
+
+// lowestVersion returns the lowest release allowed by a version constraint, e.g. `0.5.0` for `~> 0.5` or `>= 0.5.0, < 1.0.0`,
+// and `0.5.1` for `> 0.5.0`, or nil if the constraint has no lower bound.
+// Parts that are not valid version constraints, such as `latest`, are skipped.
+func lowestVersion(constraint string) *version.Version {
+	var lowest *version.Version
+	for _, c := range strings.Split(constraint, ",") {
+		c = strings.TrimSpace(c)
+		var op string
+		for _, prefix := range []string{"~>", ">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(c, prefix) {
+				op = prefix
+				break
+			}
+		}
+		if op == "<=" || op == "<" || op == "!=" {
+			continue
+		}
+		v, err := version.NewVersion(strings.TrimSpace(strings.TrimPrefix(c, op)))
+		if err != nil {
+			continue
+		}
+		if op == ">" {
+			v = nextRelease(v)
+		}
+		// Every part of the constraint must hold, so the highest lower bound is the lowest version allowed.
+		if lowest == nil || v.GreaterThan(lowest) {
+			lowest = v
+		}
+	}
+	return lowest
+}
+
+// nextRelease returns the lowest release greater than the version,
+// which is the version without its pre-release for a pre-release, e.g. `0.5.0` for `0.5.0-beta`, otherwise the next patch.
+func nextRelease(v *version.Version) *version.Version {
+	core := v.Core()
+	if v.Prerelease() != "" {
+		return core
+	}
+	segments := core.Segments64()
+	return version.Must(version.NewVersion(fmt.Sprintf("%d.%d.%d", segments[0], segments[1], segments[2]+1)))
+}
@@ -0,0 +1,122 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+)
+
+func TestModuleInputRule(t *testing.T) {
+	const content = `
+module "old" {
+  source  = "Azure/avm-res-keyvault-vault/azurerm"
+  version = "~> 0.4"
+
+  sku_name = "standard"
+}
+
+module "new" {
+  source  = "Azure/avm-res-keyvault-vault/azurerm"
+  version = ">= 0.9.0, < 1.0.0"
+
+  sku_name = "standard"
+}
+
+module "git" {
+  source = "git::https://github.com/Azure/terraform-azurerm-avm-res-keyvault-vault.git"
+
+  sku_name = "standard"
+}
+
+module "exclusive" {
+  source  = "Azure/avm-res-keyvault-vault/azurerm"
+  version = "> 0.4.0, latest"
+
+  sku_name = "standard"
+}
+`
+	testCases := []struct {
+		name     string
+		source   string
+		version  string
+		expected []string
+	}{
+		{
+			name:     "all versions",
+			source:   "Azure/avm-res-keyvault-vault/azurerm",
+			expected: []string{"old", "new", "exclusive"},
+		},
+		{
+			name:     "version constraint",
+			source:   "azure/avm-res-keyvault-vault/azurerm",
+			version:  ">= 0.5.0",
+			expected: []string{"new"},
+		},
+		{
+			name:     "exclusive lower bound",
+			source:   "Azure/avm-res-keyvault-vault/azurerm",
+			version:  ">= 0.4.1",
+			expected: []string{"new", "exclusive"},
+		},
+		{
+			name:   "other source",
+			source: "Azure/avm-res-storage-storageaccount/azurerm",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := NewModuleInputRule("test", "https://example.com", tc.source, tc.version, "sku_name", "", blockquery.IsOneOf, blockquery.NewStringResults("premium")...)
+			runner := helper.TestRunner(t, map[string]string{"main.tf": content})
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(content))
+			defer stub.Reset()
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Unexpected error occurred: %s", err)
+			}
+			var got []string
+			for _, issue := range runner.Issues {
+				assert.Equal(t, "`sku_name`: returned value `standard` not in expected values `[premium]`", issue.Message)
+				got = append(got, issue.Range.String())
+			}
+			var want []string
+			for _, name := range tc.expected {
+				want = append(want, map[string]string{"old": "main.tf:6,3-24", "new": "main.tf:13,3-24", "exclusive": "main.tf:26,3-24"}[name])
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestLowestVersion(t *testing.T) {
+	testCases := []struct {
+		constraint string
+		want       string
+	}{
+		{constraint: "0.9.1", want: "0.9.1"},
+		{constraint: "= 0.9.1", want: "0.9.1"},
+		{constraint: "~> 0.5", want: "0.5.0"},
+		{constraint: ">= 0.9.0, < 1.0.0", want: "0.9.0"},
+		{constraint: "> 0.3.0, != 0.4.0, >= 0.2.0", want: "0.3.1"},
+		{constraint: "> 0.5.0", want: "0.5.1"},
+		{constraint: "> 0.5", want: "0.5.1"},
+		{constraint: "> 0.5.0-beta", want: "0.5.0"},
+		{constraint: ">= 0.5.0-rc.1, < 1.0.0", want: "0.5.0-rc.1"},
+		{constraint: "latest, >= 0.4.0", want: "0.4.0"},
+		{constraint: "~> 0.5, >= 0.6.2", want: "0.6.2"},
+		{constraint: "< 1.0.0"},
+		{constraint: "latest"},
+	}
+	for _, tc := range testCases {
+		got := lowestVersion(tc.constraint)
+		if tc.want == "" {
+			assert.Nil(t, got, tc.constraint)
+			continue
+		}
+		if assert.NotNil(t, got, tc.constraint) {
+			assert.Equal(t, tc.want, got.String(), tc.constraint)
+		}
+	}
+}
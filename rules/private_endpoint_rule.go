@@ -0,0 +1,228 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/graph"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// PrivateEndpointTarget is a resource type that must be connected to a private endpoint when public network access is disabled.
+type PrivateEndpointTarget struct {
+	ResourceType string   // The resource type, e.g. `Microsoft.Storage/storageAccounts`.
+	GroupIDs     []string // The private link group IDs that must be connected, e.g. `blob`.
+}
+
+// DefaultPrivateEndpointTargets are the PaaS services checked by NewPrivateEndpointRule.
+var DefaultPrivateEndpointTargets = []PrivateEndpointTarget{
+	{ResourceType: "Microsoft.Storage/storageAccounts", GroupIDs: []string{"blob"}},
+	{ResourceType: "Microsoft.KeyVault/vaults", GroupIDs: []string{"vault"}},
+	{ResourceType: "Microsoft.Sql/servers", GroupIDs: []string{"sqlServer"}},
+	{ResourceType: "Microsoft.DocumentDB/databaseAccounts", GroupIDs: []string{"Sql"}},
+}
+
+const privateEndpointResourceType = "Microsoft.Network/privateEndpoints"
+
+// PrivateEndpointRule checks that PaaS services with `publicNetworkAccess` disabled
+// have a `Microsoft.Network/privateEndpoints` resource connected to them.
+// Private endpoints are matched to their target by the resource ID in `privateLinkServiceConnections[].properties.privateLinkServiceId`,
+// or if it is not known, by a reference to the target resource in its expression.
+type PrivateEndpointRule struct {
+	tflint.DefaultRule
+	ruleName string
+	link     string
+	metadata *waf.Metadata
+	targets  []PrivateEndpointTarget
+}
+
+var _ tflint.Rule = &PrivateEndpointRule{}
+
+// NewPrivateEndpointRule creates a rule to check that the given targets are connected to a private endpoint.
+// If no targets are supplied then DefaultPrivateEndpointTargets is used.
+func NewPrivateEndpointRule(ruleName, link string, targets ...PrivateEndpointTarget) *PrivateEndpointRule {
+	if len(targets) == 0 {
+		targets = DefaultPrivateEndpointTargets
+	}
+	return &PrivateEndpointRule{
+		ruleName: ruleName,
+		link:     link,
+		targets:  targets,
+	}
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *PrivateEndpointRule) WithMetadata(md waf.Metadata) *PrivateEndpointRule {
+	r.metadata = &md
+	return r
+}
+
+func (r *PrivateEndpointRule) Metadata() interface{} {
+	return r.metadata
+}
+
+func (r *PrivateEndpointRule) Link() string {
+	return r.link
+}
+
+func (r *PrivateEndpointRule) Enabled() bool {
+	return true
+}
+
+func (r *PrivateEndpointRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+func (r *PrivateEndpointRule) Name() string {
+	return r.ruleName
+}
+
+func (r *PrivateEndpointRule) Check(runner tflint.Runner) error {
+	instances, diags := modulecontent.ModuleInstances(runner)
+	if diags.HasErrors() {
+		return fmt.Errorf("could not load module instances: %s", diags)
+	}
+	// Only the resources of the root module are checked.
+	g, diags := graph.Build(instances[:1])
+	if diags.HasErrors() {
+		return fmt.Errorf("could not build resource graph: %s", diags)
+	}
+	endpoints := azapiResources(g.OfType(privateEndpointResourceType))
+	for _, target := range r.targets {
+		for _, res := range azapiResources(g.OfType(target.ResourceType)) {
+			pna, err := res.Query("properties.publicNetworkAccess")
+			if err != nil {
+				return fmt.Errorf("could not query value: %s", err)
+			}
+			if !strings.EqualFold(pna.String(), "Disabled") {
+				continue
+			}
+			connected, groupIDs, err := connectedGroupIDs(g, res, endpoints)
+			if err != nil {
+				return err
+			}
+			missing := missingGroupIDs(target.GroupIDs, groupIDs)
+			check := score.Check{
+				Rule:         r,
+				ResourceType: res.Type,
+				Address:      res.String(),
+				Range:        res.Block.DefRange,
+			}
+			var msg string
+			switch {
+			case !connected:
+				msg = fmt.Sprintf(
+					"`%s` has public network access disabled but is not the target of a private endpoint, missing group IDs: %s",
+					res.Address,
+					strings.Join(missing, ", "),
+				)
+			case len(missing) > 0:
+				msg = fmt.Sprintf(
+					"`%s` has public network access disabled but its private endpoints do not connect group IDs: %s",
+					res.Address,
+					strings.Join(missing, ", "),
+				)
+			default:
+				check.Passed = true
+				score.Record(runner, check)
+				continue
+			}
+			score.Record(runner, check)
+			runner.EmitIssue(r, r.metadata.IssueMessage(msg), res.Block.DefRange)
+		}
+	}
+	return nil
+}
+
+// connectedGroupIDs returns whether any private endpoint connects to the target resource,
+// and the group IDs of those connections.
+func connectedGroupIDs(g *graph.Graph, target *graph.Node, endpoints []*graph.Node) (bool, []string, error) {
+	connected := false
+	groupIDs := make([]string, 0)
+	for _, pe := range endpoints {
+		conns, err := pe.Query("properties.privateLinkServiceConnections")
+		if err != nil {
+			return false, nil, fmt.Errorf("could not query value: %s", err)
+		}
+		for i, conn := range conns.Array() {
+			if !connectionTargets(g, pe, i, conn.Get("properties.privateLinkServiceId").Value(), target) {
+				continue
+			}
+			connected = true
+			for _, g := range conn.Get("properties.groupIds").Array() {
+				groupIDs = append(groupIDs, g.String())
+			}
+		}
+	}
+	return connected, groupIDs, nil
+}
+
+// connectionTargets reports whether the private link service ID of the i-th private endpoint connection refers to the target.
+// If the ID is a known string it is matched against the graph, otherwise the expression of the ID must reference the target.
+func connectionTargets(g *graph.Graph, pe *graph.Node, i int, serviceID any, target *graph.Node) bool {
+	id, ok := serviceID.(string)
+	if !ok {
+		expr := connectionServiceIDExpr(pe, i)
+		return expr != nil && pe.ExprReferencesNode(expr, target)
+	}
+	for _, res := range g.ByResourceID(id) {
+		if res == target {
+			return true
+		}
+	}
+	return false
+}
+
+// connectionServiceIDExpr returns the expression of the private link service ID of the i-th private endpoint connection in the `body` attribute,
+// or nil if the body has no such key.
+// If the connections, or the connection, are not literals in the body, e.g. a variable or a `for` expression,
+// the expression of the connections, or the connection, is returned as the ID is computed from it.
+func connectionServiceIDExpr(pe *graph.Node, i int) hcl.Expression {
+	body, ok := pe.Block.Body.Attributes["body"]
+	if !ok {
+		return nil
+	}
+	expr, keys, ok := blockquery.ObjectValueExpr(body.Expr, "properties.privateLinkServiceConnections")
+	if !ok || len(keys) > 0 {
+		return expr
+	}
+	tuple, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return expr
+	}
+	if i >= len(tuple.Exprs) {
+		return nil
+	}
+	expr, _, ok = blockquery.ObjectValueExpr(tuple.Exprs[i], "properties.privateLinkServiceId")
+	if !ok {
+		return nil
+	}
+	return expr
+}
+
+// missingGroupIDs returns the sorted list of wanted group IDs that are not in got.
+// Group IDs are compared case insensitively.
+func missingGroupIDs(want, got []string) []string {
+	missing := make([]string, 0, len(want))
+	for _, w := range want {
+		found := false
+		for _, g := range got {
+			if strings.EqualFold(w, g) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, w)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
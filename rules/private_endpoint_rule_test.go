@@ -0,0 +1,345 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/prashantv/gostub"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+)
+
+func TestPrivateEndpointRule(t *testing.T) {
+	rule := NewPrivateEndpointRule("test", "https://example.com")
+	testCases := []struct {
+		name     string
+		content  string
+		expected helper.Issues
+	}{
+		{
+			name: "private endpoint by reference",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = "sa"
+  body = {
+    properties = {
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+
+resource "azapi_resource" "pe" {
+  type = "Microsoft.Network/privateEndpoints@2023-04-01"
+  name = "pe"
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "blob"
+          properties = {
+            privateLinkServiceId = azapi_resource.sa.id
+            groupIds             = ["blob"]
+          }
+        }
+      ]
+    }
+  }
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "private endpoint by resource id",
+			content: `
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-02-01"
+  name = "kv1"
+  body = {
+    properties = {
+      publicNetworkAccess = "disabled"
+    }
+  }
+}
+
+resource "azapi_resource" "pe" {
+  type = "Microsoft.Network/privateEndpoints@2023-04-01"
+  name = "pe"
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "vault"
+          properties = {
+            privateLinkServiceId = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv1"
+            groupIds             = ["vault"]
+          }
+        }
+      ]
+    }
+  }
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "public network access enabled",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = "sa"
+  body = {
+    properties = {
+      publicNetworkAccess = "Enabled"
+    }
+  }
+}`,
+			expected: helper.Issues{},
+		},
+		{
+			name: "no private endpoint",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = "sa"
+  body = {
+    properties = {
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` has public network access disabled but is not the target of a private endpoint, missing group IDs: blob",
+				},
+			},
+		},
+		{
+			name: "private endpoint for another resource",
+			content: `
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-02-01"
+  name = "kv1"
+  body = {
+    properties = {
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+
+resource "azapi_resource" "pe" {
+  type = "Microsoft.Network/privateEndpoints@2023-04-01"
+  name = "pe"
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "vault"
+          properties = {
+            privateLinkServiceId = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv2"
+            groupIds             = ["vault"]
+          }
+        }
+      ]
+    }
+  }
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.kv` has public network access disabled but is not the target of a private endpoint, missing group IDs: vault",
+				},
+			},
+		},
+		{
+			name: "private endpoint with missing group id",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = "sa"
+  body = {
+    properties = {
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+
+resource "azapi_resource" "pe" {
+  type = "Microsoft.Network/privateEndpoints@2023-04-01"
+  name = "pe"
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "file"
+          properties = {
+            privateLinkServiceId = azapi_resource.sa.id
+            groupIds             = ["file"]
+          }
+        }
+      ]
+    }
+  }
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` has public network access disabled but its private endpoints do not connect group IDs: blob",
+				},
+			},
+		},
+		{
+			name: "private endpoint referencing the resource outside of the connection",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = "sa"
+  body = {
+    properties = {
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-02-01"
+  name = "kv"
+  body = {
+    properties = {
+      publicNetworkAccess = "Enabled"
+    }
+  }
+}
+
+resource "azapi_resource" "pe" {
+  type      = "Microsoft.Network/privateEndpoints@2023-04-01"
+  name      = "pe-${azapi_resource.sa.name}"
+  parent_id = azapi_resource.sa.parent_id
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "blob"
+          properties = {
+            privateLinkServiceId = azapi_resource.kv.id
+            groupIds             = ["blob"]
+          }
+        }
+      ]
+    }
+  }
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` has public network access disabled but is not the target of a private endpoint, missing group IDs: blob",
+				},
+			},
+		},
+		{
+			name: "private endpoint connections to other resources",
+			content: `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = "sa"
+  body = {
+    properties = {
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+
+resource "azapi_resource" "other" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name = "other"
+  body = {
+    properties = {
+      publicNetworkAccess = "Enabled"
+    }
+  }
+}
+
+resource "azapi_resource" "pe" {
+  type = "Microsoft.Network/privateEndpoints@2023-04-01"
+  name = "pe"
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "blob"
+          properties = {
+            privateLinkServiceId = azapi_resource.other.id
+            groupIds             = ["blob"]
+          }
+        },
+        {
+          name = "file"
+          properties = {
+            privateLinkServiceId = azapi_resource.sa.id
+            groupIds             = ["file"]
+          }
+        }
+      ]
+    }
+  }
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` has public network access disabled but its private endpoints do not connect group IDs: blob",
+				},
+			},
+		},
+		{
+			name: "private endpoints of instances",
+			content: `
+resource "azapi_resource" "sa" {
+  for_each = toset(["a", "b"])
+  type     = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name     = each.key
+  body = {
+    properties = {
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+
+resource "azapi_resource" "pe" {
+  for_each = toset(["a"])
+  type     = "Microsoft.Network/privateEndpoints@2023-04-01"
+  name     = "pe-${each.key}"
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "blob"
+          properties = {
+            privateLinkServiceId = azapi_resource.sa[each.key].id
+            groupIds             = ["blob"]
+          }
+        }
+      ]
+    }
+  }
+}`,
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "`azapi_resource.sa` has public network access disabled but is not the target of a private endpoint, missing group IDs: blob",
+				},
+			},
+		},
+	}
+
+	filename := "main.tf"
+	for _, c := range testCases {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			runner := helper.TestRunner(t, map[string]string{filename: tc.content})
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(tc.content))
+			defer stub.Reset()
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			helper.AssertIssuesWithoutRange(t, tc.expected, runner.Issues)
+		})
+	}
+}
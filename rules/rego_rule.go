@@ -0,0 +1,300 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// RegoRule evaluates the `deny` rule of a Rego package against each `azapi_resource`, and emits an issue at the resource for each result.
+//
+// The input document of the policy has the `address`, `type`, `api_version`, `name`, `location`, `body`, `tags` and `parent_id` of the resource,
+// where the `address` includes the instance key, the `type` has no API version, and the `body` is decoded if it is JSON encoded.
+// A result is a message, or an object with the message in `msg` and optional `pillar`, `recommendation_ids`, `remediation` and `severity`:
+//
+//	package wellarchitected.storage
+//
+//	import rego.v1
+//
+//	deny contains {"msg": "Storage accounts must deny public blob access", "pillar": "security", "severity": "warning"} if {
+//	  input.type == "Microsoft.Storage/storageAccounts"
+//	  input.body.properties.allowBlobPublicAccess != false
+//	}
+type RegoRule struct {
+	tflint.DefaultRule // Embed the default rule to reuse its implementation
+	ruleName           string
+	query              rego.PreparedEvalQuery
+}
+
+var _ tflint.Rule = &RegoRule{}
+var _ modulecontent.BlockFetcher = &RegoRule{}
+
+// regoRuleNameReplacer replaces the characters of a package path that are not valid in a rule name.
+var regoRuleNameReplacer = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// LoadRegoRules reads the Rego modules in the directory from modulecontent.AppFs, and returns a rule for each package with a `deny` rule.
+// The rule of a package is named after its path, e.g. `rego_wellarchitected_storage` for `package wellarchitected.storage`.
+// Test modules, which end with `_test.rego`, are skipped. It returns an error if a module cannot be parsed or compiled.
+func LoadRegoRules(dir string) ([]*RegoRule, error) {
+	infos, err := modulecontent.AppFs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read Rego policy directory: %s", err)
+	}
+	modules := map[string]*ast.Module{}
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || filepath.Ext(name) != ".rego" || strings.HasSuffix(name, "_test.rego") {
+			continue
+		}
+		filename := filepath.Join(dir, name)
+		src, err := modulecontent.AppFs.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not read Rego policy: %s", err)
+		}
+		module, err := ast.ParseModule(filename, string(src))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse Rego policy: %s", err)
+		}
+		modules[filename] = module
+	}
+	compiler := ast.NewCompiler()
+	if compiler.Compile(modules); compiler.Failed() {
+		return nil, fmt.Errorf("could not compile Rego policies: %s", compiler.Errors)
+	}
+
+	packages := map[string]ast.Ref{}
+	names := map[string]string{}
+	for _, module := range modules {
+		path := module.Package.Path.String()
+		for _, rule := range module.Rules {
+			if rule.Head.Ref().String() != "deny" {
+				continue
+			}
+			name := regoRuleName(module.Package.Path)
+			if other, exists := names[name]; exists && other != path {
+				return nil, fmt.Errorf("Rego packages `%s` and `%s` have the same rule name `%s`", other, path, name)
+			}
+			names[name] = path
+			packages[path] = module.Package.Path
+			break
+		}
+	}
+	rules := make([]*RegoRule, 0, len(packages))
+	for path, ref := range packages {
+		query, err := rego.New(
+			rego.Query(path+".deny"),
+			rego.Compiler(compiler),
+		).PrepareForEval(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("could not prepare Rego policy `%s`: %s", path, err)
+		}
+		rules = append(rules, &RegoRule{
+			ruleName: regoRuleName(ref),
+			query:    query,
+		})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ruleName < rules[j].ruleName
+	})
+	return rules, nil
+}
+
+// regoRuleName returns the name of the rule of the package, e.g. `rego_wellarchitected_storage` for `data.wellarchitected.storage`.
+func regoRuleName(path ast.Ref) string {
+	parts := make([]string, 0, len(path))
+	for _, term := range path[1:] {
+		s, ok := term.Value.(ast.String)
+		if !ok {
+			continue
+		}
+		parts = append(parts, strings.Trim(regoRuleNameReplacer.ReplaceAllString(strings.ToLower(string(s)), "_"), "_"))
+	}
+	return "rego_" + strings.Join(parts, "_")
+}
+
+func (r *RegoRule) Enabled() bool {
+	return true
+}
+
+func (r *RegoRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+func (r *RegoRule) Name() string {
+	return r.ruleName
+}
+
+func (r *RegoRule) LabelOne() string {
+	return "azapi_resource"
+}
+
+func (r *RegoRule) LabelNames() []string {
+	return []string{"type", "name"}
+}
+
+func (r *RegoRule) BlockType() string {
+	return "resource"
+}
+
+func (r *RegoRule) Attributes() []string {
+	return []string{"name", "type", "body", "location", "tags", "parent_id"}
+}
+
+func (r *RegoRule) Check(runner tflint.Runner) error {
+	ctx, resources, instances, diags := modulecontent.FetchBlockInstances(r, runner)
+	if diags.HasErrors() {
+		return fmt.Errorf("could not get partial content: %s", diags)
+	}
+	for _, resource := range resources {
+		typeAttr, typeAttrExists := resource.Body.Attributes["type"]
+		if !typeAttrExists {
+			continue
+		}
+		typeVal, diags := ctx.EvaluateExpr(typeAttr.Expr, cty.String)
+		if diags.HasErrors() {
+			return fmt.Errorf("could not evaluate type expression: %s", diags)
+		}
+		if !typeVal.IsWhollyKnown() || typeVal.IsNull() {
+			continue
+		}
+		typeSplit := strings.Split(typeVal.AsString(), "@")
+		if len(typeSplit) != 2 {
+			continue
+		}
+		input, err := resourceDocument(ctx, resource, typeSplit[0])
+		if err != nil {
+			return err
+		}
+		input["api_version"] = typeSplit[1]
+		address := instances[resource].Address(strings.Join(resource.Labels, "."))
+		input["address"] = address
+		rs, err := r.query.Eval(context.Background(), rego.EvalInput(input))
+		if err != nil {
+			return fmt.Errorf("could not evaluate Rego policy of rule `%s`: %s", r.ruleName, err)
+		}
+		passed := true
+		for _, result := range rs {
+			for _, expr := range result.Expressions {
+				if denials, ok := expr.Value.([]interface{}); ok && len(denials) > 0 {
+					passed = false
+				}
+			}
+		}
+		score.Record(runner, score.Check{
+			Rule:         r,
+			ResourceType: typeSplit[0],
+			Address:      address,
+			Passed:       passed,
+			Range:        resource.DefRange,
+		})
+		for _, result := range rs {
+			for _, expr := range result.Expressions {
+				denials, ok := expr.Value.([]interface{})
+				if !ok {
+					return fmt.Errorf("`deny` of rule `%s` is not a set", r.ruleName)
+				}
+				for _, denial := range denials {
+					issue, err := r.regoIssue(denial)
+					if err != nil {
+						return err
+					}
+					if err := runner.EmitIssue(issue, issue.metadata.IssueMessage(issue.message), resource.DefRange); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// regoIssueRule is the rule of an issue from a `deny` result, with the severity and metadata of the result.
+type regoIssueRule struct {
+	*RegoRule
+	message  string
+	severity tflint.Severity
+	metadata *waf.Metadata
+}
+
+func (r *regoIssueRule) Severity() tflint.Severity {
+	return r.severity
+}
+
+func (r *regoIssueRule) Metadata() interface{} {
+	return r.metadata
+}
+
+// regoIssue returns the issue of a `deny` result, which is a message or an object with the message in `msg`.
+func (r *RegoRule) regoIssue(denial interface{}) (*regoIssueRule, error) {
+	issue := &regoIssueRule{RegoRule: r, severity: r.Severity()}
+	if msg, ok := denial.(string); ok {
+		issue.message = msg
+		return issue, nil
+	}
+	obj, ok := denial.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("`deny` result of rule `%s` is not a string or an object", r.ruleName)
+	}
+	fields := map[string]string{}
+	for _, key := range []string{"msg", "pillar", "remediation", "severity"} {
+		if v, exists := obj[key]; exists {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("`%s` of `deny` result of rule `%s` is not a string", key, r.ruleName)
+			}
+			fields[key] = s
+		}
+	}
+	if issue.message = fields["msg"]; issue.message == "" {
+		return nil, fmt.Errorf("`deny` result of rule `%s` has no `msg`", r.ruleName)
+	}
+	if s := fields["severity"]; s != "" {
+		severity, err := parseSeverity(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid `severity` of `deny` result of rule `%s`: %s", r.ruleName, err)
+		}
+		issue.severity = severity
+	}
+	md := &waf.Metadata{Remediation: fields["remediation"]}
+	if s := fields["pillar"]; s != "" {
+		pillar, err := waf.ParsePillar(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid `pillar` of `deny` result of rule `%s`: %s", r.ruleName, err)
+		}
+		md.Pillar = pillar
+	}
+	ids, _ := obj["recommendation_ids"].([]interface{})
+	for _, id := range ids {
+		if s, ok := id.(string); ok {
+			md.RecommendationIDs = append(md.RecommendationIDs, s)
+		}
+	}
+	if md.Pillar != "" || md.Remediation != "" || len(md.RecommendationIDs) > 0 {
+		issue.metadata = md
+	}
+	return issue, nil
+}
+
+// parseSeverity parses `error`, `warning` or `notice`, in any case.
+func parseSeverity(s string) (tflint.Severity, error) {
+	switch strings.ToLower(s) {
+	case "error":
+		return tflint.ERROR, nil
+	case "warning":
+		return tflint.WARNING, nil
+	case "notice":
+		return tflint.NOTICE, nil
+	}
+	return tflint.ERROR, fmt.Errorf("unknown severity `%s`, must be `error`, `warning` or `notice`", s)
+}
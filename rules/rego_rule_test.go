@@ -0,0 +1,269 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+const regoConfig = `
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  tags = {
+    env = "prod"
+  }
+  body = jsonencode({
+    properties = {
+      allowBlobPublicAccess = true
+      minimumTlsVersion     = "TLS1_2"
+    }
+  })
+}
+
+resource "azapi_resource" "kv" {
+  type = "Microsoft.KeyVault/vaults@2023-07-01"
+  name = "kv"
+  body = {
+    properties = {}
+  }
+}
+`
+
+const regoStoragePolicy = `
+package wellarchitected.storage
+
+import rego.v1
+
+deny contains {
+  "msg": sprintf("%s allows public blob access", [input.address]),
+  "pillar": "security",
+  "recommendation_ids": ["SE:06"],
+  "remediation": "Set ` + "`properties.allowBlobPublicAccess`" + ` to ` + "`false`" + `.",
+  "severity": "warning",
+} if {
+  input.type == "Microsoft.Storage/storageAccounts"
+  input.body.properties.allowBlobPublicAccess
+}
+
+deny contains "TLS 1.3 is required in production" if {
+  input.type == "Microsoft.Storage/storageAccounts"
+  input.api_version >= "2023-01-01"
+  input.tags.env == "prod"
+  input.body.properties.minimumTlsVersion != "TLS1_3"
+}
+`
+
+const regoKeyVaultPolicy = `
+package wellarchitected["key-vault"]
+
+deny[msg] {
+  input.type == "Microsoft.KeyVault/vaults"
+  not input.body.properties.enablePurgeProtection
+  msg := sprintf("%s does not have purge protection", [input.name])
+}
+`
+
+func TestRegoRules(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		"main.tf":                   regoConfig,
+		"policy/storage.rego":       regoStoragePolicy,
+		"policy/keyvault.rego":      regoKeyVaultPolicy,
+		"policy/storage_test.rego":  "package wellarchitected.storage_test\n\ndeny[msg] { msg := \"test\" }\n",
+		"policy/lib/helpers.rego":   "package lib\n\ndeny[msg] { msg := \"nested\" }\n",
+		"policy/README.md":          "Policies",
+		"other/not_loaded.rego":     "package other\n\ndeny[msg] { msg := \"other\" }\n",
+		"policy/no_deny/empty.rego": "package empty\n",
+		"policy/allow.rego":         "package allow\n\nallow := true\n",
+	}))
+	defer stub.Reset()
+
+	rules, err := LoadRegoRules("policy")
+	require.NoError(t, err)
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name())
+	}
+	require.Equal(t, []string{"rego_wellarchitected_key_vault", "rego_wellarchitected_storage"}, names)
+
+	type issue struct {
+		rule     string
+		severity tflint.Severity
+		metadata *waf.Metadata
+		message  string
+		line     int
+	}
+	var got []issue
+	for _, rule := range rules {
+		runner := helper.TestRunner(t, map[string]string{"main.tf": regoConfig})
+		require.NoError(t, rule.Check(runner))
+		for _, i := range runner.Issues {
+			got = append(got, issue{i.Rule.Name(), i.Rule.Severity(), waf.FromRule(i.Rule), i.Message, i.Range.Start.Line})
+		}
+	}
+	assert.ElementsMatch(t, []issue{
+		{
+			rule:     "rego_wellarchitected_key_vault",
+			severity: tflint.ERROR,
+			message:  "kv does not have purge protection",
+			line:     18,
+		},
+		{
+			rule:     "rego_wellarchitected_storage",
+			severity: tflint.WARNING,
+			metadata: &waf.Metadata{Pillar: waf.Security, RecommendationIDs: []string{"SE:06"}, Remediation: "Set `properties.allowBlobPublicAccess` to `false`."},
+			message:  "azapi_resource.sa allows public blob access [Security SE:06] Remediation: Set `properties.allowBlobPublicAccess` to `false`.",
+			line:     2,
+		},
+		{
+			rule:     "rego_wellarchitected_storage",
+			severity: tflint.ERROR,
+			message:  "TLS 1.3 is required in production",
+			line:     2,
+		},
+	}, got)
+}
+
+func TestRegoRulesInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policies map[string]string
+		wantErr  string
+	}{
+		{
+			name:     "parse error",
+			policies: map[string]string{"policy/bad.rego": "package bad\n\ndeny contains msg if {"},
+			wantErr:  "could not parse Rego policy",
+		},
+		{
+			name:     "compile error",
+			policies: map[string]string{"policy/bad.rego": "package bad\n\nimport rego.v1\n\ndeny contains msg if { msg := unknown_function(input) }"},
+			wantErr:  "could not compile Rego policies",
+		},
+		{
+			name: "same rule name",
+			policies: map[string]string{
+				"policy/a.rego": "package waf.key_vault\n\ndeny[msg] { msg := \"a\" }\n",
+				"policy/b.rego": "package waf[\"key-vault\"]\n\ndeny[msg] { msg := \"b\" }\n",
+			},
+			wantErr: "have the same rule name `rego_waf_key_vault`",
+		},
+		{
+			name:    "missing directory",
+			wantErr: "could not read Rego policy directory",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(tc.policies))
+			defer stub.Reset()
+			_, err := LoadRegoRules("policy")
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestRegoRuleRecordsInstanceChecks(t *testing.T) {
+	const policy = `
+package wellarchitected.tls
+
+import rego.v1
+
+deny contains {
+  "msg": sprintf("%s does not require TLS 1.2", [input.address]),
+  "recommendation_ids": ["SE:07"],
+  "remediation": "Set the minimum TLS version to TLS 1.2.",
+} if {
+  input.body.properties.minimumTlsVersion != "TLS1_2"
+}
+`
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		"main.tf":         instanceTestConfig,
+		"policy/tls.rego": policy,
+	}))
+	defer stub.Reset()
+	rules, err := LoadRegoRules("policy")
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+
+	runner := &recordingRunner{Runner: helper.TestRunner(t, map[string]string{"main.tf": instanceTestConfig})}
+	require.NoError(t, rules[0].Check(runner))
+	type result struct {
+		address string
+		passed  bool
+	}
+	var got []result
+	for _, c := range runner.checks {
+		assert.Equal(t, rules[0], c.Rule)
+		assert.Equal(t, storageAccountResourceType, c.ResourceType)
+		got = append(got, result{c.Address, c.Passed})
+	}
+	assert.Equal(t, []result{
+		{"azapi_resource.counted[0]", true},
+		{"azapi_resource.counted[1]", false},
+		{`azapi_resource.each["a"]`, false},
+		{`azapi_resource.each["b"]`, true},
+	}, got)
+
+	var messages []string
+	for _, i := range runner.Issues {
+		messages = append(messages, i.Message)
+		assert.Equal(t, &waf.Metadata{RecommendationIDs: []string{"SE:07"}, Remediation: "Set the minimum TLS version to TLS 1.2."}, waf.FromRule(i.Rule))
+	}
+	assert.Equal(t, []string{
+		"azapi_resource.counted[1] does not require TLS 1.2",
+		`azapi_resource.each["a"] does not require TLS 1.2`,
+	}, messages)
+}
+
+func TestRegoRuleInvalidResult(t *testing.T) {
+	testCases := []struct {
+		name    string
+		result  string
+		wantErr string
+	}{
+		{
+			name:    "no message",
+			result:  `{"pillar": "security"}`,
+			wantErr: "`deny` result of rule `rego_bad` has no `msg`",
+		},
+		{
+			name:    "unknown severity",
+			result:  `{"msg": "bad", "severity": "critical"}`,
+			wantErr: "invalid `severity` of `deny` result of rule `rego_bad`: unknown severity `critical`",
+		},
+		{
+			name:    "unknown pillar",
+			result:  `{"msg": "bad", "pillar": "speed"}`,
+			wantErr: "invalid `pillar` of `deny` result of rule `rego_bad`",
+		},
+		{
+			name:    "number",
+			result:  `1`,
+			wantErr: "`deny` result of rule `rego_bad` is not a string or an object",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+				"main.tf":         regoConfig,
+				"policy/bad.rego": "package bad\n\nimport rego.v1\n\ndeny contains " + tc.result + " if { input.name == \"sa\" }\n",
+			}))
+			defer stub.Reset()
+			rules, err := LoadRegoRules("policy")
+			require.NoError(t, err)
+			require.Len(t, rules, 1)
+			err = rules[0].Check(helper.TestRunner(t, map[string]string{"main.tf": regoConfig}))
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
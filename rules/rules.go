@@ -0,0 +1,265 @@
+package rules
+
+import (
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/graph"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	storageAccountResourceType = "Microsoft.Storage/storageAccounts"
+	keyVaultResourceType       = "Microsoft.KeyVault/vaults"
+	storageAccountLink         = "https://learn.microsoft.com/azure/well-architected/service-guides/azure-blob-storage"
+	keyVaultLink               = "https://learn.microsoft.com/azure/key-vault/general/best-practices"
+	avmStorageAccountSource    = "Azure/avm-res-storage-storageaccount/azurerm"
+	avmKeyVaultSource          = "Azure/avm-res-keyvault-vault/azurerm"
+)
+
+// Rules is the list of rules provided by the ruleset.
+var Rules = allRules()
+
+// allRules returns the rules of the ruleset, followed by the body rules, the CEL rules, the module input rules,
+// and the rules that trace the body rules back to variables.
+func allRules() []tflint.Rule {
+	rules := []tflint.Rule{
+		NewPrivateEndpointRule(
+			"azapi_private_endpoint_required",
+			"https://learn.microsoft.com/azure/well-architected/security/networking#connectivity-to-paas-services",
+		).WithMetadata(waf.Metadata{
+			Pillar:            waf.Security,
+			RecommendationIDs: []string{"SE:06"},
+			Remediation:       "Add a `Microsoft.Network/privateEndpoints` resource that connects to the service with the missing group IDs.",
+			Rationale:         "A service with public network access disabled is only reachable through a private endpoint.",
+		}),
+		NewVnetAddressSpaceRule(
+			"azapi_vnet_address_space_overlap",
+			"https://learn.microsoft.com/azure/well-architected/reliability/networking#ip-address-planning",
+		).WithMetadata(waf.Metadata{
+			Pillar:            waf.Reliability,
+			RecommendationIDs: []string{"RE:05"},
+			Remediation:       "Allocate non-overlapping address prefixes and keep subnets within their virtual network's address space.",
+			Rationale:         "Overlapping address spaces prevent virtual network peering and routing between workloads.",
+		}),
+		NewAzApiSchemaRule(
+			"azapi_resource_body_schema",
+			"https://learn.microsoft.com/azure/well-architected/operational-excellence/infrastructure-as-code-design",
+		).WithMetadata(waf.Metadata{
+			Pillar:            waf.OperationalExcellence,
+			RecommendationIDs: []string{"OE:05"},
+			Remediation:       "Correct the body so that it matches the resource type's schema for the API version.",
+			Rationale:         "Queries against a misspelled or mistyped property pass silently, and the deployment fails or ignores the value.",
+		}),
+		NewApiVersionRule(
+			"azapi_api_version",
+			"https://learn.microsoft.com/azure/azure-resource-manager/management/resource-providers-and-types",
+		).WithMetadata(waf.Metadata{
+			Pillar:            waf.OperationalExcellence,
+			RecommendationIDs: []string{"OE:05"},
+			Remediation:       "Update the resource type to a current stable API version.",
+			Rationale:         "Old API versions lack current properties and security defaults, and are eventually retired.",
+		}),
+		NewPreviewApiVersionRule(
+			"azapi_api_version_preview",
+			"https://learn.microsoft.com/azure/azure-resource-manager/management/resource-providers-and-types",
+		).WithMetadata(waf.Metadata{
+			Pillar:            waf.Reliability,
+			RecommendationIDs: []string{"RE:04"},
+			Remediation:       "Use a stable API version.",
+			Rationale:         "Preview API versions are not covered by a service level agreement and can change without notice.",
+		}),
+	}
+	for _, r := range bodyRules {
+		rules = append(rules, r)
+	}
+	for _, r := range celRules {
+		rules = append(rules, r)
+	}
+	for _, r := range moduleInputRules {
+		rules = append(rules, r)
+	}
+	return append(rules,
+		NewVariableDefaultRule(
+			"azapi_variable_default",
+			"https://developer.hashicorp.com/terraform/language/values/variables",
+			bodyRules,
+		).WithMetadata(waf.Metadata{
+			Pillar:            waf.OperationalExcellence,
+			RecommendationIDs: []string{"OE:05"},
+			Remediation:       "Change the default to a value that the rule accepts.",
+			Rationale:         "Callers that do not set the variable deploy the default, which does not meet the rule.",
+		}),
+		NewVariableValidationRule(
+			"azapi_variable_validation",
+			"https://developer.hashicorp.com/terraform/language/values/variables#custom-validation-rules",
+			bodyRules,
+		).WithMetadata(waf.Metadata{
+			Pillar:            waf.OperationalExcellence,
+			RecommendationIDs: []string{"OE:05"},
+			Remediation:       "Add a `validation` block that restricts the variable to the values that the rule accepts.",
+			Rationale:         "Without validation, callers of the module can pass values that do not meet the rule.",
+		}),
+	)
+}
+
+// bodyRules are the rules that check a path of the `body` attribute of `azapi_resource` resources.
+var bodyRules = []*AzApiRule{
+	NewAzApiRule(
+		"azapi_storage_account_min_tls_version",
+		storageAccountLink,
+		storageAccountResourceType,
+		"", "",
+		"properties.minimumTlsVersion",
+		blockquery.IsOneOfAndMustExist,
+		blockquery.NewStringResults("TLS1_2")...,
+	).WithFix(cty.StringVal("TLS1_2")).WithMetadata(waf.Metadata{
+		Pillar:            waf.Security,
+		RecommendationIDs: []string{"SE:07"},
+		Remediation:       "Set `properties.minimumTlsVersion` to `TLS1_2`.",
+		Rationale:         "Older TLS versions have known vulnerabilities.",
+	}),
+	NewAzApiRule(
+		"azapi_storage_account_https_traffic_only",
+		storageAccountLink,
+		storageAccountResourceType,
+		"", "",
+		"properties.supportsHttpsTrafficOnly",
+		blockquery.IsOneOf,
+		blockquery.NewTrueResult(),
+	).WithFix(cty.True).WithMetadata(waf.Metadata{
+		Pillar:            waf.Security,
+		RecommendationIDs: []string{"SE:07"},
+		Remediation:       "Set `properties.supportsHttpsTrafficOnly` to `true`.",
+		Rationale:         "Unencrypted HTTP traffic can be intercepted.",
+	}),
+	NewAzApiRule(
+		"azapi_storage_account_public_network_access",
+		storageAccountLink,
+		storageAccountResourceType,
+		"", "",
+		"properties.publicNetworkAccess",
+		blockquery.IsOneOfAndMustExist,
+		blockquery.NewStringResults("Disabled")...,
+	).WithFix(cty.StringVal("Disabled")).WithMetadata(waf.Metadata{
+		Pillar:            waf.Security,
+		RecommendationIDs: []string{"SE:06"},
+		Remediation:       "Set `properties.publicNetworkAccess` to `Disabled` and connect to the account with a private endpoint.",
+		Rationale:         "Public endpoints increase the attack surface of the storage account.",
+	}),
+	NewAzApiRule(
+		"azapi_storage_account_zone_redundancy",
+		storageAccountLink,
+		storageAccountResourceType,
+		"", "",
+		"sku.name",
+		blockquery.IsOneOfAndMustExist,
+		blockquery.NewStringResults("Standard_ZRS", "Standard_GZRS", "Standard_RAGZRS", "Premium_ZRS")...,
+	).WithMetadata(waf.Metadata{
+		Pillar:            waf.Reliability,
+		RecommendationIDs: []string{"RE:05"},
+		Remediation:       "Use a zone-redundant SKU such as `Standard_ZRS` or `Standard_GZRS`.",
+		Rationale:         "Zone-redundant storage keeps data available during a zone outage.",
+	}),
+	NewAzApiRule(
+		"azapi_key_vault_purge_protection",
+		keyVaultLink,
+		keyVaultResourceType,
+		"", "",
+		"properties.enablePurgeProtection",
+		blockquery.IsOneOfAndMustExist,
+		blockquery.NewTrueResult(),
+	).WithMetadata(waf.Metadata{
+		Pillar:            waf.Reliability,
+		RecommendationIDs: []string{"RE:09"},
+		Remediation:       "Set `properties.enablePurgeProtection` to `true`.",
+		Rationale:         "Purge protection prevents the permanent deletion of vaults and secrets during the retention period.",
+	}),
+	NewAzApiRule(
+		"azapi_key_vault_public_network_access",
+		keyVaultLink,
+		keyVaultResourceType,
+		"", "",
+		"properties.publicNetworkAccess",
+		blockquery.IsOneOfAndMustExist,
+		blockquery.NewStringResults("Disabled")...,
+	).WithFix(cty.StringVal("Disabled")).WithMetadata(waf.Metadata{
+		Pillar:            waf.Security,
+		RecommendationIDs: []string{"SE:06"},
+		Remediation:       "Set `properties.publicNetworkAccess` to `Disabled` and connect to the vault with a private endpoint.",
+		Rationale:         "Public endpoints increase the attack surface of the vault.",
+	}),
+}
+
+// celRules are the rules that evaluate a CEL expression against `azapi_resource` resources.
+// An invalid expression panics when the plugin starts.
+var celRules = []*CelRule{
+	MustCelRule(NewCelRule(
+		"azapi_storage_account_network_restricted",
+		storageAccountLink,
+		storageAccountResourceType,
+		"", "",
+		"body.?properties.?publicNetworkAccess.orValue('') == 'Disabled' || body.?properties.?networkAcls.?defaultAction.orValue('') == 'Deny'",
+	)).WithMetadata(waf.Metadata{
+		Pillar:            waf.Security,
+		RecommendationIDs: []string{"SE:06"},
+		Remediation:       "Set `properties.networkAcls.defaultAction` to `Deny` and allow the required networks, or set `properties.publicNetworkAccess` to `Disabled`.",
+		Rationale:         "A storage account that accepts traffic from all networks is exposed to the internet.",
+	}),
+}
+
+// moduleInputRules are the rules that check the input arguments of calls to Azure Verified Modules.
+var moduleInputRules = []*ModuleInputRule{
+	NewModuleInputRule(
+		"avm_storage_account_public_network_access",
+		storageAccountLink,
+		avmStorageAccountSource,
+		"",
+		"public_network_access_enabled", "",
+		blockquery.IsOneOf,
+		blockquery.NewFalseResult(),
+	).WithMetadata(waf.Metadata{
+		Pillar:            waf.Security,
+		RecommendationIDs: []string{"SE:06"},
+		Remediation:       "Set `public_network_access_enabled` to `false` and connect to the account with a private endpoint.",
+		Rationale:         "Public endpoints increase the attack surface of the storage account.",
+	}),
+	NewModuleInputRule(
+		"avm_key_vault_public_network_access",
+		keyVaultLink,
+		avmKeyVaultSource,
+		"",
+		"public_network_access_enabled", "",
+		blockquery.IsOneOfAndMustExist,
+		blockquery.NewFalseResult(),
+	).WithMetadata(waf.Metadata{
+		Pillar:            waf.Security,
+		RecommendationIDs: []string{"SE:06"},
+		Remediation:       "Set `public_network_access_enabled` to `false` and connect to the vault with a private endpoint.",
+		Rationale:         "The module enables public network access by default, which increases the attack surface of the vault.",
+	}),
+	NewModuleInputRule(
+		"avm_key_vault_diagnostic_settings",
+		"https://learn.microsoft.com/azure/key-vault/general/logging",
+		avmKeyVaultSource,
+		"",
+		"diagnostic_settings", "@values.0",
+		blockquery.Exists,
+	).WithMetadata(waf.Metadata{
+		Pillar:            waf.OperationalExcellence,
+		RecommendationIDs: []string{"OE:07"},
+		Remediation:       "Add an entry to `diagnostic_settings` that sends the vault's logs to a Log Analytics workspace.",
+		Rationale:         "Audit logs of secret, key and certificate access are needed to detect and investigate misuse.",
+	}),
+}
+
+// azapiResources returns the `azapi_resource` resources of the nodes with a known type and API version.
+func azapiResources(nodes []*graph.Node) []*graph.Node {
+	res := make([]*graph.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Kind == graph.KindResource && n.Label == "azapi_resource" && n.IsAzApi() {
+			res = append(res, n)
+		}
+	}
+	return res
+}
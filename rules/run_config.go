@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/tidwall/gjson"
+)
+
+// RunConfig is the configuration of a run of the rules by a ruleset.
+// It is provided by the runner rather than kept on the rules, as the rules are shared by the rulesets.
+type RunConfig struct {
+	Enabled  map[string]bool           // The enabled rules by name, or nil if every rule is enabled.
+	Expected map[string][]gjson.Result // The expected results that replace those of the rules by name, e.g. from a profile.
+	Engine   *Engine                   // Runs the queries of the enabled AzApiRule rules in a single pass, or nil if each rule runs its own.
+}
+
+// RuleEnabled reports whether the rule is enabled in the run.
+func (c *RunConfig) RuleEnabled(name string) bool {
+	if c == nil || c.Enabled == nil {
+		return true
+	}
+	return c.Enabled[name]
+}
+
+// expected returns the expected results of the rule in the run, or the defaults if they are not replaced.
+func (c *RunConfig) expected(name string, defaults []gjson.Result) []gjson.Result {
+	if c == nil {
+		return defaults
+	}
+	if expected, ok := c.Expected[name]; ok {
+		return expected
+	}
+	return defaults
+}
+
+// RunConfigRunner is implemented by runners that provide the configuration of the run.
+type RunConfigRunner interface {
+	RunConfig() *RunConfig
+}
+
+// RunConfigOf returns the configuration of the run if the runner is a RunConfigRunner, otherwise nil.
+func RunConfigOf(runner tflint.Runner) *RunConfig {
+	if rr, ok := runner.(RunConfigRunner); ok {
+		return rr.RunConfig()
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// recordingRunner is a test runner that records the checks of the rules.
+type recordingRunner struct {
+	*helper.Runner
+	checks []score.Check
+}
+
+func (r *recordingRunner) RecordCheck(c score.Check) {
+	r.checks = append(r.checks, c)
+}
+
+const scoreTestConfig = `
+resource "azapi_resource" "good" {
+  type      = "Microsoft.Network/virtualNetworks@2023-11-01"
+  name      = "good"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.0.0/16"]
+      }
+      subnets = [
+        {
+          name = "outside"
+          properties = {
+            addressPrefix = "10.1.0.0/24"
+          }
+        }
+      ]
+    }
+  }
+}
+
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2021-09-01"
+  name = "sa"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = "Standard_ZRS"
+    }
+    properties = {
+      minimumTlsVersion   = "TLS1_0"
+      publicNetworkAccess = "Disabled"
+    }
+  }
+}
+`
+
+func TestRulesRecordChecks(t *testing.T) {
+	type result struct {
+		address string
+		passed  bool
+	}
+	testCases := []struct {
+		rule tflint.Rule
+		want []result
+	}{
+		{
+			rule: NewAzApiRule("tls", "", storageAccountResourceType, "", "", "properties.minimumTlsVersion", blockquery.IsOneOf, blockquery.NewStringResults("TLS1_2")...),
+			want: []result{{"azapi_resource.sa", false}},
+		},
+		{
+			rule: NewAzApiRule("zrs", "", storageAccountResourceType, "", "", "sku.name", blockquery.IsOneOf, blockquery.NewStringResults("Standard_ZRS")...),
+			want: []result{{"azapi_resource.sa", true}},
+		},
+		{
+			rule: NewApiVersionRule("api_version", ""),
+			want: []result{{"azapi_resource.good", true}, {"azapi_resource.sa", false}},
+		},
+		{
+			rule: NewPreviewApiVersionRule("preview", ""),
+			want: []result{{"azapi_resource.good", true}, {"azapi_resource.sa", true}},
+		},
+		{
+			rule: NewAzApiSchemaRule("schema", ""),
+			want: []result{{"azapi_resource.good", true}, {"azapi_resource.sa", true}},
+		},
+		{
+			rule: NewPrivateEndpointRule("private_endpoint", ""),
+			want: []result{{"azapi_resource.sa", false}},
+		},
+		{
+			rule: NewVnetAddressSpaceRule("vnet", ""),
+			want: []result{{"azapi_resource.good", true}, {"azapi_resource.good/subnets/outside", false}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.rule.Name(), func(t *testing.T) {
+			runner := &recordingRunner{Runner: helper.TestRunner(t, map[string]string{"main.tf": scoreTestConfig})}
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(scoreTestConfig))
+			defer stub.Reset()
+			require.NoError(t, tc.rule.Check(runner))
+			got := make([]result, len(runner.checks))
+			for i, c := range runner.checks {
+				assert.Equal(t, tc.rule, c.Rule)
+				got[i] = result{c.Address, c.Passed}
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+const instanceTestConfig = `
+resource "azapi_resource" "counted" {
+  count = 2
+  type  = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name  = "counted${count.index}"
+  body = {
+    properties = {
+      minimumTlsVersion = count.index == 0 ? "TLS1_2" : "TLS1_0"
+    }
+  }
+}
+
+resource "azapi_resource" "each" {
+  for_each = { a = "TLS1_0", b = "TLS1_2" }
+  type     = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name     = each.key
+  body = {
+    properties = {
+      minimumTlsVersion = each.value
+    }
+  }
+}
+
+resource "azapi_resource" "untyped" {
+  name = "untyped"
+}
+`
+
+func TestRulesRecordInstanceChecks(t *testing.T) {
+	type result struct {
+		address string
+		passed  bool
+	}
+	instances := []result{
+		{"azapi_resource.counted[0]", true},
+		{"azapi_resource.counted[1]", false},
+		{`azapi_resource.each["a"]`, false},
+		{`azapi_resource.each["b"]`, true},
+	}
+	testCases := []struct {
+		name   string
+		rule   tflint.Rule
+		want   []result
+		issues int
+	}{
+		{
+			name:   "azapi",
+			rule:   NewAzApiRule("tls", "", storageAccountResourceType, "", "", "properties.minimumTlsVersion", blockquery.IsOneOf, blockquery.NewStringResults("TLS1_2")...),
+			want:   append(instances, result{"azapi_resource.untyped", false}),
+			issues: 3,
+		},
+		{
+			name:   "cel",
+			rule:   MustCelRule(NewCelRule("tls", "", storageAccountResourceType, "", "", "body.properties.minimumTlsVersion == 'TLS1_2'")),
+			want:   instances,
+			issues: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := &recordingRunner{Runner: helper.TestRunner(t, map[string]string{"main.tf": instanceTestConfig})}
+			stub := gostub.Stub(&modulecontent.AppFs, mockFs(instanceTestConfig))
+			defer stub.Reset()
+			require.NoError(t, tc.rule.Check(runner))
+			got := make([]result, len(runner.checks))
+			for i, c := range runner.checks {
+				got[i] = result{c.Address, c.Passed}
+			}
+			assert.Equal(t, tc.want, got)
+			assert.Len(t, runner.Issues, tc.issues)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+module "vault" {
+  source  = "Azure/avm-res-keyvault-vault/azurerm"
+  version = "0.9.1"
+
+  name                          = "kv"
+  resource_group_name           = "rg"
+  location                      = "westeurope"
+  tenant_id                     = "00000000-0000-0000-0000-000000000000"
+  public_network_access_enabled = false
+  diagnostic_settings           = {}
+}
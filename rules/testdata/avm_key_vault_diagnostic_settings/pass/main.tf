@@ -0,0 +1,23 @@
+resource "azapi_resource" "law" {
+  type      = "Microsoft.OperationalInsights/workspaces@2023-09-01"
+  name      = "law"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body      = {}
+}
+
+module "vault" {
+  source  = "Azure/avm-res-keyvault-vault/azurerm"
+  version = "0.9.1"
+
+  name                          = "kv"
+  resource_group_name           = "rg"
+  location                      = "westeurope"
+  tenant_id                     = "00000000-0000-0000-0000-000000000000"
+  public_network_access_enabled = false
+  diagnostic_settings = {
+    to_law = {
+      workspace_resource_id = azapi_resource.law.id
+    }
+  }
+}
@@ -0,0 +1,25 @@
+variable "public_network_access_enabled" {
+  type    = bool
+  default = true
+}
+
+module "vault" {
+  source  = "Azure/avm-res-keyvault-vault/azurerm"
+  version = "0.9.1"
+
+  name                          = "kv"
+  resource_group_name           = "rg"
+  location                      = "westeurope"
+  tenant_id                     = "00000000-0000-0000-0000-000000000000"
+  public_network_access_enabled = var.public_network_access_enabled
+}
+
+module "missing" {
+  source  = "Azure/avm-res-keyvault-vault/azurerm"
+  version = "0.9.1"
+
+  name                = "kv2"
+  resource_group_name = "rg"
+  location            = "westeurope"
+  tenant_id           = "00000000-0000-0000-0000-000000000000"
+}
@@ -0,0 +1,4 @@
+rule "avm_key_vault_public_network_access" {
+  enabled  = true
+  expected = [true]
+}
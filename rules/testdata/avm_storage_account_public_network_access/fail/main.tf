@@ -0,0 +1,9 @@
+module "storage" {
+  source  = "Azure/avm-res-storage-storageaccount/azurerm"
+  version = "~> 0.2"
+
+  name                          = "sa"
+  resource_group_name           = "rg"
+  location                      = "westeurope"
+  public_network_access_enabled = true
+}
@@ -0,0 +1,14 @@
+module "storage" {
+  source  = "registry.terraform.io/Azure/avm-res-storage-storageaccount/azurerm"
+  version = "~> 0.2"
+
+  name                = "sa"
+  resource_group_name = "rg"
+  location            = "westeurope"
+}
+
+module "other" {
+  source = "Azure/avm-res-network-virtualnetwork/azurerm"
+
+  public_network_access_enabled = true
+}
@@ -0,0 +1,35 @@
+resource "azapi_resource" "old" {
+  type      = "Microsoft.Storage/storageAccounts@2019-06-01"
+  name      = "old"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = "Standard_ZRS"
+    }
+    properties = {
+      minimumTlsVersion        = "TLS1_2"
+      supportsHttpsTrafficOnly = true
+      publicNetworkAccess      = "Disabled"
+    }
+  }
+}
+
+resource "azapi_resource" "unknown" {
+  type      = "Microsoft.Storage/storageAccounts@2099-01-01"
+  name      = "unknown"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = "Standard_ZRS"
+    }
+    properties = {
+      minimumTlsVersion        = "TLS1_2"
+      supportsHttpsTrafficOnly = true
+      publicNetworkAccess      = "Disabled"
+    }
+  }
+}
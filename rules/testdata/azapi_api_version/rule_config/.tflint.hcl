@@ -0,0 +1,4 @@
+rule "azapi_api_version" {
+  enabled            = true
+  max_newer_versions = 1
+}
@@ -0,0 +1,17 @@
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = "Standard_ZRS"
+    }
+    properties = {
+      minimumTlsVersion        = "TLS1_2"
+      supportsHttpsTrafficOnly = true
+      publicNetworkAccess      = "Disabled"
+    }
+  }
+}
@@ -0,0 +1,16 @@
+resource "azapi_resource" "kv" {
+  type      = "Microsoft.KeyVault/vaults@2021-04-01-preview"
+  name      = "kv"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    properties = {
+      tenantId = "00000000-0000-0000-0000-000000000000"
+      sku = {
+        family = "A"
+        name   = "standard"
+      }
+      enablePurgeProtection = true
+    }
+  }
+}
@@ -0,0 +1,18 @@
+resource "azapi_resource" "pe" {
+  type      = "Microsoft.Network/privateEndpoints@2023-11-01"
+  name      = "pe"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  body = {
+    properties = {
+      privateLinkServiceConnections = [
+        {
+          name = "sa"
+          properties = {
+            privateLinkServiceId = azapi_resource.sa.id
+            groupIds             = ["file"]
+          }
+        }
+      ]
+    }
+  }
+}
@@ -0,0 +1,4 @@
+module "vault" {
+  source = "./modules/vault"
+  sku    = "basic"
+}
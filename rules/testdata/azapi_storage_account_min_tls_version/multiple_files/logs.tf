@@ -0,0 +1,15 @@
+resource "azapi_resource" "logs" {
+  type      = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name      = "logs"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = "Standard_ZRS"
+    }
+    properties = {
+      minimumTlsVersion = "TLS1_1"
+    }
+  }
+}
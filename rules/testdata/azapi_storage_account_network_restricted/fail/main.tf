@@ -0,0 +1,37 @@
+resource "azapi_resource" "allow" {
+  type      = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name      = "allow"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    properties = {
+      publicNetworkAccess = "Enabled"
+      networkAcls = {
+        defaultAction = "Allow"
+      }
+    }
+  }
+}
+
+resource "azapi_resource" "unset" {
+  type      = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name      = "unset"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+  }
+}
+
+resource "azapi_resource" "vault" {
+  type      = "Microsoft.KeyVault/vaults@2023-07-01"
+  name      = "kv"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    properties = {
+      publicNetworkAccess = "Enabled"
+    }
+  }
+}
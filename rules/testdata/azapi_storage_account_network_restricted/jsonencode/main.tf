@@ -0,0 +1,14 @@
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = jsonencode({
+    kind = "StorageV2"
+    properties = {
+      networkAcls = {
+        defaultAction = "Allow"
+      }
+    }
+  })
+}
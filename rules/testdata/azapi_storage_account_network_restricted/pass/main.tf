@@ -0,0 +1,20 @@
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    properties = {
+      networkAcls = {
+        defaultAction = "Deny"
+        ipRules = [
+          {
+            action = "Allow"
+            value  = "203.0.113.0/24"
+          }
+        ]
+      }
+    }
+  }
+}
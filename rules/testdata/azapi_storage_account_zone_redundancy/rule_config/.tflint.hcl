@@ -0,0 +1,4 @@
+rule "azapi_storage_account_zone_redundancy" {
+  enabled  = true
+  expected = ["Standard_GZRS", "Standard_RAGZRS"]
+}
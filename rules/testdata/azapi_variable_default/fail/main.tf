@@ -0,0 +1,27 @@
+variable "min_tls_version" {
+  type    = string
+  default = "TLS1_0"
+}
+
+variable "sku" {
+  type = object({
+    name = string
+  })
+  default = {
+    name = "Standard_LRS"
+  }
+}
+
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    sku  = var.sku
+    properties = {
+      minimumTlsVersion = var.min_tls_version
+    }
+  }
+}
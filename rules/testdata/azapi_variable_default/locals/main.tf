@@ -0,0 +1,26 @@
+variable "settings" {
+  type = object({
+    tls = string
+  })
+  default = {
+    tls = "TLS1_1"
+  }
+}
+
+locals {
+  tls = var.settings.tls
+  properties = {
+    minimumTlsVersion = local.tls
+  }
+}
+
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind       = "StorageV2"
+    properties = local.properties
+  }
+}
@@ -0,0 +1,30 @@
+variable "min_tls_version" {
+  type    = string
+  default = "TLS1_2"
+}
+
+variable "public_network_access" {
+  type = string
+}
+
+variable "sku_name" {
+  type    = string
+  default = "Standard_LRS"
+}
+
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    sku = {
+      name = var.sku_name == "" ? "Standard_ZRS" : "Standard_GZRS"
+    }
+    properties = {
+      minimumTlsVersion   = var.min_tls_version
+      publicNetworkAccess = var.public_network_access
+    }
+  }
+}
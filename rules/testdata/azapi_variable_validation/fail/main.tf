@@ -0,0 +1,45 @@
+variable "min_tls_version" {
+  type    = string
+  default = "TLS1_2"
+}
+
+variable "public_network_access" {
+  type    = string
+  default = "Disabled"
+
+  validation {
+    condition     = length(var.min_tls_version) > 0
+    error_message = "This validates another variable."
+  }
+}
+
+locals {
+  public_network_access = var.public_network_access
+}
+
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    properties = {
+      minimumTlsVersion   = var.min_tls_version
+      publicNetworkAccess = local.public_network_access
+    }
+  }
+}
+
+resource "azapi_resource" "sa2" {
+  type      = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name      = "sa2"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = "westeurope"
+  body = {
+    kind = "StorageV2"
+    properties = {
+      minimumTlsVersion = var.min_tls_version
+    }
+  }
+}
@@ -0,0 +1,26 @@
+variable "min_tls_version" {
+  type    = string
+  default = "TLS1_2"
+
+  validation {
+    condition     = contains(["TLS1_2", "TLS1_3"], var.min_tls_version)
+    error_message = "The minimum TLS version must be TLS1_2 or later."
+  }
+}
+
+variable "location" {
+  type = string
+}
+
+resource "azapi_resource" "sa" {
+  type      = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name      = "sa"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  location  = var.location
+  body = {
+    kind = "StorageV2"
+    properties = {
+      minimumTlsVersion = var.min_tls_version
+    }
+  }
+}
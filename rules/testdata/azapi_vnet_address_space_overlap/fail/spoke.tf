@@ -0,0 +1,12 @@
+resource "azapi_resource" "spoke" {
+  type      = "Microsoft.Network/virtualNetworks@2023-11-01"
+  name      = "spoke"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.128.0/17"]
+      }
+    }
+  }
+}
@@ -0,0 +1,25 @@
+resource "azapi_resource" "hub" {
+  type      = "Microsoft.Network/virtualNetworks@2023-11-01"
+  name      = "hub"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.0.0/16"]
+      }
+    }
+  }
+}
+
+resource "azapi_resource" "spoke" {
+  type      = "Microsoft.Network/virtualNetworks@2023-11-01"
+  name      = "spoke"
+  parent_id = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/rg"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.1.0.0/16"]
+      }
+    }
+  }
+}
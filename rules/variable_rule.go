@@ -0,0 +1,360 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// variableRule is the base of the rules that trace the query path of body rules back to the input variables of the module.
+// These are for the authors of reusable modules, where callers can pass any value for a variable, so they are disabled by default.
+type variableRule struct {
+	tflint.DefaultRule
+	bodyRules []*AzApiRule
+	link      string
+	metadata  *waf.Metadata
+	ruleName  string
+}
+
+var _ modulecontent.BlockFetcher = &variableRule{}
+
+func (r *variableRule) Metadata() interface{} {
+	return r.metadata
+}
+
+func (r *variableRule) Link() string {
+	return r.link
+}
+
+func (r *variableRule) Enabled() bool {
+	return false
+}
+
+func (r *variableRule) Severity() tflint.Severity {
+	return tflint.WARNING
+}
+
+func (r *variableRule) Name() string {
+	return r.ruleName
+}
+
+func (r *variableRule) LabelOne() string {
+	return "azapi_resource"
+}
+
+func (r *variableRule) LabelNames() []string {
+	return []string{"type", "name"}
+}
+
+func (r *variableRule) BlockType() string {
+	return "resource"
+}
+
+func (r *variableRule) Attributes() []string {
+	return []string{"type", "body"}
+}
+
+// VariableDefaultRule checks that the default of a variable which is used at the query path of a body rule satisfies the rule,
+// e.g. a `min_tls_version` variable with the default `TLS1_0` that sets `properties.minimumTlsVersion`.
+// Only variables whose value is used as is, directly or through local values, are checked.
+type VariableDefaultRule struct {
+	variableRule
+}
+
+var _ tflint.Rule = &VariableDefaultRule{}
+
+// NewVariableDefaultRule creates a rule to check the defaults of the variables used at the query paths of the body rules.
+func NewVariableDefaultRule(ruleName, link string, bodyRules []*AzApiRule) *VariableDefaultRule {
+	return &VariableDefaultRule{variableRule{bodyRules: bodyRules, link: link, ruleName: ruleName}}
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *VariableDefaultRule) WithMetadata(md waf.Metadata) *VariableDefaultRule {
+	r.metadata = &md
+	return r
+}
+
+func (r *VariableDefaultRule) Check(runner tflint.Runner) error {
+	refs, err := traceBodyVariables(r, runner, r.bodyRules)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		v := ref.variable
+		if !ref.direct || v.Default == cty.NilVal || v.Default.IsNull() {
+			continue
+		}
+		val, diags := ref.rel.TraverseRel(v.Default)
+		if diags.HasErrors() || !val.IsWhollyKnown() {
+			continue
+		}
+		key := fmt.Sprintf("%s\x00%s\x00%s\x00%#v", v.Name, ref.rule.Name(), ref.query(), val)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		qr, err := blockquery.Query(cty.ObjectVal(map[string]cty.Value{"value": val}), cty.DynamicPseudoType, ref.query())
+		if err != nil {
+			return fmt.Errorf("could not query value: %s", err)
+		}
+		ok, msg, err := ref.rule.CompareFunc(qr, ref.expected...)
+		if err != nil {
+			return fmt.Errorf("could not compare values: %w", err)
+		}
+		score.Record(runner, score.Check{
+			Rule:         r,
+			ResourceType: ref.rule.resourceType,
+			Address:      "var." + v.Name,
+			Passed:       ok,
+			Range:        v.DefaultRange,
+		})
+		if ok {
+			continue
+		}
+		runner.EmitIssue(
+			r,
+			r.metadata.IssueMessage(fmt.Sprintf(
+				"default of variable `%s` is used at `%s` checked by `%s`: %s",
+				v.Name, ref.rule.Query, ref.rule.Name(), msg,
+			)),
+			v.DefaultRange,
+		)
+	}
+	return nil
+}
+
+// VariableValidationRule checks that a variable which is used at the query path of a body rule has a `validation` block
+// with a condition that references the variable. The condition itself is not evaluated.
+type VariableValidationRule struct {
+	variableRule
+}
+
+var _ tflint.Rule = &VariableValidationRule{}
+
+// NewVariableValidationRule creates a rule to check that the variables used at the query paths of the body rules are validated.
+func NewVariableValidationRule(ruleName, link string, bodyRules []*AzApiRule) *VariableValidationRule {
+	return &VariableValidationRule{variableRule{bodyRules: bodyRules, link: link, ruleName: ruleName}}
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *VariableValidationRule) WithMetadata(md waf.Metadata) *VariableValidationRule {
+	r.metadata = &md
+	return r
+}
+
+func (r *VariableValidationRule) Check(runner tflint.Runner) error {
+	refs, err := traceBodyVariables(r, runner, r.bodyRules)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		v := ref.variable
+		key := v.Name + "\x00" + ref.rule.Name()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ok := validatesVariable(v)
+		score.Record(runner, score.Check{
+			Rule:         r,
+			ResourceType: ref.rule.resourceType,
+			Address:      "var." + v.Name,
+			Passed:       ok,
+			Range:        v.DeclRange,
+		})
+		if ok {
+			continue
+		}
+		runner.EmitIssue(
+			r,
+			r.metadata.IssueMessage(fmt.Sprintf(
+				"variable `%s` is used at `%s` checked by `%s` without a validation block",
+				v.Name, ref.rule.Query, ref.rule.Name(),
+			)),
+			v.DeclRange,
+		)
+	}
+	return nil
+}
+
+// validatesVariable reports whether a validation condition of the variable references it.
+func validatesVariable(v *modulecontent.Variable) bool {
+	for _, cond := range v.Validations {
+		for _, t := range cond.Variables() {
+			if name, ok := referenceName(t, "var"); ok && name == v.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// variableRef is a reference to an input variable from the expression at the query path of a body rule.
+type variableRef struct {
+	variable *modulecontent.Variable
+	direct   bool           // Whether the value at the query path is the variable's value, e.g. `var.tls`, or `local.tls` that is `var.tls`.
+	rel      hcl.Traversal  // If direct, the traversal of the variable's value, e.g. `.tls` for `var.settings.tls`.
+	keys     []string       // If direct, the keys of the query path below the variable's value, e.g. `minimumTlsVersion` for `properties = var.properties`.
+	rule     *AzApiRule     // The body rule.
+	expected []gjson.Result // The expected results of the body rule.
+}
+
+// query returns the gjson query of the value at the query path, relative to the object `{ value = <traversed variable> }`.
+func (ref variableRef) query() string {
+	return strings.Join(append([]string{"value"}, ref.keys...), ".")
+}
+
+// traceBodyVariables returns the references to input variables from the query path of each body rule
+// in the `azapi_resource` resources of the root module that the body rule applies to.
+// Body rules that are disabled in the run, see RunConfig, are skipped.
+func traceBodyVariables(f modulecontent.BlockFetcher, runner tflint.Runner, bodyRules []*AzApiRule) ([]variableRef, error) {
+	bodyRules = enabledRules(runner, bodyRules)
+	ctx, resources, diags := modulecontent.FetchBlocks(f, runner)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("could not get partial content: %s", diags)
+	}
+	decls, diags := modulecontent.FetchDeclarations(runner)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("could not get variables: %s", diags)
+	}
+	expected := make(map[*AzApiRule][]gjson.Result, len(bodyRules))
+	for _, br := range bodyRules {
+		results, err := br.expectedResults(runner)
+		if err != nil {
+			return nil, err
+		}
+		expected[br] = results
+	}
+	var refs []variableRef
+	for _, resource := range resources {
+		typeAttr, typeOk := resource.Body.Attributes["type"]
+		bodyAttr, bodyOk := resource.Body.Attributes["body"]
+		if !typeOk || !bodyOk {
+			continue
+		}
+		typeVal, diags := ctx.EvaluateExpr(typeAttr.Expr, cty.String)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("could not evaluate type expression: %s", diags)
+		}
+		if !typeVal.IsKnown() || typeVal.IsNull() {
+			continue
+		}
+		for _, br := range bodyRules {
+			if !checkAzApiType(typeVal.AsString(), br.resourceType, br.minimumApiVersion, br.maximumApiVersion) {
+				continue
+			}
+			keys := strings.Split(br.Query, ".")
+			expr := bodyAttr.Expr
+			for _, ref := range traceVariables(expr, keys, decls, map[string]bool{}) {
+				ref.rule = br
+				ref.expected = expected[br]
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs, nil
+}
+
+// enabledRules returns the rules that are enabled in the run of the runner.
+func enabledRules(runner tflint.Runner, rules []*AzApiRule) []*AzApiRule {
+	rc := RunConfigOf(runner)
+	res := make([]*AzApiRule, 0, len(rules))
+	for _, r := range rules {
+		if rc.RuleEnabled(r.Name()) {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// traceVariables returns the references to input variables from the value at the keys below the expression,
+// following local values and object literals.
+func traceVariables(expr hcl.Expression, keys []string, decls *modulecontent.Declarations, seen map[string]bool) []variableRef {
+	if len(keys) > 0 {
+		var ok bool
+		if expr, keys, ok = blockquery.ObjectValueExpr(expr, strings.Join(keys, ".")); !ok {
+			return nil
+		}
+	}
+	if ste, ok := expr.(*hclsyntax.ScopeTraversalExpr); ok {
+		return traceTraversal(ste.Traversal, keys, decls, seen, true)
+	}
+	var refs []variableRef
+	for _, t := range expr.Variables() {
+		refs = append(refs, traceTraversal(t, nil, decls, seen, false)...)
+	}
+	return refs
+}
+
+// traceTraversal returns the references to input variables from a `var` or `local` traversal, with the keys of the query path below it.
+// Seen holds the local values being traced, to stop at cycles.
+func traceTraversal(t hcl.Traversal, keys []string, decls *modulecontent.Declarations, seen map[string]bool, direct bool) []variableRef {
+	if name, ok := referenceName(t, "var"); ok {
+		v, ok := decls.Variables[name]
+		if !ok {
+			return nil
+		}
+		ref := variableRef{variable: v, direct: direct}
+		if direct {
+			ref.rel = t[2:]
+			ref.keys = keys
+		}
+		return []variableRef{ref}
+	}
+	name, ok := referenceName(t, "local")
+	if !ok || seen[name] {
+		return nil
+	}
+	expr, ok := decls.Locals[name]
+	if !ok {
+		return nil
+	}
+	seen[name] = true
+	defer delete(seen, name)
+	if !direct {
+		return traceVariables(expr, nil, decls, seen)
+	}
+	// Follow the attributes of the local value as keys, e.g. `local.settings.tls` is the key `tls` of `local.settings`.
+	var localKeys []string
+	for _, step := range t[2:] {
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			return indirect(traceVariables(expr, nil, decls, seen))
+		}
+		localKeys = append(localKeys, attr.Name)
+	}
+	return traceVariables(expr, append(localKeys, keys...), decls, seen)
+}
+
+// indirect marks the references as not direct.
+func indirect(refs []variableRef) []variableRef {
+	for i := range refs {
+		refs[i].direct = false
+		refs[i].rel = nil
+		refs[i].keys = nil
+	}
+	return refs
+}
+
+// referenceName returns the name in a traversal of the form `<root>.<name>`, e.g. `var.tls`.
+func referenceName(t hcl.Traversal, root string) (string, bool) {
+	if len(t) < 2 || t.RootName() != root {
+		return "", false
+	}
+	attr, ok := t[1].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+	return attr.Name, true
+}
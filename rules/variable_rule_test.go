@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceVariables(t *testing.T) {
+	parse := func(src string) hcl.Expression {
+		expr, diags := hclsyntax.ParseExpression([]byte(src), "main.tf", hcl.InitialPos)
+		require.False(t, diags.HasErrors(), diags.Error())
+		return expr
+	}
+	decls := &modulecontent.Declarations{
+		Variables: map[string]*modulecontent.Variable{
+			"tls":      {Name: "tls"},
+			"settings": {Name: "settings"},
+		},
+		Locals: map[string]hcl.Expression{
+			"tls":      parse(`var.settings.tls`),
+			"list":     parse(`[var.tls]`),
+			"cycle_a":  parse(`local.cycle_b`),
+			"cycle_b":  parse(`local.cycle_a`),
+			"settings": parse(`{ tls = var.tls }`),
+		},
+	}
+	type want struct {
+		name   string
+		direct bool
+		keys   []string
+	}
+	testCases := []struct {
+		name string
+		expr string
+		keys []string
+		want []want
+	}{
+		{name: "variable", expr: `var.tls`, want: []want{{name: "tls", direct: true}}},
+		{name: "variable object", expr: `var.settings`, keys: []string{"tls"}, want: []want{{name: "settings", direct: true, keys: []string{"tls"}}}},
+		{name: "local", expr: `local.tls`, want: []want{{name: "settings", direct: true}}},
+		{name: "local object", expr: `local.settings.tls`, want: []want{{name: "tls", direct: true}}},
+		{name: "function call", expr: `upper(var.tls)`, want: []want{{name: "tls"}}},
+		{name: "index", expr: `local.list[0]`, want: []want{{name: "tls"}}},
+		{name: "cycle", expr: `local.cycle_a`},
+		{name: "undeclared", expr: `var.undeclared`},
+		{name: "missing key", expr: `local.settings`, keys: []string{"other"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []want
+			for _, ref := range traceVariables(parse(tc.expr), tc.keys, decls, map[string]bool{}) {
+				got = append(got, want{name: ref.variable.Name, direct: ref.direct, keys: ref.keys})
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
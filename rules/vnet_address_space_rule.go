@@ -0,0 +1,253 @@
+package rules
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/graph"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	virtualNetworkResourceType = "Microsoft.Network/virtualNetworks"
+	subnetResourceType         = "Microsoft.Network/virtualNetworks/subnets"
+)
+
+// VnetAddressSpaceRule checks the address spaces of `Microsoft.Network/virtualNetworks` and `subnets` resources
+// across all resource instances and child modules in the configuration.
+// It reports virtual networks with overlapping address spaces, subnets that overlap within the same virtual network,
+// and subnets whose address prefixes are outside the address space of their parent virtual network.
+// An overlap is reported at both of the overlapping resources.
+type VnetAddressSpaceRule struct {
+	tflint.DefaultRule
+	ruleName string
+	link     string
+	metadata *waf.Metadata
+}
+
+var _ tflint.Rule = &VnetAddressSpaceRule{}
+
+// NewVnetAddressSpaceRule creates a rule to check for overlapping virtual network and subnet address spaces.
+func NewVnetAddressSpaceRule(ruleName, link string) *VnetAddressSpaceRule {
+	return &VnetAddressSpaceRule{
+		ruleName: ruleName,
+		link:     link,
+	}
+}
+
+// WithMetadata sets the Well-Architected Framework metadata of the rule.
+func (r *VnetAddressSpaceRule) WithMetadata(md waf.Metadata) *VnetAddressSpaceRule {
+	r.metadata = &md
+	return r
+}
+
+func (r *VnetAddressSpaceRule) Metadata() interface{} {
+	return r.metadata
+}
+
+func (r *VnetAddressSpaceRule) Link() string {
+	return r.link
+}
+
+func (r *VnetAddressSpaceRule) Enabled() bool {
+	return true
+}
+
+func (r *VnetAddressSpaceRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+func (r *VnetAddressSpaceRule) Name() string {
+	return r.ruleName
+}
+
+// addressSpace is a virtual network or subnet and its parsed address prefixes.
+type addressSpace struct {
+	resource *graph.Node
+	name     string // The display name, e.g. `azapi_resource.vnet` or `azapi_resource.vnet/subnets/default`.
+	prefixes []netip.Prefix
+	subnets  []*addressSpace
+}
+
+func (r *VnetAddressSpaceRule) Check(runner tflint.Runner) error {
+	g, diags := graph.FromRunner(runner)
+	if diags.HasErrors() {
+		return fmt.Errorf("could not build resource graph: %s", diags)
+	}
+	vnets := make([]*addressSpace, 0)
+	for _, res := range azapiResources(g.OfType(virtualNetworkResourceType)) {
+		prefixes, err := queryPrefixes(res, "properties.addressSpace.addressPrefixes")
+		if err != nil {
+			return err
+		}
+		vnet := &addressSpace{
+			resource: res,
+			name:     res.String(),
+			prefixes: prefixes,
+		}
+		inline, err := res.Query("properties.subnets")
+		if err != nil {
+			return fmt.Errorf("could not query value: %s", err)
+		}
+		for _, subnet := range inline.Array() {
+			vnet.subnets = append(vnet.subnets, &addressSpace{
+				resource: res,
+				name:     fmt.Sprintf("%s/subnets/%s", res.String(), subnet.Get("name").String()),
+				prefixes: parsePrefixes(subnetPrefixes(subnet.Get("properties"))),
+			})
+		}
+		vnets = append(vnets, vnet)
+	}
+	for _, res := range azapiResources(g.OfType(subnetResourceType)) {
+		props, err := res.Query("properties")
+		if err != nil {
+			return fmt.Errorf("could not query value: %s", err)
+		}
+		subnet := &addressSpace{
+			resource: res,
+			name:     res.String(),
+			prefixes: parsePrefixes(subnetPrefixes(props)),
+		}
+		if vnet := parentVnet(g, vnets, res); vnet != nil {
+			vnet.subnets = append(vnet.subnets, subnet)
+		}
+	}
+
+	failed := make(map[*addressSpace]bool)
+	for i, a := range vnets {
+		for _, b := range vnets[i+1:] {
+			if p, q, ok := overlap(a.prefixes, b.prefixes); ok {
+				r.emitOverlap(runner, "virtual network", a, p, b, q, failed)
+			}
+		}
+		for j, s := range a.subnets {
+			if len(a.prefixes) > 0 {
+				for _, p := range s.prefixes {
+					if !containedBy(p, a.prefixes) {
+						failed[s] = true
+						runner.EmitIssue(
+							r,
+							r.metadata.IssueMessage(fmt.Sprintf("subnet `%s` address prefix `%s` is outside the address space of virtual network `%s`", s.name, p, a.name)),
+							s.resource.IssueRange(),
+						)
+					}
+				}
+			}
+			for _, t := range a.subnets[j+1:] {
+				if p, q, ok := overlap(s.prefixes, t.prefixes); ok {
+					r.emitOverlap(runner, "subnet", s, p, t, q, failed)
+				}
+			}
+		}
+	}
+	for _, vnet := range vnets {
+		recordAddressSpace(runner, r, virtualNetworkResourceType, vnet, failed)
+		for _, subnet := range vnet.subnets {
+			recordAddressSpace(runner, r, subnetResourceType, subnet, failed)
+		}
+	}
+	return nil
+}
+
+// emitOverlap emits an issue at each of the overlapping virtual networks or subnets and marks both as failed,
+// as the overlap can be resolved by changing either of them.
+func (r *VnetAddressSpaceRule) emitOverlap(runner tflint.Runner, kind string, a *addressSpace, p netip.Prefix, b *addressSpace, q netip.Prefix, failed map[*addressSpace]bool) {
+	failed[a] = true
+	failed[b] = true
+	runner.EmitIssue(
+		r,
+		r.metadata.IssueMessage(fmt.Sprintf("%s `%s` address prefix `%s` overlaps with %s `%s` address prefix `%s`", kind, a.name, p, kind, b.name, q)),
+		a.resource.IssueRange(),
+	)
+	runner.EmitIssue(
+		r,
+		r.metadata.IssueMessage(fmt.Sprintf("%s `%s` address prefix `%s` overlaps with %s `%s` address prefix `%s`", kind, b.name, q, kind, a.name, p)),
+		b.resource.IssueRange(),
+	)
+}
+
+// recordAddressSpace records the check of a virtual network or subnet, which failed if an issue was emitted for it.
+func recordAddressSpace(runner tflint.Runner, rule tflint.Rule, resourceType string, as *addressSpace, failed map[*addressSpace]bool) {
+	score.Record(runner, score.Check{
+		Rule:         rule,
+		ResourceType: resourceType,
+		Address:      as.name,
+		Passed:       !failed[as],
+		Range:        as.resource.IssueRange(),
+	})
+}
+
+// parentVnet returns the virtual network that is the parent of the subnet resource, see graph.Graph.Parents.
+func parentVnet(g *graph.Graph, vnets []*addressSpace, subnet *graph.Node) *addressSpace {
+	for _, parent := range g.Parents(subnet) {
+		for _, vnet := range vnets {
+			if vnet.resource == parent {
+				return vnet
+			}
+		}
+	}
+	return nil
+}
+
+// queryPrefixes returns the parsed address prefixes in the array at the query path.
+func queryPrefixes(res *graph.Node, query string) ([]netip.Prefix, error) {
+	qr, err := res.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("could not query value: %s", err)
+	}
+	return parsePrefixes(qr.Array()), nil
+}
+
+// subnetPrefixes returns the address prefixes of the subnet properties,
+// using `addressPrefixes` if set, otherwise `addressPrefix`.
+func subnetPrefixes(props gjson.Result) []gjson.Result {
+	if prefixes := props.Get("addressPrefixes"); prefixes.IsArray() {
+		return prefixes.Array()
+	}
+	if prefix := props.Get("addressPrefix"); prefix.Type == gjson.String {
+		return []gjson.Result{prefix}
+	}
+	return nil
+}
+
+// parsePrefixes parses the CIDR strings, values that are unknown or not a valid prefix are ignored.
+func parsePrefixes(in []gjson.Result) []netip.Prefix {
+	res := make([]netip.Prefix, 0, len(in))
+	for _, v := range in {
+		if v.Type != gjson.String {
+			continue
+		}
+		p, err := netip.ParsePrefix(strings.TrimSpace(v.String()))
+		if err != nil {
+			continue
+		}
+		res = append(res, p.Masked())
+	}
+	return res
+}
+
+// overlap returns the first pair of overlapping prefixes.
+func overlap(a, b []netip.Prefix) (netip.Prefix, netip.Prefix, bool) {
+	for _, p := range a {
+		for _, q := range b {
+			if p.Overlaps(q) {
+				return p, q, true
+			}
+		}
+	}
+	return netip.Prefix{}, netip.Prefix{}, false
+}
+
+// containedBy reports whether the prefix is wholly within any of the parent prefixes.
+func containedBy(p netip.Prefix, parents []netip.Prefix) bool {
+	for _, parent := range parents {
+		if parent.Bits() <= p.Bits() && parent.Contains(p.Addr()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,382 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+)
+
+func TestVnetAddressSpaceRule(t *testing.T) {
+	rule := NewVnetAddressSpaceRule("test", "https://example.com")
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		expected helper.Issues
+	}{
+		{
+			name: "no overlap",
+			files: map[string]string{
+				"main.tf": `
+resource "azapi_resource" "hub" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "hub"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.0.0/16"]
+      }
+      subnets = [
+        {
+          name = "a"
+          properties = {
+            addressPrefix = "10.0.0.0/24"
+          }
+        },
+        {
+          name = "b"
+          properties = {
+            addressPrefixes = ["10.0.1.0/24"]
+          }
+        }
+      ]
+    }
+  }
+}
+
+resource "azapi_resource" "spoke" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "spoke"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.1.0.0/16"]
+      }
+    }
+  }
+}`,
+			},
+			expected: helper.Issues{},
+		},
+		{
+			name: "overlapping virtual networks",
+			files: map[string]string{
+				"main.tf": `
+resource "azapi_resource" "hub" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "hub"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.0.0/16"]
+      }
+    }
+  }
+}
+
+resource "azapi_resource" "spoke" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "spoke"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["192.168.0.0/24", "10.0.128.0/17"]
+      }
+    }
+  }
+}`,
+			},
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "virtual network `azapi_resource.hub` address prefix `10.0.0.0/16` overlaps with virtual network `azapi_resource.spoke` address prefix `10.0.128.0/17`",
+				},
+				{
+					Rule:    rule,
+					Message: "virtual network `azapi_resource.spoke` address prefix `10.0.128.0/17` overlaps with virtual network `azapi_resource.hub` address prefix `10.0.0.0/16`",
+				},
+			},
+		},
+		{
+			name: "overlapping instances",
+			files: map[string]string{
+				"main.tf": `
+resource "azapi_resource" "spoke" {
+  count = 2
+  type  = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name  = "spoke${count.index}"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.0.0/16"]
+      }
+    }
+  }
+}`,
+			},
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "virtual network `azapi_resource.spoke[0]` address prefix `10.0.0.0/16` overlaps with virtual network `azapi_resource.spoke[1]` address prefix `10.0.0.0/16`",
+				},
+				{
+					Rule:    rule,
+					Message: "virtual network `azapi_resource.spoke[1]` address prefix `10.0.0.0/16` overlaps with virtual network `azapi_resource.spoke[0]` address prefix `10.0.0.0/16`",
+				},
+			},
+		},
+		{
+			name: "subnet resources overlap and outside parent",
+			files: map[string]string{
+				"main.tf": `
+resource "azapi_resource" "vnet" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "vnet"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.0.0/16"]
+      }
+    }
+  }
+}
+
+resource "azapi_resource" "a" {
+  type      = "Microsoft.Network/virtualNetworks/subnets@2023-04-01"
+  name      = "a"
+  parent_id = azapi_resource.vnet.id
+  body = {
+    properties = {
+      addressPrefix = "10.0.0.0/24"
+    }
+  }
+}
+
+resource "azapi_resource" "b" {
+  type      = "Microsoft.Network/virtualNetworks/subnets@2023-04-01"
+  name      = "b"
+  parent_id = "/subscriptions/xxx/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet"
+  body = {
+    properties = {
+      addressPrefix = "10.0.0.128/25"
+    }
+  }
+}
+
+resource "azapi_resource" "c" {
+  type      = "Microsoft.Network/virtualNetworks/subnets@2023-04-01"
+  name      = "c"
+  parent_id = azapi_resource.vnet.id
+  body = {
+    properties = {
+      addressPrefix = "10.1.0.0/24"
+    }
+  }
+}`,
+			},
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "subnet `azapi_resource.a` address prefix `10.0.0.0/24` overlaps with subnet `azapi_resource.b` address prefix `10.0.0.128/25`",
+				},
+				{
+					Rule:    rule,
+					Message: "subnet `azapi_resource.b` address prefix `10.0.0.128/25` overlaps with subnet `azapi_resource.a` address prefix `10.0.0.0/24`",
+				},
+				{
+					Rule:    rule,
+					Message: "subnet `azapi_resource.c` address prefix `10.1.0.0/24` is outside the address space of virtual network `azapi_resource.vnet`",
+				},
+			},
+		},
+		{
+			name: "overlap with child module",
+			files: map[string]string{
+				"main.tf": `
+resource "azapi_resource" "hub" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "hub"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.0.0/16"]
+      }
+    }
+  }
+}
+
+module "spoke" {
+  source         = "./spoke"
+  address_prefix = "10.0.0.0/24"
+}`,
+				"spoke/main.tf": `
+variable "address_prefix" {
+  type = string
+}
+
+resource "azapi_resource" "vnet" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "spoke"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = [var.address_prefix]
+      }
+    }
+  }
+}`,
+			},
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "virtual network `azapi_resource.hub` address prefix `10.0.0.0/16` overlaps with virtual network `module.spoke.azapi_resource.vnet` address prefix `10.0.0.0/24`",
+				},
+				{
+					Rule:    rule,
+					Message: "virtual network `module.spoke.azapi_resource.vnet` address prefix `10.0.0.0/24` overlaps with virtual network `azapi_resource.hub` address prefix `10.0.0.0/16`",
+				},
+			},
+		},
+		{
+			name: "subnets of instances",
+			files: map[string]string{
+				"main.tf": `
+locals {
+  vnets = {
+    a = "10.0.0.0/16"
+    b = "10.1.0.0/16"
+  }
+}
+
+resource "azapi_resource" "vnet" {
+  for_each = local.vnets
+  type     = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name     = each.key
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = [each.value]
+      }
+    }
+  }
+}
+
+resource "azapi_resource" "subnet" {
+  for_each  = { a = "10.0.1.0/24", b = "10.0.2.0/24" }
+  type      = "Microsoft.Network/virtualNetworks/subnets@2023-04-01"
+  name      = each.key
+  parent_id = azapi_resource.vnet[each.key].id
+  body = {
+    properties = {
+      addressPrefix = each.value
+    }
+  }
+}
+
+resource "azapi_resource" "counted" {
+  count = 2
+  type  = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name  = "counted${count.index}"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.${count.index + 2}.0.0/16"]
+      }
+    }
+  }
+}
+
+resource "azapi_resource" "counted_subnet" {
+  type      = "Microsoft.Network/virtualNetworks/subnets@2023-04-01"
+  name      = "default"
+  parent_id = azapi_resource.counted[1].id
+  body = {
+    properties = {
+      addressPrefix = "10.3.0.0/24"
+    }
+  }
+}`,
+			},
+			expected: helper.Issues{
+				{
+					Rule:    rule,
+					Message: "subnet `azapi_resource.subnet[\"b\"]` address prefix `10.0.2.0/24` is outside the address space of virtual network `azapi_resource.vnet[\"b\"]`",
+				},
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			runner := helper.TestRunner(t, map[string]string{"main.tf": tc.files["main.tf"]})
+			stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(tc.files))
+			defer stub.Reset()
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			helper.AssertIssuesWithoutRange(t, tc.expected, runner.Issues)
+		})
+	}
+}
+
+func TestVnetAddressSpaceRuleFailsBothOverlaps(t *testing.T) {
+	const config = `
+resource "azapi_resource" "hub" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "hub"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.0.0/16"]
+      }
+    }
+  }
+}
+
+resource "azapi_resource" "spoke" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "spoke"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.0.1.0/24"]
+      }
+    }
+  }
+}
+
+resource "azapi_resource" "other" {
+  type = "Microsoft.Network/virtualNetworks@2023-04-01"
+  name = "other"
+  body = {
+    properties = {
+      addressSpace = {
+        addressPrefixes = ["10.1.0.0/16"]
+      }
+    }
+  }
+}`
+	runner := &recordingRunner{Runner: helper.TestRunner(t, map[string]string{"main.tf": config})}
+	stub := gostub.Stub(&modulecontent.AppFs, mockFs(config))
+	defer stub.Reset()
+	require.NoError(t, NewVnetAddressSpaceRule("test", "").Check(runner))
+	passed := make(map[string]bool)
+	for _, c := range runner.checks {
+		passed[c.Address] = c.Passed
+	}
+	assert.Equal(t, map[string]bool{
+		"azapi_resource.hub":   false,
+		"azapi_resource.spoke": false,
+		"azapi_resource.other": true,
+	}, passed)
+	var ranges []string
+	for _, issue := range runner.Issues {
+		ranges = append(ranges, issue.Range.String())
+	}
+	assert.Equal(t, []string{"main.tf:2,1-32", "main.tf:14,1-34"}, ranges)
+}
@@ -0,0 +1,229 @@
+package ruleset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// BaselineSchemaVersion is the version of the baseline file format, incremented on incompatible changes.
+const BaselineSchemaVersion = 1
+
+// Baseline is a set of accepted issues, e.g. those of an existing estate when the ruleset is adopted.
+// Issues in the baseline are not reported, so that only new or changed issues are.
+type Baseline struct {
+	SchemaVersion int             `json:"schema_version"`
+	Entries       []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry identifies an issue independently of its position in the configuration.
+type BaselineEntry struct {
+	Rule        string `json:"rule"`            // The name of the rule.
+	Address     string `json:"address"`         // The address of the block that contains the issue, e.g. `azapi_resource.sa`.
+	Query       string `json:"query,omitempty"` // The path of the checked value in the block, if the rule queries one, e.g. `body.properties.minimumTlsVersion`.
+	Fingerprint string `json:"fingerprint"`     // The hash of the issue message, which includes the offending value.
+}
+
+// queryPather is implemented by rules that check a value at a path of a block, e.g. rules that embed blockquery.BlockQuery.
+type queryPather interface {
+	QueryPath() string
+}
+
+// LoadBaseline reads the baseline file from modulecontent.AppFs. If the file does not exist the baseline is empty.
+func LoadBaseline(filename string) (*Baseline, error) {
+	src, err := modulecontent.AppFs.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Baseline{SchemaVersion: BaselineSchemaVersion}, nil
+		}
+		return nil, fmt.Errorf("could not read baseline file: %s", err)
+	}
+	baseline := &Baseline{}
+	if err := json.Unmarshal(src, baseline); err != nil {
+		return nil, fmt.Errorf("could not parse baseline file: %s", err)
+	}
+	if baseline.SchemaVersion != BaselineSchemaVersion {
+		return nil, fmt.Errorf("unsupported baseline schema version %d, regenerate the baseline", baseline.SchemaVersion)
+	}
+	return baseline, nil
+}
+
+// Write writes the baseline as indented JSON, with the entries sorted so that changes are easy to review.
+func (b *Baseline) Write(w io.Writer) error {
+	sort.Slice(b.Entries, func(i, j int) bool {
+		ei, ej := b.Entries[i], b.Entries[j]
+		if ei.Rule != ej.Rule {
+			return ei.Rule < ej.Rule
+		}
+		if ei.Address != ej.Address {
+			return ei.Address < ej.Address
+		}
+		if ei.Query != ej.Query {
+			return ei.Query < ej.Query
+		}
+		return ei.Fingerprint < ej.Fingerprint
+	})
+	if b.Entries == nil {
+		b.Entries = []BaselineEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// newBaselineEntry returns the entry of an issue at the address.
+// The fingerprint leaves out the pillar, recommendation IDs and remediation that are appended to the message,
+// so that the entry only changes with the finding.
+func newBaselineEntry(rule tflint.Rule, message, address string) BaselineEntry {
+	message = strings.TrimSuffix(message, waf.FromRule(rule).IssueMessage(""))
+	sum := sha256.Sum256([]byte(message))
+	e := BaselineEntry{
+		Rule:        rule.Name(),
+		Address:     address,
+		Fingerprint: hex.EncodeToString(sum[:8]),
+	}
+	if qp, ok := rule.(queryPather); ok {
+		e.Query = qp.QueryPath()
+	}
+	return e
+}
+
+// baselineSet is the state shared by the rules that apply the baseline in a run.
+type baselineSet struct {
+	entries map[BaselineEntry]bool
+	used    map[BaselineEntry]bool // The entries that suppressed an issue.
+	found   map[BaselineEntry]bool // The entries of all issues that could be in the baseline.
+}
+
+func newBaselineSet(b *Baseline) *baselineSet {
+	s := &baselineSet{
+		entries: make(map[BaselineEntry]bool, len(b.Entries)),
+		used:    map[BaselineEntry]bool{},
+		found:   map[BaselineEntry]bool{},
+	}
+	for _, e := range b.Entries {
+		s.entries[e] = true
+	}
+	return s
+}
+
+// BaselineFile returns the baseline file of the plugin configuration, or an empty string if none is configured.
+func (r *RuleSet) BaselineFile() string {
+	return r.config.Baseline
+}
+
+// UpdatedBaseline returns a baseline of the issues found by the last run, which replaces the baseline file to accept them all.
+// It is nil if no baseline file is configured.
+func (r *RuleSet) UpdatedBaseline() *Baseline {
+	if r.baseline == nil {
+		return nil
+	}
+	return newBaseline(r.baseline.found)
+}
+
+// PrunedBaseline returns the entries of the baseline file that matched an issue in the last run, without the stale entries.
+// It is nil if no baseline file is configured.
+func (r *RuleSet) PrunedBaseline() *Baseline {
+	if r.baseline == nil {
+		return nil
+	}
+	return newBaseline(r.baseline.used)
+}
+
+func newBaseline(entries map[BaselineEntry]bool) *Baseline {
+	b := &Baseline{SchemaVersion: BaselineSchemaVersion, Entries: make([]BaselineEntry, 0, len(entries))}
+	for e := range entries {
+		b.Entries = append(b.Entries, e)
+	}
+	return b
+}
+
+// baselineRule runs a rule and drops the issues that are in the baseline.
+type baselineRule struct {
+	tflint.Rule
+	set *baselineSet
+}
+
+func (r *baselineRule) Check(runner tflint.Runner) error {
+	return r.Rule.Check(&baselineRunner{Runner: runner, set: r.set})
+}
+
+// baselineRunner drops the issues in the baseline, and emits the others with the underlying runner.
+// Issues that are not in a block with an address, and the notices of tag exemptions, are always emitted.
+type baselineRunner struct {
+	tflint.Runner
+	set   *baselineSet
+	files map[string]*hcl.File
+}
+
+var _ modulecontent.VariablesRunner = &baselineRunner{}
+var _ score.Recorder = &baselineRunner{}
+var _ rules.RunConfigRunner = &baselineRunner{}
+
+func (r *baselineRunner) Variables() modulecontent.Variables {
+	if vr, ok := r.Runner.(modulecontent.VariablesRunner); ok {
+		return vr.Variables()
+	}
+	return modulecontent.Variables{}
+}
+
+func (r *baselineRunner) RecordCheck(c score.Check) {
+	score.Record(r.Runner, c)
+}
+
+func (r *baselineRunner) RunConfig() *rules.RunConfig {
+	return rules.RunConfigOf(r.Runner)
+}
+
+func (r *baselineRunner) EmitIssue(rule tflint.Rule, message string, issueRange hcl.Range) error {
+	accepted, err := r.accepted(rule, message, issueRange)
+	if err != nil || accepted {
+		return err
+	}
+	return r.Runner.EmitIssue(rule, message, issueRange)
+}
+
+func (r *baselineRunner) EmitIssueWithFix(rule tflint.Rule, message string, issueRange hcl.Range, fixFunc func(f tflint.Fixer) error) error {
+	accepted, err := r.accepted(rule, message, issueRange)
+	if err != nil || accepted {
+		return err
+	}
+	return r.Runner.EmitIssueWithFix(rule, message, issueRange, fixFunc)
+}
+
+// accepted reports whether the issue is in the baseline, and records it as found.
+func (r *baselineRunner) accepted(rule tflint.Rule, message string, issueRange hcl.Range) (bool, error) {
+	if rule.Name() == TagExemptionRuleName {
+		return false, nil
+	}
+	if r.files == nil {
+		files, diags := modulecontent.FetchFiles(r.Runner)
+		if diags.HasErrors() {
+			return false, fmt.Errorf("could not read files: %s", diags)
+		}
+		r.files = files
+	}
+	address := blockAddress(r.files, issueRange)
+	if address == "" {
+		return false, nil
+	}
+	e := newBaselineEntry(rule, message, address)
+	r.set.found[e] = true
+	if !r.set.entries[e] {
+		return false, nil
+	}
+	r.set.used[e] = true
+	return true, nil
+}
@@ -0,0 +1,96 @@
+package ruleset
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+func TestBaseline(t *testing.T) {
+	files := map[string]string{"main.tf": exemptionsConfig}
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(files))
+	defer stub.Reset()
+
+	run := func() (*RuleSet, []string) {
+		rs := New("test", "0.0.0", []tflint.Rule{&resourcesRule{testRule{name: "public"}}})
+		require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+		require.NoError(t, decodeConfig(t, rs, `baseline = "baseline.json"`))
+		runner := helper.TestRunner(t, map[string]string{})
+		for _, rule := range rs.EnabledRules {
+			require.NoError(t, rule.Check(runner))
+		}
+		var got []string
+		for _, issue := range runner.Issues {
+			got = append(got, issue.Message)
+		}
+		return rs, got
+	}
+
+	// Without a baseline file all issues are reported.
+	rs, got := run()
+	assert.Equal(t, []string{"public static_site", "public data"}, got)
+	var buf bytes.Buffer
+	require.NoError(t, rs.UpdatedBaseline().Write(&buf))
+	files["baseline.json"] = buf.String()
+
+	// Issues in the baseline are not reported, wherever they are in the file.
+	files["main.tf"] = "\n\n" + exemptionsConfig + `
+resource "azapi_resource" "new" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+}
+`
+	modulecontent.AppFs = ruletest.MemFs(files)
+	_, got = run()
+	assert.Equal(t, []string{"public new"}, got)
+
+	// Entries that no longer match an issue are pruned.
+	files["main.tf"] = `
+resource "azapi_resource" "data" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+}
+`
+	modulecontent.AppFs = ruletest.MemFs(files)
+	rs, got = run()
+	assert.Empty(t, got)
+	pruned := rs.PrunedBaseline()
+	require.Len(t, pruned.Entries, 1)
+	assert.Equal(t, "azapi_resource.data", pruned.Entries[0].Address)
+}
+
+func TestLoadBaselineSchemaVersion(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{"baseline.json": `{"schema_version": 2, "entries": []}`}))
+	defer stub.Reset()
+	_, err := LoadBaseline("baseline.json")
+	assert.ErrorContains(t, err, "unsupported baseline schema version 2")
+}
+
+type queryRule struct {
+	testRule
+	blockquery.BlockQuery
+}
+
+func TestNewBaselineEntry(t *testing.T) {
+	md := &waf.Metadata{Pillar: waf.Security, RecommendationIDs: []string{"SE:07"}, Remediation: "Set TLS 1.2."}
+	rule := &queryRule{
+		testRule:   testRule{name: "tls", metadata: md},
+		BlockQuery: blockquery.NewBlockQuery("resource", "azapi_resource", nil, "body", "properties.minimumTlsVersion", nil),
+	}
+	e := newBaselineEntry(rule, md.IssueMessage("returned value `TLS1_0`"), "azapi_resource.sa")
+	assert.Equal(t, "tls", e.Rule)
+	assert.Equal(t, "azapi_resource.sa", e.Address)
+	assert.Equal(t, "body.properties.minimumTlsVersion", e.Query)
+
+	// The metadata appended to the message does not change the fingerprint, the offending value does.
+	md.Remediation = "Set the minimum TLS version to 1.2."
+	assert.Equal(t, e, newBaselineEntry(rule, md.IssueMessage("returned value `TLS1_0`"), "azapi_resource.sa"))
+	assert.NotEqual(t, e.Fingerprint, newBaselineEntry(rule, md.IssueMessage("returned value `TLS1_1`"), "azapi_resource.sa").Fingerprint)
+}
@@ -0,0 +1,39 @@
+package ruleset
+
+// Config is the plugin configuration declared in the `plugin` block of `.tflint.hcl`.
+//
+//	plugin "azure-wellarchitected" {
+//	  enabled   = true
+//	  profile   = "production"
+//	  varfile   = ["prod.tfvars"]
+//	  variables = ["location=westeurope"]
+//
+//	  pillar "cost_optimization" {
+//	    enabled = false
+//	  }
+//
+//	  scenario "dev" {
+//	    varfile = ["dev.tfvars"]
+//	  }
+//	}
+type Config struct {
+	Profile   string           `hclext:"profile,optional"`   // The name of a bundled profile, see LoadProfile.
+	VarFiles  []string         `hclext:"varfile,optional"`   // Values files to evaluate the configuration with, after those in the tflint `config` block.
+	Values    []string         `hclext:"variables,optional"` // Values in `name=value` format, after those in the tflint `config` block.
+	Pillars   []PillarConfig   `hclext:"pillar,block"`
+	Scenarios []ScenarioConfig `hclext:"scenario,block"` // If set, each rule is run once per scenario, see ScenarioConfig.
+
+	Exemptions           string `hclext:"exemptions,optional"`             // The exemptions file, see LoadExemptions.
+	ExemptionWarningDays int    `hclext:"exemption_warning_days,optional"` // The days before expiry that an exemption is warned about, DefaultExemptionWarningDays if not set.
+	ExemptionTag         string `hclext:"exemption_tag,optional"`          // The tag of a resource that lists its exemptions, DefaultExemptionTag if not set.
+	Baseline             string `hclext:"baseline,optional"`               // The baseline file of accepted issues, see LoadBaseline.
+	RegoPolicies         string `hclext:"rego_policies,optional"`          // The directory of Rego policies to evaluate, see rules.LoadRegoRules.
+	AzurePolicies        string `hclext:"azure_policies,optional"`         // The directory of Azure Policy definitions to import, see azpolicy.Import.
+}
+
+// PillarConfig enables or disables all rules that belong to a Well-Architected pillar.
+// The label can be the pillar name, its snake case key or its recommendation code, see waf.ParsePillar.
+type PillarConfig struct {
+	Name    string `hclext:"name,label"`
+	Enabled bool   `hclext:"enabled"`
+}
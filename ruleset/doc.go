@@ -0,0 +1,3 @@
+// Package ruleset provides the tflint ruleset served by the plugin.
+// It extends the builtin ruleset with the plugin configuration in the `plugin` block of `.tflint.hcl`.
+package ruleset
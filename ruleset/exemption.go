@@ -0,0 +1,376 @@
+package ruleset
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ExemptionExpiringRuleName is the rule that warns about exemptions that expire within the warning period.
+	ExemptionExpiringRuleName = "waf_exemption_expiring"
+	// ExemptionInvalidRuleName is the rule that reports exemptions that have expired or do not match any issue.
+	ExemptionInvalidRuleName = "waf_exemption_invalid"
+	// DefaultExemptionWarningDays is the number of days before its expiry that an exemption is warned about.
+	DefaultExemptionWarningDays = 30
+
+	exemptionsLink = "https://github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitected#exemptions"
+	dateLayout     = "2006-01-02"
+)
+
+// now returns the current time, it is replaced in tests.
+var now = time.Now
+
+// Exemption is a sanctioned exception to a rule for the resources whose address matches a pattern,
+// declared in the exemptions file of the plugin configuration.
+//
+//	exemption {
+//	  rule          = "azapi_storage_account_public_network_access"
+//	  resource      = "azapi_resource.static_site"
+//	  justification = "Static website served to the public"
+//	  approver      = "security@contoso.com"
+//	  expires       = "2025-06-30"
+//	}
+type Exemption struct {
+	Rule          string    `hclext:"rule" yaml:"rule"`                   // The name of the exempted rule.
+	Resource      string    `hclext:"resource" yaml:"resource"`           // The address of the exempted blocks, where `*` matches any characters, e.g. `azapi_resource.site_*`.
+	Justification string    `hclext:"justification" yaml:"justification"` // Why the exception is sanctioned.
+	Approver      string    `hclext:"approver" yaml:"approver"`           // Who sanctioned the exception.
+	Expires       string    `hclext:"expires" yaml:"expires"`             // The last day the exemption applies, in `YYYY-MM-DD` format.
+	DeclRange     hcl.Range `yaml:"-"`                                    // The declaration in the exemptions file.
+	expires       time.Time
+	pattern       *regexp.Regexp
+}
+
+// exemptionsFile is the schema of an HCL exemptions file.
+type exemptionsFile struct {
+	Exemptions []Exemption `hclext:"exemption,block"`
+}
+
+// LoadExemptions reads the exemptions file from modulecontent.AppFs.
+// Files with a `.yaml` or `.yml` extension are read as YAML, with a list of exemptions in the `exemptions` key, and other files as HCL.
+func LoadExemptions(filename string) ([]*Exemption, error) {
+	src, err := modulecontent.AppFs.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read exemptions file: %s", err)
+	}
+	var exemptions []*Exemption
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		exemptions, err = parseYAMLExemptions(src, filename)
+	default:
+		exemptions, err = parseHCLExemptions(src, filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range exemptions {
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("%s: invalid exemption: %s", e.DeclRange, err)
+		}
+	}
+	return exemptions, nil
+}
+
+func parseHCLExemptions(src []byte, filename string) ([]*Exemption, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, diags := hclext.Content(file.Body, hclext.ImpliedBodySchema(&exemptionsFile{}))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	exemptions := make([]*Exemption, 0, len(body.Blocks))
+	for _, block := range body.Blocks {
+		e := &Exemption{}
+		if diags := hclext.DecodeBody(block.Body, nil, e); diags.HasErrors() {
+			return nil, diags
+		}
+		e.DeclRange = block.DefRange
+		exemptions = append(exemptions, e)
+	}
+	return exemptions, nil
+}
+
+func parseYAMLExemptions(src []byte, filename string) ([]*Exemption, error) {
+	var doc struct {
+		Exemptions []yaml.Node `yaml:"exemptions"`
+	}
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("could not parse exemptions file: %s", err)
+	}
+	exemptions := make([]*Exemption, 0, len(doc.Exemptions))
+	for _, node := range doc.Exemptions {
+		e := &Exemption{}
+		if err := node.Decode(e); err != nil {
+			return nil, fmt.Errorf("could not parse exemptions file: %s", err)
+		}
+		pos := hcl.Pos{Line: node.Line, Column: node.Column}
+		e.DeclRange = hcl.Range{Filename: filename, Start: pos, End: pos}
+		exemptions = append(exemptions, e)
+	}
+	return exemptions, nil
+}
+
+// validate checks that the required fields are set, and parses the expiry date and the resource pattern.
+func (e *Exemption) validate() error {
+	for _, field := range [][2]string{{"rule", e.Rule}, {"resource", e.Resource}, {"justification", e.Justification}, {"approver", e.Approver}} {
+		if strings.TrimSpace(field[1]) == "" {
+			return fmt.Errorf("`%s` must not be empty", field[0])
+		}
+	}
+	expires, err := time.ParseInLocation(dateLayout, e.Expires, time.Local)
+	if err != nil {
+		return fmt.Errorf("`expires` must be a date in YYYY-MM-DD format: %s", err)
+	}
+	e.expires = expires
+	parts := strings.Split(e.Resource, "*")
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	e.pattern = regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+	return nil
+}
+
+// Matches reports whether the exemption applies to the rule and address.
+func (e *Exemption) Matches(rule, address string) bool {
+	return e.Rule == rule && address != "" && e.pattern.MatchString(address)
+}
+
+// daysLeft returns the number of days from today until the exemption expires, negative once it has expired.
+func (e *Exemption) daysLeft() int {
+	y, m, d := now().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	return int(math.Round(e.expires.Sub(today).Hours() / 24))
+}
+
+func (e *Exemption) String() string {
+	return fmt.Sprintf("exemption of `%s` from `%s`", e.Resource, e.Rule)
+}
+
+// exemptionSet is the state shared by the rules that apply and report the exemptions of a run.
+type exemptionSet struct {
+	exemptions  []*Exemption
+	warningDays int
+	enabled     map[string]bool     // The names of the enabled rules.
+	used        map[*Exemption]bool // The exemptions that suppressed an issue in this run.
+}
+
+// newExemptionSet checks that each exemption is for one of the rules.
+func newExemptionSet(exemptions []*Exemption, warningDays int, rules []tflint.Rule) (*exemptionSet, error) {
+	known := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		known[rule.Name()] = true
+	}
+	for _, e := range exemptions {
+		if !known[e.Rule] {
+			return nil, fmt.Errorf("%s: exemption for unknown rule `%s`", e.DeclRange, e.Rule)
+		}
+	}
+	return &exemptionSet{
+		exemptions:  exemptions,
+		warningDays: warningDays,
+		enabled:     map[string]bool{},
+		used:        map[*Exemption]bool{},
+	}, nil
+}
+
+// suppresses reports whether an exemption that has not expired applies to the rule and address, and marks it as used.
+func (s *exemptionSet) suppresses(rule, address string) bool {
+	for _, e := range s.exemptions {
+		if e.daysLeft() >= 0 && e.Matches(rule, address) {
+			s.used[e] = true
+			return true
+		}
+	}
+	return false
+}
+
+// exemptRule runs a rule and drops the issues that an exemption applies to.
+type exemptRule struct {
+	tflint.Rule
+	set *exemptionSet
+}
+
+func (r *exemptRule) Check(runner tflint.Runner) error {
+	return r.Rule.Check(&exemptionRunner{Runner: runner, rule: r.Rule.Name(), set: r.set})
+}
+
+// exemptionRunner drops the issues of a rule that an exemption applies to, and emits the others with the underlying runner.
+// The address of an issue is that of the top-level block in the root module that contains it, e.g. `azapi_resource.sa` or `module.app`.
+type exemptionRunner struct {
+	tflint.Runner
+	rule  string
+	set   *exemptionSet
+	files map[string]*hcl.File
+}
+
+var _ modulecontent.VariablesRunner = &exemptionRunner{}
+var _ score.Recorder = &exemptionRunner{}
+var _ rules.RunConfigRunner = &exemptionRunner{}
+
+func (r *exemptionRunner) Variables() modulecontent.Variables {
+	if vr, ok := r.Runner.(modulecontent.VariablesRunner); ok {
+		return vr.Variables()
+	}
+	return modulecontent.Variables{}
+}
+
+func (r *exemptionRunner) RecordCheck(c score.Check) {
+	score.Record(r.Runner, c)
+}
+
+func (r *exemptionRunner) RunConfig() *rules.RunConfig {
+	return rules.RunConfigOf(r.Runner)
+}
+
+func (r *exemptionRunner) EmitIssue(rule tflint.Rule, message string, issueRange hcl.Range) error {
+	exempt, err := r.exempt(issueRange)
+	if err != nil || exempt {
+		return err
+	}
+	return r.Runner.EmitIssue(rule, message, issueRange)
+}
+
+func (r *exemptionRunner) EmitIssueWithFix(rule tflint.Rule, message string, issueRange hcl.Range, fixFunc func(f tflint.Fixer) error) error {
+	exempt, err := r.exempt(issueRange)
+	if err != nil || exempt {
+		return err
+	}
+	return r.Runner.EmitIssueWithFix(rule, message, issueRange, fixFunc)
+}
+
+func (r *exemptionRunner) exempt(issueRange hcl.Range) (bool, error) {
+	if r.files == nil {
+		files, diags := modulecontent.FetchFiles(r.Runner)
+		if diags.HasErrors() {
+			return false, fmt.Errorf("could not read files: %s", diags)
+		}
+		r.files = files
+	}
+	return r.set.suppresses(r.rule, blockAddress(r.files, issueRange)), nil
+}
+
+// blockAddress returns the address of the top-level block that contains the range,
+// or an empty string if it is not in a resource, data, module or variable block.
+func blockAddress(files map[string]*hcl.File, rng hcl.Range) string {
+	file, ok := files[rng.Filename]
+	if !ok {
+		return ""
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return ""
+	}
+	for _, block := range body.Blocks {
+		if !containsPos(block.Range(), rng.Start) {
+			continue
+		}
+		switch {
+		case block.Type == "resource" && len(block.Labels) == 2:
+			return block.Labels[0] + "." + block.Labels[1]
+		case block.Type == "data" && len(block.Labels) == 2:
+			return "data." + block.Labels[0] + "." + block.Labels[1]
+		case block.Type == "module" && len(block.Labels) == 1:
+			return "module." + block.Labels[0]
+		case block.Type == "variable" && len(block.Labels) == 1:
+			return "var." + block.Labels[0]
+		}
+	}
+	return ""
+}
+
+// containsPos reports whether the position is in the range, by line and column as the ranges of issues may not have byte offsets.
+func containsPos(r hcl.Range, pos hcl.Pos) bool {
+	after := pos.Line > r.Start.Line || (pos.Line == r.Start.Line && pos.Column >= r.Start.Column)
+	before := pos.Line < r.End.Line || (pos.Line == r.End.Line && pos.Column < r.End.Column)
+	return after && before
+}
+
+// exemptionRule reports on the exemptions themselves, at their declaration in the exemptions file.
+type exemptionRule struct {
+	tflint.DefaultRule
+	name     string
+	severity tflint.Severity
+	set      *exemptionSet
+	check    func(s *exemptionSet, e *Exemption) string // Returns the message of the issue with the exemption, if any.
+	reset    bool                                       // Whether to reset the exemptions used in the run after the check.
+}
+
+// newExemptionExpiringRule returns the rule that warns about exemptions that expire within the warning period.
+func newExemptionExpiringRule(set *exemptionSet) *exemptionRule {
+	return &exemptionRule{
+		name:     ExemptionExpiringRuleName,
+		severity: tflint.WARNING,
+		set:      set,
+		check: func(s *exemptionSet, e *Exemption) string {
+			if days := e.daysLeft(); days >= 0 && days <= s.warningDays {
+				return fmt.Sprintf("%s approved by %s expires on %s", e, e.Approver, e.Expires)
+			}
+			return ""
+		},
+	}
+}
+
+// newExemptionInvalidRule returns the rule that reports exemptions that have expired, or whose rule is enabled but
+// that did not suppress any issue. It must run after the exempted rules, and resets the exemptions used in the run.
+func newExemptionInvalidRule(set *exemptionSet) *exemptionRule {
+	return &exemptionRule{
+		name:     ExemptionInvalidRuleName,
+		severity: tflint.ERROR,
+		set:      set,
+		reset:    true,
+		check: func(s *exemptionSet, e *Exemption) string {
+			switch {
+			case e.daysLeft() < 0:
+				return fmt.Sprintf("%s approved by %s expired on %s", e, e.Approver, e.Expires)
+			case s.enabled[e.Rule] && !s.used[e]:
+				return fmt.Sprintf("%s does not match any issue", e)
+			}
+			return ""
+		},
+	}
+}
+
+func (r *exemptionRule) Name() string {
+	return r.name
+}
+
+func (r *exemptionRule) Enabled() bool {
+	return true
+}
+
+func (r *exemptionRule) Severity() tflint.Severity {
+	return r.severity
+}
+
+func (r *exemptionRule) Link() string {
+	return exemptionsLink
+}
+
+func (r *exemptionRule) Check(runner tflint.Runner) error {
+	if r.reset {
+		defer func() { r.set.used = map[*Exemption]bool{} }()
+	}
+	for _, e := range r.set.exemptions {
+		if msg := r.check(r.set, e); msg != "" {
+			if err := runner.EmitIssue(r, msg, e.DeclRange); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package ruleset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+const exemptionsConfig = `
+resource "azapi_resource" "static_site" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+}
+
+resource "azapi_resource" "data" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+}
+`
+
+// resourcesRule emits an issue at each `azapi_resource` block.
+type resourcesRule struct {
+	testRule
+}
+
+func (r *resourcesRule) BlockType() string    { return "resource" }
+func (r *resourcesRule) LabelOne() string     { return "azapi_resource" }
+func (r *resourcesRule) LabelNames() []string { return []string{"type", "name"} }
+func (r *resourcesRule) Attributes() []string { return []string{"type"} }
+func (r *resourcesRule) Check(runner tflint.Runner) error {
+	_, blocks, diags := modulecontent.FetchBlocks(r, runner)
+	if diags.HasErrors() {
+		return diags
+	}
+	for _, block := range blocks {
+		if err := runner.EmitIssue(r, r.name+" "+block.Labels[1], block.DefRange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExemptions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		exemptions string
+		filename   string
+		want       []string
+		wantErr    string
+	}{
+		{
+			name: "suppressed",
+			exemptions: `
+exemption {
+  rule          = "public"
+  resource      = "azapi_resource.static_*"
+  justification = "Static website"
+  approver      = "security@contoso.com"
+  expires       = "2025-12-31"
+}`,
+			want: []string{"public: public data", "tls: tls data", "tls: tls static_site"},
+		},
+		{
+			name:     "yaml",
+			filename: "exemptions.yaml",
+			exemptions: `
+exemptions:
+  - rule: public
+    resource: azapi_resource.static_site
+    justification: Static website
+    approver: security@contoso.com
+    expires: 2025-12-31
+`,
+			want: []string{"public: public data", "tls: tls data", "tls: tls static_site"},
+		},
+		{
+			name: "expiring",
+			exemptions: `
+exemption {
+  rule          = "public"
+  resource      = "azapi_resource.static_site"
+  justification = "Static website"
+  approver      = "security@contoso.com"
+  expires       = "2025-06-20"
+}`,
+			want: []string{
+				"public: public data",
+				"tls: tls data",
+				"tls: tls static_site",
+				"waf_exemption_expiring: exemption of `azapi_resource.static_site` from `public` approved by security@contoso.com expires on 2025-06-20",
+			},
+		},
+		{
+			name: "expired",
+			exemptions: `
+exemption {
+  rule          = "public"
+  resource      = "azapi_resource.static_site"
+  justification = "Static website"
+  approver      = "security@contoso.com"
+  expires       = "2025-06-14"
+}`,
+			want: []string{
+				"public: public data",
+				"public: public static_site",
+				"tls: tls data",
+				"tls: tls static_site",
+				"waf_exemption_invalid: exemption of `azapi_resource.static_site` from `public` approved by security@contoso.com expired on 2025-06-14",
+			},
+		},
+		{
+			name: "unused",
+			exemptions: `
+exemption {
+  rule          = "public"
+  resource      = "azapi_resource.removed"
+  justification = "Static website"
+  approver      = "security@contoso.com"
+  expires       = "2025-12-31"
+}
+
+exemption {
+  rule          = "disabled"
+  resource      = "azapi_resource.removed"
+  justification = "Not checked"
+  approver      = "security@contoso.com"
+  expires       = "2025-12-31"
+}`,
+			want: []string{
+				"public: public data",
+				"public: public static_site",
+				"tls: tls data",
+				"tls: tls static_site",
+				"waf_exemption_invalid: exemption of `azapi_resource.removed` from `public` does not match any issue",
+			},
+		},
+		{
+			name: "unknown rule",
+			exemptions: `
+exemption {
+  rule          = "unknown"
+  resource      = "azapi_resource.static_site"
+  justification = "Static website"
+  approver      = "security@contoso.com"
+  expires       = "2025-12-31"
+}`,
+			wantErr: "exemptions.hcl:2,1-10: exemption for unknown rule `unknown`",
+		},
+		{
+			name: "missing justification",
+			exemptions: `
+exemption {
+  rule          = "public"
+  resource      = "azapi_resource.static_site"
+  justification = ""
+  approver      = "security@contoso.com"
+  expires       = "2025-12-31"
+}`,
+			wantErr: "invalid exemption: `justification` must not be empty",
+		},
+		{
+			name: "invalid date",
+			exemptions: `
+exemption {
+  rule          = "public"
+  resource      = "azapi_resource.static_site"
+  justification = "Static website"
+  approver      = "security@contoso.com"
+  expires       = "31/12/2025"
+}`,
+			wantErr: "`expires` must be a date in YYYY-MM-DD format",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filename := tc.filename
+			if filename == "" {
+				filename = "exemptions.hcl"
+			}
+			stubs := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+				"main.tf": exemptionsConfig,
+				filename:  tc.exemptions,
+			}))
+			stubs.Stub(&now, func() time.Time { return time.Date(2025, 6, 15, 12, 0, 0, 0, time.Local) })
+			defer stubs.Reset()
+
+			rs := New("test", "0.0.0", []tflint.Rule{
+				&resourcesRule{testRule{name: "public"}},
+				&resourcesRule{testRule{name: "tls"}},
+				&resourcesRule{testRule{name: "disabled"}},
+			})
+			global := &tflint.Config{Rules: map[string]*tflint.RuleConfig{
+				"disabled": {Name: "disabled", Enabled: false},
+			}}
+			require.NoError(t, rs.ApplyGlobalConfig(global))
+			err := decodeConfig(t, rs, `exemptions = "`+filename+`"`)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			runner := helper.TestRunner(t, map[string]string{})
+			for _, rule := range rs.EnabledRules {
+				require.NoError(t, rule.Check(runner))
+			}
+			var got []string
+			for _, issue := range runner.Issues {
+				got = append(got, issue.Rule.Name()+": "+issue.Message)
+			}
+			assert.ElementsMatch(t, tc.want, got)
+		})
+	}
+}
+
+func TestExemptionsIgnored(t *testing.T) {
+	rs := New("test", "0.0.0", testRules())
+	require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+	rs.IgnoreExemptions()
+	require.NoError(t, decodeConfig(t, rs, `exemptions = "missing.hcl"`))
+	assert.Equal(t, []string{"security", "reliability", "no_metadata", TagExemptionRuleName}, enabledNames(rs))
+}
+
+func TestBlockAddress(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{"main.tf": `
+resource "azapi_resource" "sa" {
+  body = {}
+}
+
+data "azapi_resource" "rg" {}
+
+module "app" {
+  source = "./app"
+}
+
+variable "tls" {}
+
+locals {
+  tls = "TLS1_2"
+}
+`, "app/main.tf": ``}))
+	defer stub.Reset()
+	files, diags := modulecontent.FetchFiles(helper.TestRunner(t, map[string]string{}))
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	for line, want := range map[int]string{
+		1:  "",
+		3:  "azapi_resource.sa",
+		6:  "data.azapi_resource.rg",
+		9:  "module.app",
+		12: "var.tls",
+		15: "",
+		16: "",
+	} {
+		rng := hcl.Range{Filename: "main.tf", Start: hcl.Pos{Line: line, Column: 3}}
+		assert.Equal(t, want, blockAddress(files, rng), "line %d", line)
+	}
+}
@@ -0,0 +1,92 @@
+package ruleset
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/tidwall/gjson"
+	"github.com/zclconf/go-cty/cty"
+)
+
+//go:embed profiles/*.hcl
+var profilesFs embed.FS
+
+// Profile is a named set of rule settings bundled with the plugin, e.g. `production`.
+// Profiles are HCL files in the `profiles` directory, selected with `profile = "<name>"` in the plugin block.
+type Profile struct {
+	Name  string
+	Rules []ProfileRule `hclext:"rule,block"`
+}
+
+// ProfileRule enables or disables a rule in a profile and optionally sets its expected values.
+type ProfileRule struct {
+	Name     string    `hclext:"name,label"`
+	Enabled  bool      `hclext:"enabled"`
+	Expected cty.Value `hclext:"expected,optional"`
+}
+
+// ExpectedRule is implemented by rules whose expected values can be set by a profile, e.g. rules.AzApiRule.
+// The values of the profile are provided to the rules in the RunConfig, as the rules are shared by the rulesets.
+type ExpectedRule interface {
+	Expected() []gjson.Result
+}
+
+// ProfileNames returns the names of the profiles bundled with the plugin.
+func ProfileNames() []string {
+	entries, _ := profilesFs.ReadDir("profiles")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".hcl"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadProfile loads the bundled profile with the given name.
+func LoadProfile(name string) (*Profile, error) {
+	src, err := profilesFs.ReadFile(path.Join("profiles", name+".hcl"))
+	if err != nil {
+		return nil, fmt.Errorf("unknown profile `%s`, valid profiles are: %s", name, strings.Join(ProfileNames(), ", "))
+	}
+	file, diags := hclsyntax.ParseConfig(src, name+".hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	profile := &Profile{Name: name}
+	body, diags := hclext.Content(file.Body, hclext.ImpliedBodySchema(profile))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if diags := hclext.DecodeBody(body, nil, profile); diags.HasErrors() {
+		return nil, diags
+	}
+	return profile, nil
+}
+
+// Rule returns the settings for the rule with the given name, or nil if the profile does not configure it.
+func (p *Profile) Rule(name string) *ProfileRule {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Rules {
+		if p.Rules[i].Name == name {
+			return &p.Rules[i]
+		}
+	}
+	return nil
+}
+
+// ExpectedResults returns the expected values of the rule as results, or nil if they are not set.
+func (pr *ProfileRule) ExpectedResults() ([]gjson.Result, error) {
+	if pr.Expected == cty.NilVal {
+		return nil, nil
+	}
+	return blockquery.NewResultsFromCty(pr.Expected)
+}
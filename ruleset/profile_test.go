@@ -0,0 +1,126 @@
+package ruleset
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/tidwall/gjson"
+)
+
+type expectedRule struct {
+	testRule
+	expected []gjson.Result
+}
+
+func (r *expectedRule) Expected() []gjson.Result {
+	return r.expected
+}
+
+func TestLoadProfile(t *testing.T) {
+	assert.Equal(t, []string{"baseline", "mission-critical", "production"}, ProfileNames())
+	for _, name := range ProfileNames() {
+		p, err := LoadProfile(name)
+		require.NoError(t, err, name)
+		assert.NotEmpty(t, p.Rules, name)
+	}
+	_, err := LoadProfile("unknown")
+	assert.ErrorContains(t, err, "unknown profile `unknown`")
+}
+
+func TestProfileRulesExist(t *testing.T) {
+	names := make(map[string]bool, len(rules.Rules))
+	for _, r := range rules.Rules {
+		names[r.Name()] = true
+	}
+	for _, name := range ProfileNames() {
+		p, err := LoadProfile(name)
+		require.NoError(t, err)
+		for _, pr := range p.Rules {
+			assert.True(t, names[pr.Name], "profile %s configures unknown rule %s", name, pr.Name)
+		}
+	}
+}
+
+func TestApplyConfigProfile(t *testing.T) {
+	testCases := []struct {
+		name         string
+		globalConfig *tflint.Config
+		config       string
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name:         "baseline disables rules",
+			globalConfig: &tflint.Config{},
+			config:       `profile = "baseline"`,
+			want:         []string{"azapi_storage_account_min_tls_version", "zone", TagExemptionRuleName},
+		},
+		{
+			name:         "pillar takes precedence over profile",
+			globalConfig: &tflint.Config{},
+			config: `
+profile = "baseline"
+
+pillar "security" {
+  enabled = true
+}`,
+			want: []string{"azapi_storage_account_min_tls_version", "azapi_storage_account_public_network_access", "zone", TagExemptionRuleName},
+		},
+		{
+			name: "rule block takes precedence over profile",
+			globalConfig: &tflint.Config{
+				Rules: map[string]*tflint.RuleConfig{
+					"azapi_storage_account_min_tls_version": {Name: "azapi_storage_account_min_tls_version", Enabled: false},
+				},
+			},
+			config: `profile = "baseline"`,
+			want:   []string{"zone", TagExemptionRuleName},
+		},
+		{
+			name:         "unknown profile",
+			globalConfig: &tflint.Config{},
+			config:       `profile = "unknown"`,
+			wantErr:      true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := New("test", "0.0.0", []tflint.Rule{
+				&testRule{name: "azapi_storage_account_min_tls_version", metadata: &waf.Metadata{Pillar: waf.Security}},
+				&testRule{name: "azapi_storage_account_public_network_access", metadata: &waf.Metadata{Pillar: waf.Security}},
+				&testRule{name: "zone"},
+			})
+			require.NoError(t, rs.ApplyGlobalConfig(tc.globalConfig))
+			err := decodeConfig(t, rs, tc.config)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, enabledNames(rs))
+		})
+	}
+}
+
+func TestApplyConfigProfileExpected(t *testing.T) {
+	rule := &expectedRule{testRule: testRule{name: "azapi_storage_account_zone_redundancy"}}
+	rs := New("test", "0.0.0", []tflint.Rule{rule})
+	require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+	require.NoError(t, decodeConfig(t, rs, `profile = "mission-critical"`))
+	expected := rs.RunConfig().Expected[rule.Name()]
+	got := make([]string, len(expected))
+	for i, r := range expected {
+		got[i] = r.String()
+	}
+	assert.Equal(t, []string{"Standard_GZRS", "Standard_RAGZRS"}, got)
+	assert.Nil(t, rule.expected, "the profile must not change the shared rule")
+
+	other := New("test", "0.0.0", []tflint.Rule{rule})
+	require.NoError(t, other.ApplyGlobalConfig(&tflint.Config{}))
+	require.NoError(t, decodeConfig(t, other, ""))
+	assert.NotContains(t, other.RunConfig().Expected, rule.Name(), "the profile of a ruleset must not apply to another")
+}
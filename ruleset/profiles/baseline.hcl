@@ -0,0 +1,62 @@
+# The baseline profile is intended for development and sandbox environments.
+# It checks encryption in transit but does not require private networking or zone redundancy.
+
+rule "azapi_storage_account_min_tls_version" {
+  enabled = true
+}
+
+rule "azapi_storage_account_https_traffic_only" {
+  enabled = true
+}
+
+rule "azapi_storage_account_public_network_access" {
+  enabled = false
+}
+
+rule "azapi_storage_account_network_restricted" {
+  enabled = false
+}
+
+rule "azapi_storage_account_zone_redundancy" {
+  enabled = false
+}
+
+rule "azapi_key_vault_purge_protection" {
+  enabled = false
+}
+
+rule "azapi_key_vault_public_network_access" {
+  enabled = false
+}
+
+rule "azapi_private_endpoint_required" {
+  enabled = false
+}
+
+rule "azapi_vnet_address_space_overlap" {
+  enabled = true
+}
+
+rule "azapi_resource_body_schema" {
+  enabled = true
+}
+
+rule "azapi_api_version" {
+  enabled = true
+}
+
+rule "azapi_api_version_preview" {
+  enabled = false
+}
+
+rule "avm_storage_account_public_network_access" {
+  enabled = false
+}
+
+rule "avm_key_vault_public_network_access" {
+  enabled = false
+}
+
+rule "avm_key_vault_diagnostic_settings" {
+  enabled = true
+}
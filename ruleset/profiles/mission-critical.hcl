@@ -0,0 +1,62 @@
+# The mission-critical profile extends production by requiring geo-zone-redundant storage.
+
+rule "azapi_storage_account_min_tls_version" {
+  enabled = true
+}
+
+rule "azapi_storage_account_https_traffic_only" {
+  enabled = true
+}
+
+rule "azapi_storage_account_public_network_access" {
+  enabled = true
+}
+
+rule "azapi_storage_account_network_restricted" {
+  enabled = true
+}
+
+rule "azapi_storage_account_zone_redundancy" {
+  enabled  = true
+  expected = ["Standard_GZRS", "Standard_RAGZRS"]
+}
+
+rule "azapi_key_vault_purge_protection" {
+  enabled = true
+}
+
+rule "azapi_key_vault_public_network_access" {
+  enabled = true
+}
+
+rule "azapi_private_endpoint_required" {
+  enabled = true
+}
+
+rule "azapi_vnet_address_space_overlap" {
+  enabled = true
+}
+
+rule "azapi_resource_body_schema" {
+  enabled = true
+}
+
+rule "azapi_api_version" {
+  enabled = true
+}
+
+rule "azapi_api_version_preview" {
+  enabled = true
+}
+
+rule "avm_storage_account_public_network_access" {
+  enabled = true
+}
+
+rule "avm_key_vault_public_network_access" {
+  enabled = true
+}
+
+rule "avm_key_vault_diagnostic_settings" {
+  enabled = true
+}
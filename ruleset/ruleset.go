@@ -0,0 +1,304 @@
+package ruleset
+
+import (
+	"fmt"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/azpolicy"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/logger"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/tidwall/gjson"
+)
+
+// RuleSet is the ruleset served by the plugin.
+// Rules are enabled in order of precedence by the `--only` option, a `rule` block,
+// a `pillar` block in the plugin configuration, the selected profile, and finally the rule's own default.
+type RuleSet struct {
+	tflint.BuiltinRuleSet
+	globalConfig     *tflint.Config
+	config           *Config
+	ignoreExemptions bool
+	baseline         *baselineSet
+	policyRules      []tflint.Rule    // The rules loaded from the policies directories by ApplyConfig.
+	runConfig        *rules.RunConfig // The configuration of the runs of the rules, see RunConfig.
+	tags             *tagCache        // The tags fetched in a run of the rules, for the tag exemptions.
+}
+
+var _ tflint.RuleSet = &RuleSet{}
+
+// New returns a new RuleSet with the given rules.
+func New(name, version string, rules []tflint.Rule) *RuleSet {
+	return &RuleSet{
+		BuiltinRuleSet: tflint.BuiltinRuleSet{
+			Name:    name,
+			Version: version,
+			Rules:   rules,
+		},
+		globalConfig: &tflint.Config{},
+		config:       &Config{},
+	}
+}
+
+// ApplyGlobalConfig applies the common config and keeps it so that ApplyConfig can respect rule blocks.
+func (r *RuleSet) ApplyGlobalConfig(config *tflint.Config) error {
+	r.globalConfig = config
+	return r.BuiltinRuleSet.ApplyGlobalConfig(config)
+}
+
+// RuleNames returns the names of the rules, including the rules that report on exemptions
+// and the rules loaded from the policies directories, so that tflint accepts `rule` blocks for them.
+// tflint validates the rule blocks after ApplyConfig has loaded the policies.
+func (r *RuleSet) RuleNames() []string {
+	names := append(r.BuiltinRuleSet.RuleNames(), ExemptionExpiringRuleName, ExemptionInvalidRuleName, TagExemptionRuleName)
+	for _, rule := range r.policyRules {
+		names = append(names, rule.Name())
+	}
+	return names
+}
+
+// IgnoreExemptions makes ApplyConfig ignore the exemptions and baseline files, e.g. when the configuration is generated from a plan
+// and does not have the addresses that they match.
+func (r *RuleSet) IgnoreExemptions() {
+	r.ignoreExemptions = true
+}
+
+// ConfigSchema returns the schema of the plugin configuration, see Config.
+func (r *RuleSet) ConfigSchema() *hclext.BodySchema {
+	return hclext.ImpliedBodySchema(&Config{})
+}
+
+// ApplyConfig decodes the plugin configuration and applies it to the enabled rules.
+func (r *RuleSet) ApplyConfig(body *hclext.BodyContent) error {
+	config := &Config{}
+	if diags := hclext.DecodeBody(body, nil, config); diags.HasErrors() {
+		return diags
+	}
+	r.config = config
+	if err := validateScenarios(config.Scenarios); err != nil {
+		return err
+	}
+
+	var profile *Profile
+	if config.Profile != "" {
+		var err error
+		if profile, err = LoadProfile(config.Profile); err != nil {
+			return err
+		}
+	}
+
+	pillars := make(map[waf.Pillar]bool, len(config.Pillars))
+	for _, pc := range config.Pillars {
+		p, err := waf.ParsePillar(pc.Name)
+		if err != nil {
+			return fmt.Errorf("invalid pillar block: %w", err)
+		}
+		pillars[p] = pc.Enabled
+	}
+
+	allRules, err := r.withPolicyRules(config)
+	if err != nil {
+		return err
+	}
+
+	var notice *tagExemptionRule
+	if r.builtinRuleEnabled(TagExemptionRuleName) {
+		notice = newTagExemptionRule(config.ExemptionTag)
+	}
+	r.tags = &tagCache{}
+	r.tags.reset()
+
+	r.EnabledRules = []tflint.Rule{}
+	r.runConfig = &rules.RunConfig{Enabled: map[string]bool{}, Expected: map[string][]gjson.Result{}}
+	var azApiRules []*rules.AzApiRule
+	for _, rule := range allRules {
+		enabled := rule.Enabled()
+		if r.globalConfig.DisabledByDefault {
+			enabled = false
+		}
+		if pr := profile.Rule(rule.Name()); pr != nil {
+			enabled = pr.Enabled
+			expected, err := profileExpected(rule, pr)
+			if err != nil {
+				return err
+			}
+			if expected != nil {
+				r.runConfig.Expected[rule.Name()] = expected
+			}
+		}
+		if md := waf.FromRule(rule); md != nil {
+			if pillarEnabled, ok := pillars[md.Pillar]; ok {
+				enabled = pillarEnabled
+			}
+		}
+		if r.hasRuleConfig(rule.Name()) {
+			enabled = r.ruleConfigEnabled(rule.Name())
+		}
+		if !enabled {
+			continue
+		}
+		r.runConfig.Enabled[rule.Name()] = true
+		if ar, ok := rule.(*rules.AzApiRule); ok {
+			azApiRules = append(azApiRules, ar)
+		}
+		if notice != nil {
+			rule = &tagExemptRule{Rule: rule, notice: notice, cache: r.tags}
+		}
+		if len(config.Scenarios) > 0 {
+			rule = newScenarioRule(rule, config.Scenarios)
+		}
+		r.EnabledRules = append(r.EnabledRules, rule)
+	}
+	r.runConfig.Engine = rules.NewEngine(azApiRules)
+
+	// The exemptions file applies before the baseline, so that exempted issues are not added to it,
+	// and the rules that report on the exemptions run last.
+	var exemptionRules []tflint.Rule
+	if config.Exemptions != "" && !r.ignoreExemptions {
+		var err error
+		if exemptionRules, err = r.applyExemptions(config, allRules); err != nil {
+			return err
+		}
+	}
+	r.baseline = nil
+	if config.Baseline != "" && !r.ignoreExemptions {
+		if err := r.applyBaseline(config); err != nil {
+			return err
+		}
+	}
+	if notice != nil {
+		r.EnabledRules = append(r.EnabledRules, notice)
+	}
+	r.EnabledRules = append(r.EnabledRules, exemptionRules...)
+	return nil
+}
+
+// RunConfig returns the configuration of the runs of the rules by ApplyConfig, which NewRunner provides to the rules.
+func (r *RuleSet) RunConfig() *rules.RunConfig {
+	return r.runConfig
+}
+
+// withPolicyRules returns the rules of the ruleset followed by the rules of the Rego policies and Azure Policy definitions directories, if configured.
+// The loaded rules are kept for RuleNames.
+// Azure Policy definitions that cannot be translated are skipped, see azpolicy.Import, and logged as warnings.
+func (r *RuleSet) withPolicyRules(config *Config) ([]tflint.Rule, error) {
+	r.policyRules = nil
+	all := append([]tflint.Rule{}, r.Rules...)
+	if config.RegoPolicies != "" {
+		regoRules, err := rules.LoadRegoRules(config.RegoPolicies)
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range regoRules {
+			all = append(all, rule)
+			r.policyRules = append(r.policyRules, rule)
+		}
+	}
+	if config.AzurePolicies != "" {
+		res, err := azpolicy.Import(config.AzurePolicies)
+		if err != nil {
+			return nil, err
+		}
+		for _, rd := range res.Rules {
+			rule, err := rd.Rule()
+			if err != nil {
+				return nil, fmt.Errorf("could not create rule of policy `%s`: %s", rd.DisplayName, err)
+			}
+			all = append(all, rule)
+			r.policyRules = append(r.policyRules, rule)
+		}
+		for _, s := range res.Skipped {
+			logger.Warn(fmt.Sprintf("skipped Azure Policy definition `%s` in %s: %s", s.Policy, s.File, s.Reason))
+		}
+		for _, alias := range res.UnknownAliases {
+			logger.Warn(fmt.Sprintf("unknown Azure Policy alias `%s`", alias))
+		}
+	}
+	return all, nil
+}
+
+// applyExemptions loads the exemptions file and wraps the enabled rules to drop the exempted issues.
+// Exemptions can only be for one of the known rules.
+// It returns the enabled rules that report on the exemptions, which must run after the exempted rules.
+func (r *RuleSet) applyExemptions(config *Config, known []tflint.Rule) ([]tflint.Rule, error) {
+	exemptions, err := LoadExemptions(config.Exemptions)
+	if err != nil {
+		return nil, err
+	}
+	warningDays := config.ExemptionWarningDays
+	if warningDays == 0 {
+		warningDays = DefaultExemptionWarningDays
+	}
+	set, err := newExemptionSet(exemptions, warningDays, known)
+	if err != nil {
+		return nil, err
+	}
+	for i, rule := range r.EnabledRules {
+		set.enabled[rule.Name()] = true
+		r.EnabledRules[i] = &exemptRule{Rule: rule, set: set}
+	}
+	var rules []tflint.Rule
+	for _, rule := range []tflint.Rule{newExemptionExpiringRule(set), newExemptionInvalidRule(set)} {
+		if r.builtinRuleEnabled(rule.Name()) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// applyBaseline loads the baseline file and wraps the enabled rules to drop the issues in the baseline.
+func (r *RuleSet) applyBaseline(config *Config) error {
+	baseline, err := LoadBaseline(config.Baseline)
+	if err != nil {
+		return err
+	}
+	r.baseline = newBaselineSet(baseline)
+	for i, rule := range r.EnabledRules {
+		r.EnabledRules[i] = &baselineRule{Rule: rule, set: r.baseline}
+	}
+	return nil
+}
+
+// builtinRuleEnabled returns whether a rule of the ruleset itself, which is enabled by default, is enabled by the tflint config.
+func (r *RuleSet) builtinRuleEnabled(name string) bool {
+	if r.hasRuleConfig(name) {
+		return r.ruleConfigEnabled(name)
+	}
+	return !r.globalConfig.DisabledByDefault
+}
+
+// profileExpected returns the expected values of the rule from the profile, if both support it, otherwise nil.
+func profileExpected(rule tflint.Rule, pr *ProfileRule) ([]gjson.Result, error) {
+	if _, ok := rule.(ExpectedRule); !ok {
+		return nil, nil
+	}
+	expected, err := pr.ExpectedResults()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected values for rule `%s`: %s", pr.Name, err)
+	}
+	return expected, nil
+}
+
+// hasRuleConfig reports whether the rule is configured by the `--only` option or a `rule` block,
+// which take precedence over the plugin configuration.
+func (r *RuleSet) hasRuleConfig(name string) bool {
+	if len(r.globalConfig.Only) > 0 {
+		return true
+	}
+	_, ok := r.globalConfig.Rules[name]
+	return ok
+}
+
+// ruleConfigEnabled returns whether the rule is enabled by the `--only` option or its `rule` block.
+func (r *RuleSet) ruleConfigEnabled(name string) bool {
+	if len(r.globalConfig.Only) > 0 {
+		for _, only := range r.globalConfig.Only {
+			if only == name {
+				return true
+			}
+		}
+		return false
+	}
+	return r.globalConfig.Rules[name].Enabled
+}
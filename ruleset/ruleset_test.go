@@ -0,0 +1,312 @@
+package ruleset
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+type testRule struct {
+	tflint.DefaultRule
+	name     string
+	metadata *waf.Metadata
+}
+
+func (r *testRule) Name() string                { return r.name }
+func (r *testRule) Enabled() bool               { return true }
+func (r *testRule) Severity() tflint.Severity   { return tflint.ERROR }
+func (r *testRule) Metadata() interface{}       { return r.metadata }
+func (r *testRule) Check(_ tflint.Runner) error { return nil }
+
+func testRules() []tflint.Rule {
+	return []tflint.Rule{
+		&testRule{name: "security", metadata: &waf.Metadata{Pillar: waf.Security}},
+		&testRule{name: "reliability", metadata: &waf.Metadata{Pillar: waf.Reliability}},
+		&testRule{name: "no_metadata"},
+	}
+}
+
+func decodeConfig(t *testing.T, rs *RuleSet, src string) error {
+	file, diags := hclsyntax.ParseConfig([]byte(src), "plugin.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	body, diags := hclext.Content(file.Body, rs.ConfigSchema())
+	require.False(t, diags.HasErrors(), diags.Error())
+	return rs.ApplyConfig(body)
+}
+
+func enabledNames(rs *RuleSet) []string {
+	names := make([]string, len(rs.EnabledRules))
+	for i, r := range rs.EnabledRules {
+		names[i] = r.Name()
+	}
+	return names
+}
+
+func TestApplyConfigPillars(t *testing.T) {
+	testCases := []struct {
+		name         string
+		globalConfig *tflint.Config
+		config       string
+		want         []string
+		wantErr      bool
+	}{
+		{
+			name:         "no config",
+			globalConfig: &tflint.Config{},
+			want:         []string{"security", "reliability", "no_metadata", TagExemptionRuleName},
+		},
+		{
+			name:         "pillar disabled",
+			globalConfig: &tflint.Config{},
+			config: `
+pillar "security" {
+  enabled = false
+}`,
+			want: []string{"reliability", "no_metadata", TagExemptionRuleName},
+		},
+		{
+			name:         "pillar enabled with disabled by default",
+			globalConfig: &tflint.Config{DisabledByDefault: true},
+			config: `
+pillar "RE" {
+  enabled = true
+}`,
+			want: []string{"reliability"},
+		},
+		{
+			name: "rule block takes precedence over pillar",
+			globalConfig: &tflint.Config{
+				Rules: map[string]*tflint.RuleConfig{
+					"security": {Name: "security", Enabled: true},
+				},
+			},
+			config: `
+pillar "Security" {
+  enabled = false
+}`,
+			want: []string{"security", "reliability", "no_metadata", TagExemptionRuleName},
+		},
+		{
+			name:         "only takes precedence over pillar",
+			globalConfig: &tflint.Config{Only: []string{"reliability"}},
+			config: `
+pillar "reliability" {
+  enabled = true
+}`,
+			want: []string{"reliability"},
+		},
+		{
+			name:         "unknown pillar",
+			globalConfig: &tflint.Config{},
+			config: `
+pillar "unknown" {
+  enabled = false
+}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := New("test", "0.0.0", testRules())
+			require.NoError(t, rs.ApplyGlobalConfig(tc.globalConfig))
+			err := decodeConfig(t, rs, tc.config)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, enabledNames(rs))
+		})
+	}
+}
+
+func TestApplyConfigRegoPolicies(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		"main.tf": exemptionsConfig,
+		"policy/storage.rego": `
+package storage
+
+import rego.v1
+
+deny contains sprintf("%s is public", [input.address]) if {
+  input.type == "Microsoft.Storage/storageAccounts"
+}`,
+		"exemptions.hcl": `
+exemption {
+  rule          = "rego_storage"
+  resource      = "azapi_resource.static_site"
+  justification = "Static website"
+  approver      = "security@contoso.com"
+  expires       = "2099-12-31"
+}`,
+	}))
+	defer stub.Reset()
+
+	rs := New("test", "0.0.0", testRules())
+	require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+	require.NoError(t, decodeConfig(t, rs, `
+rego_policies = "policy"
+exemptions    = "exemptions.hcl"
+`))
+	assert.Equal(t, []string{"security", "reliability", "no_metadata", "rego_storage", TagExemptionRuleName, ExemptionExpiringRuleName, ExemptionInvalidRuleName}, enabledNames(rs))
+
+	runner := helper.TestRunner(t, map[string]string{})
+	for _, rule := range rs.EnabledRules {
+		require.NoError(t, rule.Check(runner))
+	}
+	var got []string
+	for _, issue := range runner.Issues {
+		got = append(got, issue.Rule.Name()+": "+issue.Message)
+	}
+	assert.Equal(t, []string{"rego_storage: azapi_resource.data is public"}, got)
+}
+
+func TestRuleNamesRegoPolicies(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		"policy/storage.rego": `
+package storage
+
+import rego.v1
+
+deny contains "public" if {
+  input.type == "Microsoft.Storage/storageAccounts"
+}`,
+	}))
+	defer stub.Reset()
+
+	rs := New("test", "0.0.0", testRules())
+	require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{
+		Rules: map[string]*tflint.RuleConfig{"rego_storage": {Name: "rego_storage", Enabled: false}},
+	}))
+	require.NoError(t, decodeConfig(t, rs, `rego_policies = "policy"`))
+	assert.Contains(t, rs.RuleNames(), "rego_storage", "the rule block of a Rego rule must be accepted")
+	assert.NotContains(t, enabledNames(rs), "rego_storage")
+}
+
+func TestApplyConfigAzurePolicies(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		"main.tf": exemptionsConfig,
+		"policies/storage_https.json": `{
+  "properties": {
+    "displayName": "Secure transfer to storage accounts should be enabled",
+    "policyRule": {
+      "if": {
+        "allOf": [
+          {"field": "type", "equals": "Microsoft.Storage/storageAccounts"},
+          {"field": "Microsoft.Storage/storageAccounts/supportsHttpsTrafficOnly", "notEquals": true}
+        ]
+      },
+      "then": {"effect": "Deny"}
+    }
+  }
+}`,
+		"policies/storage_name.json": `{
+  "properties": {
+    "displayName": "Storage account names should start with st",
+    "policyRule": {
+      "if": {"field": "name", "notLike": "st*"},
+      "then": {"effect": "Deny"}
+    }
+  }
+}`,
+		"exemptions.hcl": `
+exemption {
+  rule          = "azpolicy_storage_https"
+  resource      = "azapi_resource.static_site"
+  justification = "Static website"
+  approver      = "security@contoso.com"
+  expires       = "2099-12-31"
+}`,
+	}))
+	defer stub.Reset()
+
+	rs := New("test", "0.0.0", testRules())
+	require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+	require.NoError(t, decodeConfig(t, rs, `
+azure_policies = "policies"
+exemptions     = "exemptions.hcl"
+`))
+	assert.Equal(t, []string{"security", "reliability", "no_metadata", "azpolicy_storage_https", TagExemptionRuleName, ExemptionExpiringRuleName, ExemptionInvalidRuleName}, enabledNames(rs))
+
+	runner := helper.TestRunner(t, map[string]string{})
+	for _, rule := range rs.EnabledRules {
+		require.NoError(t, rule.Check(runner))
+	}
+	var got []string
+	for _, issue := range runner.Issues {
+		got = append(got, issue.Rule.Name()+": "+issue.Message)
+	}
+	assert.Equal(t, []string{"azpolicy_storage_https: Resource does not comply with the policy `Secure transfer to storage accounts should be enabled`"}, got)
+	assert.Contains(t, rs.RuleNames(), "azpolicy_storage_https", "the rule block of an Azure Policy rule must be accepted")
+	assert.NotContains(t, rs.RuleNames(), "azpolicy_storage_name", "skipped policies have no rule")
+}
+
+func TestVariableRulesSkipDisabledBodyRules(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		"main.tf": `
+variable "min_tls_version" {
+  type    = string
+  default = "TLS1_0"
+}
+
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-05-01"
+  name = "sa"
+  body = {
+    properties = {
+      minimumTlsVersion = var.min_tls_version
+    }
+  }
+}`,
+	}))
+	defer stub.Reset()
+
+	testCases := []struct {
+		name    string
+		enabled bool
+		want    int
+	}{
+		{name: "body rule enabled", enabled: true, want: 1},
+		{name: "body rule disabled", enabled: false, want: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := New("test", "0.0.0", rules.Rules)
+			require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{
+				DisabledByDefault: true,
+				Rules: map[string]*tflint.RuleConfig{
+					"azapi_variable_default":                {Name: "azapi_variable_default", Enabled: true},
+					"azapi_storage_account_min_tls_version": {Name: "azapi_storage_account_min_tls_version", Enabled: tc.enabled},
+				},
+			}))
+			require.NoError(t, decodeConfig(t, rs, ""))
+			tr := helper.TestRunner(t, map[string]string{})
+			runner, err := rs.NewRunner(tr)
+			require.NoError(t, err)
+			var got int
+			for _, rule := range rs.EnabledRules {
+				if rule.Name() != "azapi_variable_default" {
+					continue
+				}
+				require.NoError(t, rule.Check(runner))
+			}
+			for _, issue := range tr.Issues {
+				if issue.Rule.Name() == "azapi_variable_default" {
+					got++
+				}
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
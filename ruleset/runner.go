@@ -0,0 +1,25 @@
+package ruleset
+
+import (
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// ruleSetRunner provides the input values and the configuration of the run to the rules, see NewRunner.
+type ruleSetRunner struct {
+	tflint.Runner
+	vars   modulecontent.Variables
+	config *rules.RunConfig
+}
+
+var _ modulecontent.VariablesRunner = &ruleSetRunner{}
+var _ rules.RunConfigRunner = &ruleSetRunner{}
+
+func (r *ruleSetRunner) Variables() modulecontent.Variables {
+	return r.vars
+}
+
+func (r *ruleSetRunner) RunConfig() *rules.RunConfig {
+	return r.config
+}
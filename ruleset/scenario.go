@@ -0,0 +1,167 @@
+package ruleset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// ScenarioConfig is a named set of input values that the configuration is evaluated with,
+// in addition to the values of the tflint `config` block and the plugin's `varfile` attribute.
+//
+//	scenario "prod" {
+//	  varfile   = ["prod.tfvars"]
+//	  variables = ["sku=Premium"]
+//	}
+type ScenarioConfig struct {
+	Name     string   `hclext:"name,label"`
+	VarFiles []string `hclext:"varfile,optional"`   // Values files, after those of the ruleset.
+	Values   []string `hclext:"variables,optional"` // Values in `name=value` format, which take precedence over the files.
+}
+
+// Variables returns the input values of the scenario appended to the base values.
+func (s ScenarioConfig) Variables(base modulecontent.Variables) modulecontent.Variables {
+	return modulecontent.Variables{
+		Files:  append(append([]string{}, base.Files...), s.VarFiles...),
+		Values: append(append([]string{}, base.Values...), s.Values...),
+	}
+}
+
+// validateScenarios checks that each scenario has a unique, non-empty name.
+func validateScenarios(scenarios []ScenarioConfig) error {
+	seen := make(map[string]bool, len(scenarios))
+	for _, s := range scenarios {
+		if s.Name == "" {
+			return fmt.Errorf("scenario name must not be empty")
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("duplicate scenario `%s`", s.Name)
+		}
+		seen[s.Name] = true
+	}
+	return nil
+}
+
+// scenarioRule runs a rule once per scenario, each with its own evaluator.
+// An issue that is emitted identically in every scenario is emitted once, with its fix.
+// Other issues are emitted once per distinct message and range, tagged with the names of the scenarios they occur in,
+// and without a fix as fixing them would change the configuration for the other scenarios.
+// A check is recorded once per rule and resource address, and passes only if it passes in every scenario that records it.
+type scenarioRule struct {
+	tflint.Rule
+	scenarios []ScenarioConfig
+}
+
+// newScenarioRule returns a rule that runs rule once per scenario.
+func newScenarioRule(rule tflint.Rule, scenarios []ScenarioConfig) *scenarioRule {
+	return &scenarioRule{Rule: rule, scenarios: scenarios}
+}
+
+func (r *scenarioRule) Check(runner tflint.Runner) error {
+	var base modulecontent.Variables
+	if vr, ok := runner.(modulecontent.VariablesRunner); ok {
+		base = vr.Variables()
+	}
+	var issues []*scenarioIssue
+	index := map[string]*scenarioIssue{}
+	var checks []*score.Check
+	checkIndex := map[string]*score.Check{}
+	for _, s := range r.scenarios {
+		sr := &scenarioRunner{Runner: runner, vars: s.Variables(base)}
+		if err := r.Rule.Check(sr); err != nil {
+			return fmt.Errorf("scenario `%s`: %s", s.Name, err)
+		}
+		for i := range sr.checks {
+			c := &sr.checks[i]
+			key := c.Rule.Name() + "\x00" + c.Address
+			if existing, ok := checkIndex[key]; ok {
+				if existing.Passed && !c.Passed {
+					existing.Range = c.Range
+				}
+				existing.Passed = existing.Passed && c.Passed
+				continue
+			}
+			checkIndex[key] = c
+			checks = append(checks, c)
+		}
+		for _, issue := range sr.issues {
+			key := issue.message + "\x00" + issue.issueRange.String()
+			existing, ok := index[key]
+			if !ok {
+				index[key] = issue
+				issues = append(issues, issue)
+				existing = issue
+			}
+			if n := len(existing.scenarios); n == 0 || existing.scenarios[n-1] != s.Name {
+				existing.scenarios = append(existing.scenarios, s.Name)
+			}
+		}
+	}
+	for _, c := range checks {
+		score.Record(runner, *c)
+	}
+	for _, issue := range issues {
+		var err error
+		switch {
+		case len(issue.scenarios) < len(r.scenarios):
+			msg := fmt.Sprintf("[scenario: %s] %s", strings.Join(issue.scenarios, ", "), issue.message)
+			err = runner.EmitIssue(issue.rule, msg, issue.issueRange)
+		case issue.fix != nil:
+			err = runner.EmitIssueWithFix(issue.rule, issue.message, issue.issueRange, issue.fix)
+		default:
+			err = runner.EmitIssue(issue.rule, issue.message, issue.issueRange)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scenarioIssue is an issue emitted in one or more scenarios.
+type scenarioIssue struct {
+	rule       tflint.Rule
+	message    string
+	issueRange hcl.Range
+	fix        func(tflint.Fixer) error
+	scenarios  []string
+}
+
+// scenarioRunner provides the input values of a scenario to the evaluator and collects the emitted issues and the recorded checks.
+type scenarioRunner struct {
+	tflint.Runner
+	vars   modulecontent.Variables
+	issues []*scenarioIssue
+	checks []score.Check
+}
+
+var _ modulecontent.VariablesRunner = &scenarioRunner{}
+var _ score.Recorder = &scenarioRunner{}
+var _ rules.RunConfigRunner = &scenarioRunner{}
+
+func (r *scenarioRunner) Variables() modulecontent.Variables {
+	return r.vars
+}
+
+func (r *scenarioRunner) RecordCheck(c score.Check) {
+	r.checks = append(r.checks, c)
+}
+
+func (r *scenarioRunner) RunConfig() *rules.RunConfig {
+	return rules.RunConfigOf(r.Runner)
+}
+
+func (r *scenarioRunner) EmitIssue(rule tflint.Rule, message string, issueRange hcl.Range) error {
+	r.issues = append(r.issues, &scenarioIssue{rule: rule, message: message, issueRange: issueRange})
+	return nil
+}
+
+func (r *scenarioRunner) EmitIssueWithFix(rule tflint.Rule, message string, issueRange hcl.Range, fixFunc func(f tflint.Fixer) error) error {
+	r.issues = append(r.issues, &scenarioIssue{rule: rule, message: message, issueRange: issueRange, fix: fixFunc})
+	return nil
+}
@@ -0,0 +1,107 @@
+package ruleset
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// valuesRule emits an issue for each input value of the runner.
+type valuesRule struct {
+	testRule
+}
+
+func (r *valuesRule) Check(runner tflint.Runner) error {
+	vr, ok := runner.(modulecontent.VariablesRunner)
+	if !ok {
+		return nil
+	}
+	for _, v := range vr.Variables().Values {
+		if err := runner.EmitIssue(r, v, hcl.Range{Filename: "main.tf"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestScenarios(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "no scenarios",
+			want: []string{"base=1"},
+		},
+		{
+			name: "issues tagged with scenarios",
+			config: `
+scenario "dev" {
+  variables = ["tls=TLS1_0", "sku=Basic"]
+}
+
+scenario "test" {
+  variables = ["tls=TLS1_0", "sku=Basic"]
+}
+
+scenario "prod" {
+  varfile   = ["prod.tfvars"]
+  variables = ["tls=TLS1_0", "sku=Premium"]
+}`,
+			want: []string{
+				"base=1",
+				"tls=TLS1_0",
+				"[scenario: dev, test] sku=Basic",
+				"[scenario: prod] sku=Premium",
+			},
+		},
+		{
+			name: "duplicate scenario",
+			config: `
+scenario "dev" {}
+scenario "dev" {}`,
+			wantErr: "duplicate scenario `dev`",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := New("test", "0.0.0", []tflint.Rule{&valuesRule{testRule{name: "values"}}})
+			require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+			err := decodeConfig(t, rs, tc.config)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			runner := helper.TestRunner(t, map[string]string{})
+			vr := modulecontent.WithVariables(runner, modulecontent.Variables{Values: []string{"base=1"}})
+			for _, rule := range rs.EnabledRules {
+				require.NoError(t, rule.Check(vr))
+			}
+			var got []string
+			for _, issue := range runner.Issues {
+				assert.Equal(t, "values", issue.Rule.Name())
+				got = append(got, issue.Message)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestScenarioVariables(t *testing.T) {
+	s := ScenarioConfig{Name: "prod", VarFiles: []string{"prod.tfvars"}, Values: []string{"sku=Premium"}}
+	base := modulecontent.Variables{Files: []string{"a.tfvars"}, Values: []string{"tls=TLS1_2"}}
+	assert.Equal(t, modulecontent.Variables{
+		Files:  []string{"a.tfvars", "prod.tfvars"},
+		Values: []string{"tls=TLS1_2", "sku=Premium"},
+	}, s.Variables(base))
+	assert.Equal(t, []string{"a.tfvars"}, base.Files)
+}
@@ -0,0 +1,327 @@
+package ruleset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/score"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/hclext"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/terraform-linters/tflint/terraform"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+const (
+	// TagExemptionRuleName is the rule that reports the issues exempted by a tag of the resource, as notices.
+	TagExemptionRuleName = "waf_tag_exemption"
+	// DefaultExemptionTag is the tag that lists the exemptions of a resource.
+	DefaultExemptionTag = "waf-exempt"
+
+	tagExemptionsLink = "https://github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitected#tag-exemptions"
+)
+
+// tagExemptionRule is the rule of the notices emitted instead of the issues exempted by a tag.
+// The notices are emitted by the wrapped rules, so its own check does nothing.
+type tagExemptionRule struct {
+	tflint.DefaultRule
+	tag string
+}
+
+// newTagExemptionRule returns the rule of the notices for the tag, DefaultExemptionTag if it is empty.
+func newTagExemptionRule(tag string) *tagExemptionRule {
+	if tag == "" {
+		tag = DefaultExemptionTag
+	}
+	return &tagExemptionRule{tag: tag}
+}
+
+func (r *tagExemptionRule) Name() string {
+	return TagExemptionRuleName
+}
+
+func (r *tagExemptionRule) Enabled() bool {
+	return true
+}
+
+func (r *tagExemptionRule) Severity() tflint.Severity {
+	return tflint.NOTICE
+}
+
+func (r *tagExemptionRule) Link() string {
+	return tagExemptionsLink
+}
+
+func (r *tagExemptionRule) Check(_ tflint.Runner) error {
+	return nil
+}
+
+// tagExemptRule runs a rule and emits the issues exempted by a tag of their `azapi_resource` as notices of the tag exemption rule.
+// The tags are evaluated with the input values of the runner, so it is run once per scenario.
+type tagExemptRule struct {
+	tflint.Rule
+	notice *tagExemptionRule
+	cache  *tagCache
+}
+
+func (r *tagExemptRule) Check(runner tflint.Runner) error {
+	return r.Rule.Check(&tagExemptionRunner{Runner: runner, notice: r.notice, cache: r.cache})
+}
+
+// tagCache holds the files and the tags fetched in a run of the rules, so that they are fetched once for all of the rules.
+// It is reset before each run, see RuleSet.NewRunner.
+type tagCache struct {
+	files map[string]*hcl.File
+	tags  map[string]map[string][]string // The exemptions in the tag of each `azapi_resource` instance by address, by the key of the input values, see modulecontent.VariablesKey.
+}
+
+// reset drops the files and tags of the last run.
+func (c *tagCache) reset() {
+	c.files = nil
+	c.tags = make(map[string]map[string][]string)
+}
+
+// tagExemptionRunner emits the issues exempted by a tag as notices, and the others with the underlying runner.
+// The instance of an issue is found from the failed checks recorded at the issue's range, see issueInstance.
+type tagExemptionRunner struct {
+	tflint.Runner
+	notice *tagExemptionRule
+	cache  *tagCache
+	failed map[string][]string // The addresses of the failed checks by the range of their issues, in the order they were recorded.
+}
+
+var _ modulecontent.VariablesRunner = &tagExemptionRunner{}
+var _ score.Recorder = &tagExemptionRunner{}
+var _ rules.RunConfigRunner = &tagExemptionRunner{}
+
+func (r *tagExemptionRunner) Variables() modulecontent.Variables {
+	if vr, ok := r.Runner.(modulecontent.VariablesRunner); ok {
+		return vr.Variables()
+	}
+	return modulecontent.Variables{}
+}
+
+func (r *tagExemptionRunner) RecordCheck(c score.Check) {
+	if !c.Passed && c.Range.Filename != "" {
+		if r.failed == nil {
+			r.failed = make(map[string][]string)
+		}
+		k := rangeKey(c.Range)
+		r.failed[k] = append(r.failed[k], c.Address)
+	}
+	score.Record(r.Runner, c)
+}
+
+func (r *tagExemptionRunner) RunConfig() *rules.RunConfig {
+	return rules.RunConfigOf(r.Runner)
+}
+
+func (r *tagExemptionRunner) EmitIssue(rule tflint.Rule, message string, issueRange hcl.Range) error {
+	exempt, err := r.exempt(rule, message, issueRange)
+	if err != nil || exempt {
+		return err
+	}
+	return r.Runner.EmitIssue(rule, message, issueRange)
+}
+
+func (r *tagExemptionRunner) EmitIssueWithFix(rule tflint.Rule, message string, issueRange hcl.Range, fixFunc func(f tflint.Fixer) error) error {
+	exempt, err := r.exempt(rule, message, issueRange)
+	if err != nil || exempt {
+		return err
+	}
+	return r.Runner.EmitIssueWithFix(rule, message, issueRange, fixFunc)
+}
+
+// exempt emits the issue as a notice if the tag of the `azapi_resource` instance that it is of exempts the rule,
+// or, if the instance is not known, the tags of all instances of the resource that contains it do.
+// Issues of resources in child modules are at the module block, so they are not exempted.
+func (r *tagExemptionRunner) exempt(rule tflint.Rule, message string, issueRange hcl.Range) (bool, error) {
+	if r.cache.files == nil {
+		files, diags := modulecontent.FetchFiles(r.Runner)
+		if diags.HasErrors() {
+			return false, fmt.Errorf("could not read files: %s", diags)
+		}
+		r.cache.files = files
+	}
+	address := blockAddress(r.cache.files, issueRange)
+	if !strings.HasPrefix(address, "azapi_resource.") {
+		return false, nil
+	}
+	key := modulecontent.VariablesKey(r.Runner)
+	tags, ok := r.cache.tags[key]
+	if !ok {
+		var err error
+		if tags, err = r.fetchTags(); err != nil {
+			return false, err
+		}
+		r.cache.tags[key] = tags
+	}
+	instances := resourceInstances(tags, address)
+	if instance := r.issueInstance(instances, message, issueRange); instance != "" {
+		instances = []string{instance}
+	}
+	if len(instances) == 0 {
+		return false, nil
+	}
+	for _, instance := range instances {
+		if !tagExempts(tags[instance], rule) {
+			return false, nil
+		}
+	}
+	msg := fmt.Sprintf("`%s` is exempted by the `%s` tag: %s", rule.Name(), r.notice.tag, message)
+	return true, r.Runner.EmitIssue(r.notice, msg, issueRange)
+}
+
+// issueInstance returns the instance of the resource that the issue is of, or an empty string if it is not known.
+// An issue of a resource that is not expanded is of its only instance. Otherwise the instance mentioned first in the message is preferred,
+// e.g. `azapi_resource.vnet["a"]`, and then the failed checks at the issue's range, in order.
+func (r *tagExemptionRunner) issueInstance(instances []string, message string, issueRange hcl.Range) string {
+	if len(instances) == 1 {
+		return instances[0]
+	}
+	var res string
+	pos := -1
+	for _, instance := range instances {
+		if p := strings.Index(message, "`"+instance+"`"); p >= 0 && (pos < 0 || p < pos) {
+			res, pos = instance, p
+		}
+	}
+	if res != "" {
+		return res
+	}
+	k := rangeKey(issueRange)
+	for len(r.failed[k]) > 0 {
+		addr := r.failed[k][0]
+		r.failed[k] = r.failed[k][1:]
+		for _, instance := range instances {
+			if instance == addr {
+				return addr
+			}
+		}
+	}
+	return ""
+}
+
+// resourceInstances returns the addresses of the instances of the resource in the tags, e.g. `azapi_resource.sa[0]` for `azapi_resource.sa`, in order.
+func resourceInstances(tags map[string][]string, address string) []string {
+	var res []string
+	for instance := range tags {
+		if instance == address || strings.HasPrefix(instance, address+"[") {
+			res = append(res, instance)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// rangeKey returns the key of the range by its start, as the ranges of issues may not have byte offsets.
+func rangeKey(rng hcl.Range) string {
+	return fmt.Sprintf("%s:%d,%d", rng.Filename, rng.Start.Line, rng.Start.Column)
+}
+
+// fetchTags returns the exemptions in the exemption tag of each `azapi_resource` instance in the root module by address,
+// e.g. `azapi_resource.sa["a"]`.
+func (r *tagExemptionRunner) fetchTags() (map[string][]string, error) {
+	ctx, blocks, instances, diags := modulecontent.FetchBlockInstances(tagsFetcher{}, r.Runner)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("could not get partial content: %s", diags)
+	}
+	tags := make(map[string][]string, len(blocks))
+	for _, block := range blocks {
+		val, err := exemptionTag(ctx, block, r.notice.tag)
+		if err != nil {
+			return nil, err
+		}
+		tags[instances[block].Address(strings.Join(block.Labels, "."))] = strings.FieldsFunc(val, func(c rune) bool {
+			return c == ',' || unicode.IsSpace(c)
+		})
+	}
+	return tags, nil
+}
+
+// tagExempts reports whether one of the exemptions is the name of the rule, one of its recommendation IDs, e.g. `SE:06`,
+// or its pillar, e.g. `SE` or `security`.
+func tagExempts(exemptions []string, rule tflint.Rule) bool {
+	md := waf.FromRule(rule)
+	for _, e := range exemptions {
+		if e == rule.Name() {
+			return true
+		}
+		if md == nil {
+			continue
+		}
+		for _, id := range md.RecommendationIDs {
+			if strings.EqualFold(e, id) {
+				return true
+			}
+		}
+		if p, err := waf.ParsePillar(e); err == nil && p == md.Pillar {
+			return true
+		}
+	}
+	return false
+}
+
+// tagsFetcher fetches the tags of the `azapi_resource` blocks, as a `tags` argument or in the `body`.
+type tagsFetcher struct{}
+
+func (tagsFetcher) BlockType() string    { return "resource" }
+func (tagsFetcher) LabelOne() string     { return "azapi_resource" }
+func (tagsFetcher) LabelNames() []string { return []string{"type", "name"} }
+func (tagsFetcher) Attributes() []string { return []string{"tags", "body"} }
+
+// exemptionTag returns the value of the tag of the resource, from its `tags` argument or, if not set there,
+// from `tags` in its `body`, which can be JSON encoded. It is empty if the tag is not set or is not known.
+func exemptionTag(ctx *terraform.Evaluator, block *hclext.Block, tag string) (string, error) {
+	if attr, ok := block.Body.Attributes["tags"]; ok {
+		val, diags := ctx.EvaluateExpr(attr.Expr, cty.DynamicPseudoType)
+		if diags.HasErrors() {
+			return "", fmt.Errorf("could not evaluate tags expression: %s", diags)
+		}
+		if s := tagValue(val, tag); s != "" {
+			return s, nil
+		}
+	}
+	attr, ok := block.Body.Attributes["body"]
+	if !ok {
+		return "", nil
+	}
+	val, diags := ctx.EvaluateExpr(attr.Expr, cty.DynamicPseudoType)
+	if diags.HasErrors() {
+		return "", fmt.Errorf("could not evaluate body expression: %s", diags)
+	}
+	val, _ = val.UnmarkDeep()
+	if val.IsKnown() && !val.IsNull() && val.Type() == cty.String {
+		src := []byte(val.AsString())
+		ty, err := ctyjson.ImpliedType(src)
+		if err != nil {
+			return "", nil
+		}
+		if val, err = ctyjson.Unmarshal(src, ty); err != nil {
+			return "", nil
+		}
+	}
+	if !val.IsKnown() || val.IsNull() || !val.Type().IsObjectType() || !val.Type().HasAttribute("tags") {
+		return "", nil
+	}
+	return tagValue(val.GetAttr("tags"), tag), nil
+}
+
+// tagValue returns the value of the tag in a map or object of tags, or an empty string if it is not a known string.
+func tagValue(tags cty.Value, tag string) string {
+	tags, _ = tags.UnmarkDeep()
+	if !tags.IsKnown() || tags.IsNull() {
+		return ""
+	}
+	val, diags := hcl.Index(tags, cty.StringVal(tag), nil)
+	if diags.HasErrors() || !val.IsKnown() || val.IsNull() || val.Type() != cty.String {
+		return ""
+	}
+	return val.AsString()
+}
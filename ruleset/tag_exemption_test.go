@@ -0,0 +1,253 @@
+package ruleset
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/blockquery"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/rules"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/prashantv/gostub"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+const tagExemptionsConfig = `
+variable "exempt" {
+  type    = string
+  default = "RE:05"
+}
+
+resource "azapi_resource" "untagged" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+}
+
+resource "azapi_resource" "by_name" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  tags = {
+    "waf-exempt" = "public"
+  }
+}
+
+resource "azapi_resource" "by_id" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  tags = {
+    "waf-exempt" = "SE:06, ${var.exempt}"
+  }
+}
+
+resource "azapi_resource" "by_pillar" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  body = {
+    tags = {
+      "waf-exempt" = "security"
+    }
+  }
+}
+
+resource "azapi_resource" "json" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  body = jsonencode({
+    tags = {
+      "waf-exempt" = "reliability"
+    }
+  })
+}
+
+resource "azapi_resource" "custom" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  tags = {
+    exceptions = "SE"
+  }
+}
+`
+
+func TestTagExemptions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		global *tflint.Config
+		want   []string
+	}{
+		{
+			name: "default tag",
+			want: []string{
+				"public: public untagged",
+				"waf_tag_exemption: `public` is exempted by the `waf-exempt` tag: public by_name",
+				"waf_tag_exemption: `public` is exempted by the `waf-exempt` tag: public by_id",
+				"waf_tag_exemption: `public` is exempted by the `waf-exempt` tag: public by_pillar",
+				"public: public json",
+				"public: public custom",
+				"zone: zone untagged",
+				"zone: zone by_name",
+				"waf_tag_exemption: `zone` is exempted by the `waf-exempt` tag: zone by_id",
+				"zone: zone by_pillar",
+				"waf_tag_exemption: `zone` is exempted by the `waf-exempt` tag: zone json",
+				"zone: zone custom",
+			},
+		},
+		{
+			name:   "custom tag",
+			config: `exemption_tag = "exceptions"`,
+			want: []string{
+				"public: public untagged",
+				"public: public by_name",
+				"public: public by_id",
+				"public: public by_pillar",
+				"public: public json",
+				"waf_tag_exemption: `public` is exempted by the `exceptions` tag: public custom",
+				"zone: zone untagged",
+				"zone: zone by_name",
+				"zone: zone by_id",
+				"zone: zone by_pillar",
+				"zone: zone json",
+				"zone: zone custom",
+			},
+		},
+		{
+			name: "disabled",
+			global: &tflint.Config{Rules: map[string]*tflint.RuleConfig{
+				TagExemptionRuleName: {Name: TagExemptionRuleName, Enabled: false},
+			}},
+			want: []string{
+				"public: public untagged",
+				"public: public by_name",
+				"public: public by_id",
+				"public: public by_pillar",
+				"public: public json",
+				"public: public custom",
+				"zone: zone untagged",
+				"zone: zone by_name",
+				"zone: zone by_id",
+				"zone: zone by_pillar",
+				"zone: zone json",
+				"zone: zone custom",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{"main.tf": tagExemptionsConfig}))
+			defer stub.Reset()
+
+			rs := New("test", "0.0.0", []tflint.Rule{
+				&resourcesRule{testRule{name: "public", metadata: &waf.Metadata{Pillar: waf.Security, RecommendationIDs: []string{"SE:06"}}}},
+				&resourcesRule{testRule{name: "zone", metadata: &waf.Metadata{Pillar: waf.Reliability, RecommendationIDs: []string{"RE:05"}}}},
+			})
+			global := tc.global
+			if global == nil {
+				global = &tflint.Config{}
+			}
+			require.NoError(t, rs.ApplyGlobalConfig(global))
+			require.NoError(t, decodeConfig(t, rs, tc.config))
+
+			runner := helper.TestRunner(t, map[string]string{})
+			for _, rule := range rs.EnabledRules {
+				require.NoError(t, rule.Check(runner))
+			}
+			var got []string
+			for _, issue := range runner.Issues {
+				got = append(got, issue.Rule.Name()+": "+issue.Message)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTagExemptionsFetchedOncePerRun(t *testing.T) {
+	tagged := func(exempt string) afero.Afero {
+		return ruletest.MemFs(map[string]string{"main.tf": `
+resource "azapi_resource" "sa" {
+  type = "Microsoft.Storage/storageAccounts@2023-01-01"
+  tags = {
+    "waf-exempt" = "` + exempt + `"
+  }
+}`})
+	}
+	stub := gostub.Stub(&modulecontent.AppFs, tagged("public, zone"))
+	defer stub.Reset()
+
+	rs := New("test", "0.0.0", []tflint.Rule{
+		&resourcesRule{testRule{name: "public"}},
+		&resourcesRule{testRule{name: "zone"}},
+	})
+	require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+	require.NoError(t, decodeConfig(t, rs, ""))
+	run := func(between func()) []string {
+		tr := helper.TestRunner(t, map[string]string{})
+		runner, err := rs.NewRunner(tr)
+		require.NoError(t, err)
+		for i, rule := range rs.EnabledRules {
+			if i == 1 {
+				between()
+			}
+			require.NoError(t, rule.Check(runner))
+		}
+		var got []string
+		for _, issue := range tr.Issues {
+			got = append(got, issue.Rule.Name())
+		}
+		return got
+	}
+
+	// The rules of a run share the tags fetched by the first rule.
+	assert.Equal(t, []string{TagExemptionRuleName, TagExemptionRuleName}, run(func() { modulecontent.AppFs = tagged("none") }))
+	assert.Len(t, rs.tags.tags, 1)
+	// A new run fetches the tags again.
+	assert.Equal(t, []string{"public", "zone"}, run(func() {}))
+}
+
+func TestTagExemptionsOfInstances(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{"main.tf": `
+resource "azapi_resource" "each" {
+  for_each = { a = "tls", b = "none" }
+  type     = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name     = each.key
+  tags = {
+    "waf-exempt" = each.value
+  }
+  body = {
+    properties = {
+      minimumTlsVersion = "TLS1_0"
+    }
+  }
+}
+
+resource "azapi_resource" "counted" {
+  count = 2
+  type  = "Microsoft.Storage/storageAccounts@2023-01-01"
+  name  = "counted${count.index}"
+  tags = {
+    "waf-exempt" = "tls"
+  }
+  body = {
+    properties = {
+      minimumTlsVersion = "TLS1_0"
+    }
+  }
+}`}))
+	defer stub.Reset()
+
+	rs := New("test", "0.0.0", []tflint.Rule{
+		rules.NewAzApiRule("tls", "", "Microsoft.Storage/storageAccounts", "", "", "properties.minimumTlsVersion", blockquery.IsOneOf, blockquery.NewStringResults("TLS1_2")...),
+	})
+	require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+	require.NoError(t, decodeConfig(t, rs, ""))
+	tr := helper.TestRunner(t, map[string]string{})
+	runner, err := rs.NewRunner(tr)
+	require.NoError(t, err)
+	for _, rule := range rs.EnabledRules {
+		require.NoError(t, rule.Check(runner))
+	}
+	var got []string
+	for _, issue := range tr.Issues {
+		got = append(got, fmt.Sprintf("%s:%d", issue.Rule.Name(), issue.Range.Start.Line))
+	}
+	// Only the `a` instance of `each` is exempted, and both instances of `counted` are.
+	assert.Equal(t, []string{TagExemptionRuleName + ":9", "tls:9", TagExemptionRuleName + ":23", TagExemptionRuleName + ":23"}, got)
+}
@@ -0,0 +1,129 @@
+package ruleset
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+// DefaultTFLintConfigFile is the tflint config file that is read if `TFLINT_CONFIG_FILE` is not set,
+// from the working directory or, if it does not exist there, from the home directory.
+const DefaultTFLintConfigFile = ".tflint.hcl"
+
+// NewRunner returns a runner that provides the input values from the tflint config file and the plugin configuration,
+// and the RunConfig, to the rules.
+// The values given with the `--var-file` and `--var` options of tflint, and the file given with `--config`, are not passed to plugins,
+// so the plugin's `varfile` and `variables` attributes, which tflint passes from the file it loads, are the way to set values with them.
+// It is called before each run of the rules, so the results cached by the rule engine and the tags of the last run are dropped.
+func (r *RuleSet) NewRunner(runner tflint.Runner) (tflint.Runner, error) {
+	if r.runConfig != nil && r.runConfig.Engine != nil {
+		r.runConfig.Engine.Reset()
+	}
+	if r.tags != nil {
+		r.tags.reset()
+	}
+	vars, err := LoadTFLintVariables()
+	if err != nil {
+		return nil, err
+	}
+	return &ruleSetRunner{Runner: runner, vars: r.Variables(vars), config: r.runConfig}, nil
+}
+
+// Variables returns the input values of the tflint `config` block followed by those of the plugin configuration.
+func (r *RuleSet) Variables(global modulecontent.Variables) modulecontent.Variables {
+	return modulecontent.Variables{
+		Files:  append(append([]string{}, global.Files...), r.config.VarFiles...),
+		Values: append(append([]string{}, global.Values...), r.config.Values...),
+	}
+}
+
+// LoadTFLintVariables reads the `varfile` and `variables` attributes of the `config` block from the tflint config file in modulecontent.AppFs.
+// The file is found as tflint does without `--config`: `TFLINT_CONFIG_FILE`, or DefaultTFLintConfigFile in the working directory,
+// which is the directory given with `--chdir` as tflint starts the plugin there, or in the home directory.
+// If there is no file there are no values.
+func LoadTFLintVariables() (modulecontent.Variables, error) {
+	filenames := []string{os.Getenv("TFLINT_CONFIG_FILE")}
+	if filenames[0] == "" {
+		filenames = []string{DefaultTFLintConfigFile}
+		if home, err := os.UserHomeDir(); err == nil {
+			filenames = append(filenames, filepath.Join(home, DefaultTFLintConfigFile))
+		}
+	}
+	var filename string
+	var src []byte
+	found := false
+	for _, filename = range filenames {
+		var err error
+		if src, err = modulecontent.AppFs.ReadFile(filename); err == nil {
+			found = true
+			break
+		}
+		if !os.IsNotExist(err) {
+			return modulecontent.Variables{}, fmt.Errorf("could not read tflint config: %s", err)
+		}
+	}
+	if !found {
+		return modulecontent.Variables{}, nil
+	}
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return modulecontent.Variables{}, diags
+	}
+	content, _, diags := file.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "config"}},
+	})
+	if diags.HasErrors() {
+		return modulecontent.Variables{}, diags
+	}
+	for _, block := range content.Blocks {
+		return ConfigVariables(block.Body)
+	}
+	return modulecontent.Variables{}, nil
+}
+
+// ConfigVariables decodes the `varfile` and `variables` attributes of a tflint `config` block.
+func ConfigVariables(body hcl.Body) (modulecontent.Variables, error) {
+	content, _, diags := body.PartialContent(&hcl.BodySchema{
+		Attributes: []hcl.AttributeSchema{{Name: "varfile"}, {Name: "variables"}},
+	})
+	if diags.HasErrors() {
+		return modulecontent.Variables{}, diags
+	}
+	var vars modulecontent.Variables
+	var err error
+	if vars.Files, err = stringListAttribute(content.Attributes["varfile"]); err != nil {
+		return modulecontent.Variables{}, err
+	}
+	if vars.Values, err = stringListAttribute(content.Attributes["variables"]); err != nil {
+		return modulecontent.Variables{}, err
+	}
+	return vars, nil
+}
+
+// stringListAttribute returns the value of a list of strings attribute, or nil if the attribute is not set.
+func stringListAttribute(attr *hcl.Attribute) ([]string, error) {
+	if attr == nil {
+		return nil, nil
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	list, err := convert.Convert(val, cty.List(cty.String))
+	if err != nil || !list.IsWhollyKnown() || list.IsNull() {
+		return nil, fmt.Errorf("%s: `%s` must be a list of strings", attr.Range, attr.Name)
+	}
+	var res []string
+	if err := gocty.FromCtyValue(list, &res); err != nil {
+		return nil, fmt.Errorf("%s: `%s` must be a list of strings", attr.Range, attr.Name)
+	}
+	return res, nil
+}
@@ -0,0 +1,98 @@
+package ruleset
+
+import (
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/ruletest"
+	"github.com/prashantv/gostub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+func TestLoadTFLintVariables(t *testing.T) {
+	testCases := []struct {
+		name    string
+		files   map[string]string
+		env     string
+		want    modulecontent.Variables
+		wantErr string
+	}{
+		{
+			name: "no config file",
+		},
+		{
+			name: "config block",
+			files: map[string]string{DefaultTFLintConfigFile: `
+config {
+  varfile   = ["a.tfvars", "b.tfvars"]
+  variables = ["tls=TLS1_2"]
+}`},
+			want: modulecontent.Variables{Files: []string{"a.tfvars", "b.tfvars"}, Values: []string{"tls=TLS1_2"}},
+		},
+		{
+			name:  "TFLINT_CONFIG_FILE",
+			files: map[string]string{"ci.hcl": `config { varfile = ["ci.tfvars"] }`},
+			env:   "ci.hcl",
+			want:  modulecontent.Variables{Files: []string{"ci.tfvars"}},
+		},
+		{
+			name: "home directory",
+			files: map[string]string{
+				"/home/tflint/" + DefaultTFLintConfigFile: `config { varfile = ["home.tfvars"] }`,
+			},
+			want: modulecontent.Variables{Files: []string{"home.tfvars"}},
+		},
+		{
+			name: "working directory before home directory",
+			files: map[string]string{
+				DefaultTFLintConfigFile:                   `config { varfile = ["wd.tfvars"] }`,
+				"/home/tflint/" + DefaultTFLintConfigFile: `config { varfile = ["home.tfvars"] }`,
+			},
+			want: modulecontent.Variables{Files: []string{"wd.tfvars"}},
+		},
+		{
+			name:    "invalid varfile",
+			files:   map[string]string{DefaultTFLintConfigFile: `config { varfile = "a.tfvars" }`},
+			wantErr: "`varfile` must be a list of strings",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(tc.files))
+			defer stub.Reset()
+			t.Setenv("TFLINT_CONFIG_FILE", tc.env)
+			t.Setenv("HOME", "/home/tflint")
+			got, err := LoadTFLintVariables()
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestNewRunnerVariables(t *testing.T) {
+	stub := gostub.Stub(&modulecontent.AppFs, ruletest.MemFs(map[string]string{
+		DefaultTFLintConfigFile: `config {
+  varfile   = ["a.tfvars"]
+  variables = ["a=1"]
+}`,
+	}))
+	defer stub.Reset()
+	rs := New("test", "0.0.0", testRules())
+	require.NoError(t, rs.ApplyGlobalConfig(&tflint.Config{}))
+	require.NoError(t, decodeConfig(t, rs, `
+varfile   = ["b.tfvars"]
+variables = ["b=2"]`))
+
+	runner, err := rs.NewRunner(helper.TestRunner(t, map[string]string{}))
+	require.NoError(t, err)
+	vr, ok := runner.(modulecontent.VariablesRunner)
+	require.True(t, ok)
+	assert.Equal(t, modulecontent.Variables{Files: []string{"a.tfvars", "b.tfvars"}, Values: []string{"a=1", "b=2"}}, vr.Variables())
+}
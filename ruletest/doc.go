@@ -0,0 +1,8 @@
+// Package ruletest runs rules against fixture directories and compares the issues with golden files.
+//
+// Each rule has a directory of test cases, e.g. `testdata/azapi_private_endpoint_required`,
+// and each test case is a directory containing a Terraform configuration,
+// an optional `.tflint.hcl` with the rule config, and an `issues.golden` file with the expected issues.
+// Child modules are placed in sub directories of the test case and called with a relative source.
+// Run the tests with `-update` to rewrite the golden files from the issues that are emitted.
+package ruletest
@@ -0,0 +1,123 @@
+package ruletest
+
+import (
+	"flag"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/modulecontent"
+	"github.com/prashantv/gostub"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// GoldenFile is the name of the file in each test case that contains the expected issues, one per line.
+const GoldenFile = "issues.golden"
+
+var update = flag.Bool("update", false, "rewrite the golden files of rule test cases")
+
+// Run runs the rule against each test case in the directory and compares the issues with the golden file of the test case.
+func Run(t *testing.T, rule tflint.Rule, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		caseDir := filepath.Join(dir, e.Name())
+		t.Run(e.Name(), func(t *testing.T) {
+			RunCase(t, rule, caseDir)
+		})
+	}
+}
+
+// RunCase runs the rule against a single test case directory.
+func RunCase(t *testing.T, rule tflint.Rule, dir string) {
+	t.Helper()
+	files, err := LoadFiles(dir)
+	require.NoError(t, err)
+
+	runner := helper.TestRunner(t, rootFiles(files))
+	stub := gostub.Stub(&modulecontent.AppFs, MemFs(files))
+	defer stub.Reset()
+	require.NoError(t, rule.Check(runner))
+
+	got := FormatIssues(runner.Issues)
+	golden := filepath.Join(dir, GoldenFile)
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+		return
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err, "run the test with -update to create the golden file")
+	assert.Equal(t, string(want), got, "issues do not match %s", golden)
+}
+
+// LoadFiles returns the contents of the files in the test case directory by their slash separated relative path.
+// The golden file is not included.
+func LoadFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == GoldenFile {
+			return nil
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files[rel] = string(b)
+		return nil
+	})
+	return files, err
+}
+
+// MemFs returns an in-memory file system with the files, for use as modulecontent.AppFs.
+func MemFs(files map[string]string) afero.Afero {
+	fs := afero.NewMemMapFs()
+	for name, content := range files {
+		_ = afero.WriteFile(fs, name, []byte(content), os.ModePerm)
+	}
+	return afero.Afero{Fs: fs}
+}
+
+// FormatIssues formats the issues as they are written in a golden file, one `<range>: <message>` per line.
+func FormatIssues(issues helper.Issues) string {
+	var sb strings.Builder
+	for _, issue := range issues {
+		sb.WriteString(issue.Range.String())
+		sb.WriteString(": ")
+		sb.WriteString(issue.Message)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// rootFiles returns the files of the root module and the `.tflint.hcl` config, which are passed to the tflint test runner.
+func rootFiles(files map[string]string) map[string]string {
+	res := make(map[string]string)
+	for name, content := range files {
+		if path.Dir(name) != "." {
+			continue
+		}
+		if name == ".tflint.hcl" || path.Ext(name) == ".tf" {
+			res[name] = content
+		}
+	}
+	return res
+}
@@ -0,0 +1,42 @@
+package ruletest
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+)
+
+func TestFormatIssues(t *testing.T) {
+	issues := helper.Issues{
+		{
+			Message: "first",
+			Range: hcl.Range{
+				Filename: "main.tf",
+				Start:    hcl.Pos{Line: 1, Column: 1},
+				End:      hcl.Pos{Line: 1, Column: 10},
+			},
+		},
+		{
+			Message: "second",
+			Range: hcl.Range{
+				Filename: "other.tf",
+				Start:    hcl.Pos{Line: 2, Column: 3},
+				End:      hcl.Pos{Line: 4, Column: 4},
+			},
+		},
+	}
+	assert.Equal(t, "main.tf:1,1-10: first\nother.tf:2,3-4,4: second\n", FormatIssues(issues))
+	assert.Equal(t, "", FormatIssues(helper.Issues{}))
+}
+
+func TestRootFiles(t *testing.T) {
+	files := map[string]string{
+		"main.tf":               "",
+		".tflint.hcl":           "",
+		"terraform.tfvars":      "",
+		"modules/child/main.tf": "",
+	}
+	assert.Equal(t, map[string]string{"main.tf": "", ".tflint.hcl": ""}, rootFiles(files))
+}
@@ -0,0 +1,6 @@
+// Package score computes a Well-Architected score per pillar from the checks made by the rules,
+// i.e. the resources in scope of each rule and whether they passed.
+//
+// Rules report each check with Record. Runners that implement Recorder collect them,
+// other runners, such as the tflint plugin runner, ignore them.
+package score
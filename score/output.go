@@ -0,0 +1,39 @@
+package score
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// WriteTable writes the report as a table of the pillars with their resource types, followed by the resource types and the overall score.
+func WriteTable(w io.Writer, r Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PILLAR\tSCORE\tPASSED\tCHECKS")
+	for _, p := range r.Pillars {
+		writeRow(tw, p.Pillar, p.Score)
+		for _, t := range p.ResourceTypes {
+			writeRow(tw, "  "+t.ResourceType, t.Score)
+		}
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "RESOURCE TYPE\tSCORE\tPASSED\tCHECKS")
+	for _, t := range r.ResourceTypes {
+		writeRow(tw, t.ResourceType, t.Score)
+	}
+	fmt.Fprintln(tw)
+	writeRow(tw, "Overall", r.Score)
+	return tw.Flush()
+}
+
+func writeRow(w io.Writer, name string, s Score) {
+	fmt.Fprintf(w, "%s\t%.1f%%\t%d\t%d\n", name, s.Percent, s.Passed, s.Applicable)
+}
+
+// WriteJSON writes the report as indented JSON.
+func WriteJSON(w io.Writer, r Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,154 @@
+package score
+
+import (
+	"math"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// otherPillar is the pillar of checks by rules without Well-Architected metadata.
+const otherPillar = "Other"
+
+// Check is the result of a rule for a resource in its scope.
+type Check struct {
+	Rule         tflint.Rule // The rule that made the check.
+	ResourceType string      // The Azure resource type, e.g. `Microsoft.Storage/storageAccounts`.
+	Address      string      // The address of the resource instance, e.g. `azapi_resource.sa`.
+	Passed       bool        // Whether the resource passed the check.
+	Range        hcl.Range   // The range of the issues emitted for the resource if it failed the check, e.g. to name the test cases of the issues by address.
+}
+
+// Recorder is implemented by runners that collect the checks of the rules.
+type Recorder interface {
+	RecordCheck(c Check)
+}
+
+// Record records the check if the runner is a Recorder.
+func Record(runner tflint.Runner, c Check) {
+	if rec, ok := runner.(Recorder); ok {
+		rec.RecordCheck(c)
+	}
+}
+
+// Weights are the weights of checks by the severity of their rule.
+type Weights map[tflint.Severity]int
+
+// DefaultWeights weight errors three times, and warnings twice, as much as notices.
+var DefaultWeights = Weights{
+	tflint.ERROR:   3,
+	tflint.WARNING: 2,
+	tflint.NOTICE:  1,
+}
+
+// Score is the number and weight of the applicable and passing checks.
+type Score struct {
+	Percent      float64 `json:"score"`         // The passing weight as a percentage of the total weight, rounded to one decimal place.
+	Applicable   int     `json:"applicable"`    // The number of checks.
+	Passed       int     `json:"passed"`        // The number of passing checks.
+	Weight       int     `json:"weight"`        // The total weight of the checks.
+	PassedWeight int     `json:"passed_weight"` // The total weight of the passing checks.
+}
+
+func (s *Score) add(c Check, weight int) {
+	s.Applicable++
+	s.Weight += weight
+	if c.Passed {
+		s.Passed++
+		s.PassedWeight += weight
+	}
+	s.Percent = percent(s.PassedWeight, s.Weight)
+}
+
+// percent returns passed as a percentage of total rounded to one decimal place, or 100 if total is 0.
+func percent(passed, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return math.Round(float64(passed)*1000/float64(total)) / 10
+}
+
+// ResourceTypeScore is the score of the checks of a resource type.
+type ResourceTypeScore struct {
+	ResourceType string `json:"resource_type"`
+	Score
+}
+
+// PillarScore is the score of the checks of a pillar, with a breakdown by resource type.
+type PillarScore struct {
+	Pillar string `json:"pillar"`
+	Score
+	ResourceTypes []ResourceTypeScore `json:"resource_types"`
+}
+
+// Report is the overall score, the score of each pillar with checks, and the score of each resource type.
+type Report struct {
+	Score
+	Pillars       []PillarScore       `json:"pillars"`
+	ResourceTypes []ResourceTypeScore `json:"resource_types"`
+}
+
+// Compute returns the report of the checks weighted by the severity of their rule.
+// Pillars are in the order of waf.Pillars, followed by the checks of rules without metadata, and resource types are sorted.
+func Compute(checks []Check, weights Weights) Report {
+	res := Report{
+		Score:         Score{Percent: 100},
+		Pillars:       []PillarScore{},
+		ResourceTypes: []ResourceTypeScore{},
+	}
+	pillars := make(map[string]*Score)
+	pillarTypes := make(map[string]map[string]*Score)
+	types := make(map[string]*Score)
+	for _, c := range checks {
+		weight := weights[c.Rule.Severity()]
+		pillar := otherPillar
+		if md := waf.FromRule(c.Rule); md != nil && md.Pillar != "" {
+			pillar = string(md.Pillar)
+		}
+		if pillars[pillar] == nil {
+			pillars[pillar] = &Score{}
+			pillarTypes[pillar] = make(map[string]*Score)
+		}
+		if pillarTypes[pillar][c.ResourceType] == nil {
+			pillarTypes[pillar][c.ResourceType] = &Score{}
+		}
+		if types[c.ResourceType] == nil {
+			types[c.ResourceType] = &Score{}
+		}
+		res.Score.add(c, weight)
+		pillars[pillar].add(c, weight)
+		pillarTypes[pillar][c.ResourceType].add(c, weight)
+		types[c.ResourceType].add(c, weight)
+	}
+	names := make([]string, 0, len(waf.Pillars)+1)
+	for _, p := range waf.Pillars {
+		names = append(names, string(p))
+	}
+	names = append(names, otherPillar)
+	for _, name := range names {
+		s, ok := pillars[name]
+		if !ok {
+			continue
+		}
+		res.Pillars = append(res.Pillars, PillarScore{
+			Pillar:        name,
+			Score:         *s,
+			ResourceTypes: resourceTypeScores(pillarTypes[name]),
+		})
+	}
+	res.ResourceTypes = resourceTypeScores(types)
+	return res
+}
+
+func resourceTypeScores(types map[string]*Score) []ResourceTypeScore {
+	res := make([]ResourceTypeScore, 0, len(types))
+	for t, s := range types {
+		res = append(res, ResourceTypeScore{ResourceType: t, Score: *s})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ResourceType < res[j].ResourceType
+	})
+	return res
+}
@@ -0,0 +1,111 @@
+package score
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/matt-FFFFFF/tflint-ruleset-azure-wellarchitectred/waf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/terraform-linters/tflint-plugin-sdk/helper"
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+const (
+	storageAccount = "Microsoft.Storage/storageAccounts"
+	keyVault       = "Microsoft.KeyVault/vaults"
+)
+
+// testRule is a rule with a severity and metadata.
+type testRule struct {
+	tflint.DefaultRule
+	name     string
+	severity tflint.Severity
+	metadata *waf.Metadata
+}
+
+func (r *testRule) Name() string                { return r.name }
+func (r *testRule) Enabled() bool               { return true }
+func (r *testRule) Severity() tflint.Severity   { return r.severity }
+func (r *testRule) Metadata() interface{}       { return r.metadata }
+func (r *testRule) Check(_ tflint.Runner) error { return nil }
+
+var (
+	errorRule   = &testRule{name: "error", severity: tflint.ERROR, metadata: &waf.Metadata{Pillar: waf.Security}}
+	warningRule = &testRule{name: "warning", severity: tflint.WARNING, metadata: &waf.Metadata{Pillar: waf.Reliability}}
+	otherRule   = &testRule{name: "other", severity: tflint.ERROR}
+)
+
+func testChecks() []Check {
+	return []Check{
+		{Rule: errorRule, ResourceType: storageAccount, Address: "azapi_resource.a", Passed: true},
+		{Rule: errorRule, ResourceType: storageAccount, Address: "azapi_resource.b", Passed: false},
+		{Rule: warningRule, ResourceType: storageAccount, Address: "azapi_resource.a", Passed: true},
+		{Rule: warningRule, ResourceType: keyVault, Address: "azapi_resource.kv", Passed: false},
+		{Rule: otherRule, ResourceType: storageAccount, Address: "azapi_resource.a", Passed: true},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	r := Compute(testChecks(), DefaultWeights)
+	// (3 + 2 + 3) / (3 + 3 + 2 + 2 + 3)
+	assert.Equal(t, Score{Percent: 61.5, Applicable: 5, Passed: 3, Weight: 13, PassedWeight: 8}, r.Score)
+
+	require.Len(t, r.Pillars, 3)
+	assert.Equal(t, "Reliability", r.Pillars[0].Pillar)
+	assert.Equal(t, Score{Percent: 50, Applicable: 2, Passed: 1, Weight: 4, PassedWeight: 2}, r.Pillars[0].Score)
+	assert.Equal(t, []ResourceTypeScore{
+		{ResourceType: keyVault, Score: Score{Percent: 0, Applicable: 1, Passed: 0, Weight: 2, PassedWeight: 0}},
+		{ResourceType: storageAccount, Score: Score{Percent: 100, Applicable: 1, Passed: 1, Weight: 2, PassedWeight: 2}},
+	}, r.Pillars[0].ResourceTypes)
+	assert.Equal(t, "Security", r.Pillars[1].Pillar)
+	assert.Equal(t, 50.0, r.Pillars[1].Percent)
+	assert.Equal(t, "Other", r.Pillars[2].Pillar)
+
+	require.Len(t, r.ResourceTypes, 2)
+	assert.Equal(t, keyVault, r.ResourceTypes[0].ResourceType)
+	assert.Equal(t, Score{Percent: 72.7, Applicable: 4, Passed: 3, Weight: 11, PassedWeight: 8}, r.ResourceTypes[1].Score)
+}
+
+func TestComputeNoChecks(t *testing.T) {
+	r := Compute(nil, DefaultWeights)
+	assert.Equal(t, 100.0, r.Percent)
+	assert.Empty(t, r.Pillars)
+}
+
+func TestComputeWeights(t *testing.T) {
+	r := Compute(testChecks(), Weights{tflint.ERROR: 1, tflint.WARNING: 1})
+	assert.Equal(t, 60.0, r.Percent)
+}
+
+func TestWriteTable(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteTable(&buf, Compute(testChecks()[:2], DefaultWeights)))
+	assert.Equal(t, "PILLAR                               SCORE  PASSED  CHECKS\n"+
+		"Security                             50.0%  1       2\n"+
+		"  Microsoft.Storage/storageAccounts  50.0%  1       2\n"+
+		"\n"+
+		"RESOURCE TYPE                      SCORE  PASSED  CHECKS\n"+
+		"Microsoft.Storage/storageAccounts  50.0%  1       2\n"+
+		"\n"+
+		"Overall  50.0%  1  2\n", buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, Compute(testChecks()[:1], DefaultWeights)))
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 100.0, got["score"])
+	assert.Equal(t, 1.0, got["applicable"])
+	pillars := got["pillars"].([]interface{})
+	require.Len(t, pillars, 1)
+	assert.Equal(t, "Security", pillars[0].(map[string]interface{})["pillar"])
+}
+
+func TestRecordIgnoresOtherRunners(t *testing.T) {
+	assert.NotPanics(t, func() {
+		Record(&helper.Runner{}, Check{Rule: errorRule})
+	})
+}
@@ -0,0 +1,3 @@
+// Package waf provides the Azure Well-Architected Framework metadata that is attached to each rule,
+// i.e. the pillar and the recommendation IDs that a finding belongs to.
+package waf
@@ -0,0 +1,45 @@
+package waf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/terraform-linters/tflint-plugin-sdk/tflint"
+)
+
+// Metadata is the Well-Architected Framework metadata of a rule.
+// Rules return a *Metadata from their `Metadata()` method.
+type Metadata struct {
+	Pillar            Pillar   // The pillar the rule belongs to.
+	RecommendationIDs []string // The recommendation IDs, e.g. `SE:06`.
+	Remediation       string   // How to fix a finding.
+	Rationale         string   // Why the rule matters.
+}
+
+// FromRule returns the metadata of the rule, or nil if the rule has no Well-Architected metadata.
+func FromRule(r tflint.Rule) *Metadata {
+	md, ok := r.Metadata().(*Metadata)
+	if !ok {
+		return nil
+	}
+	return md
+}
+
+// IssueMessage returns the message with the pillar, recommendation IDs and remediation appended.
+// If the metadata is nil the message is returned unchanged.
+func (m *Metadata) IssueMessage(msg string) string {
+	if m == nil || m.Pillar == "" {
+		return msg
+	}
+	var sb strings.Builder
+	sb.WriteString(msg)
+	if len(m.RecommendationIDs) > 0 {
+		fmt.Fprintf(&sb, " [%s %s]", m.Pillar, strings.Join(m.RecommendationIDs, ", "))
+	} else {
+		fmt.Fprintf(&sb, " [%s]", m.Pillar)
+	}
+	if m.Remediation != "" {
+		fmt.Fprintf(&sb, " Remediation: %s", m.Remediation)
+	}
+	return sb.String()
+}
@@ -0,0 +1,56 @@
+package waf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pillar is one of the five pillars of the Azure Well-Architected Framework.
+type Pillar string
+
+const (
+	Reliability           Pillar = "Reliability"
+	Security              Pillar = "Security"
+	CostOptimization      Pillar = "Cost Optimization"
+	OperationalExcellence Pillar = "Operational Excellence"
+	PerformanceEfficiency Pillar = "Performance Efficiency"
+)
+
+// Pillars is the list of all pillars, in the order used by the Well-Architected Framework.
+var Pillars = []Pillar{
+	Reliability,
+	Security,
+	CostOptimization,
+	OperationalExcellence,
+	PerformanceEfficiency,
+}
+
+var pillarCodes = map[Pillar]string{
+	Reliability:           "RE",
+	Security:              "SE",
+	CostOptimization:      "CO",
+	OperationalExcellence: "OE",
+	PerformanceEfficiency: "PE",
+}
+
+// Code returns the prefix used by the recommendation IDs of the pillar, e.g. `SE` for Security.
+func (p Pillar) Code() string {
+	return pillarCodes[p]
+}
+
+// Key returns the snake case identifier of the pillar used in configuration, e.g. `cost_optimization`.
+func (p Pillar) Key() string {
+	return strings.ReplaceAll(strings.ToLower(string(p)), " ", "_")
+}
+
+// ParsePillar returns the pillar for the given name.
+// The name can be the display name (`Cost Optimization`), the configuration key (`cost_optimization`)
+// or the recommendation code (`CO`), and is case insensitive.
+func ParsePillar(name string) (Pillar, error) {
+	for _, p := range Pillars {
+		if strings.EqualFold(name, string(p)) || strings.EqualFold(name, p.Key()) || strings.EqualFold(name, p.Code()) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown pillar `%s`", name)
+}
@@ -0,0 +1,33 @@
+package waf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePillar(t *testing.T) {
+	for _, name := range []string{"Cost Optimization", "cost_optimization", "CO", "co"} {
+		p, err := ParsePillar(name)
+		require.NoError(t, err)
+		assert.Equal(t, CostOptimization, p)
+	}
+	_, err := ParsePillar("unknown")
+	assert.Error(t, err)
+}
+
+func TestIssueMessage(t *testing.T) {
+	var nilMetadata *Metadata
+	assert.Equal(t, "message", nilMetadata.IssueMessage("message"))
+
+	md := &Metadata{
+		Pillar:            Security,
+		RecommendationIDs: []string{"SE:06", "SE:07"},
+		Remediation:       "Do something.",
+	}
+	assert.Equal(t, "message [Security SE:06, SE:07] Remediation: Do something.", md.IssueMessage("message"))
+
+	md = &Metadata{Pillar: Reliability}
+	assert.Equal(t, "message [Reliability]", md.IssueMessage("message"))
+}